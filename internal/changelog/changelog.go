@@ -0,0 +1,218 @@
+// Package changelog builds release notes from conventional commits and the
+// stories they belong to.
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+)
+
+// Title is the heading of a changelog file
+const Title = "# Changelog"
+
+// jiraKeyPattern matches a Jira issue key such as PROJ-12
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// Commit is a commit to list, with the story it belongs to
+type Commit struct {
+	Hash       string
+	Message    string
+	StoryKey   string // Key of the story, empty when the commit belongs to none
+	StoryTitle string
+}
+
+// Entry is a commit listed in the changelog
+type Entry struct {
+	Hash        string   `json:"hash"`
+	Type        string   `json:"type"`
+	Scope       string   `json:"scope,omitempty"`
+	Description string   `json:"description"`
+	Breaking    string   `json:"breaking,omitempty"` // What breaks, for breaking changes
+	Story       string   `json:"story,omitempty"`
+	StoryTitle  string   `json:"story_title,omitempty"`
+	JiraKeys    []string `json:"jira_keys,omitempty"`
+}
+
+// Section is a titled group of entries
+type Section struct {
+	Type    string  `json:"type,omitempty"`
+	Title   string  `json:"title"`
+	Entries []Entry `json:"entries"`
+}
+
+// Changelog is the release notes of a range of commits
+type Changelog struct {
+	Version  string    `json:"version"`
+	Date     time.Time `json:"date"`
+	From     string    `json:"from,omitempty"`
+	To       string    `json:"to"`
+	Breaking *Section  `json:"breaking,omitempty"`
+	Sections []Section `json:"sections"`
+}
+
+// IsEmpty reports whether the changelog lists no commits
+func (c *Changelog) IsEmpty() bool {
+	return c.Breaking == nil && len(c.Sections) == 0
+}
+
+// Build groups the commits, newest first, into the configured sections.
+// Breaking changes are also listed in a section of their own. Commits that are
+// not conventional or of a type without a section are left out.
+func Build(commits []Commit, cfg config.ChangelogConfig) *Changelog {
+	sections := cfg.TypeSections()
+	entries := make([][]Entry, len(sections))
+	var breaking []Entry
+
+	for _, c := range commits {
+		msg, err := conventional.Parse(c.Message)
+		if err != nil {
+			continue
+		}
+		entry := Entry{
+			Hash:        c.Hash,
+			Type:        msg.Type,
+			Scope:       msg.Scope,
+			Description: msg.Description,
+			Story:       c.StoryKey,
+			StoryTitle:  c.StoryTitle,
+			JiraKeys:    jiraKeys(msg, c.StoryKey),
+		}
+		if msg.Breaking {
+			entry.Breaking = msg.Description
+			for _, token := range []string{conventional.BreakingChange, "BREAKING-CHANGE"} {
+				if note, ok := msg.Footer(token); ok && strings.TrimSpace(note) != "" {
+					entry.Breaking = strings.TrimSpace(note)
+					break
+				}
+			}
+			breaking = append(breaking, entry)
+		}
+
+		i := slices.IndexFunc(sections, func(s config.ChangelogSection) bool { return s.Type == msg.Type })
+		if i >= 0 {
+			entries[i] = append(entries[i], entry)
+		}
+	}
+
+	changelog := &Changelog{}
+	if len(breaking) > 0 {
+		changelog.Breaking = &Section{Title: cfg.BreakingSectionTitle(), Entries: breaking}
+	}
+	for i, s := range sections {
+		if len(entries[i]) > 0 {
+			changelog.Sections = append(changelog.Sections, Section{Type: s.Type, Title: s.Title, Entries: entries[i]})
+		}
+	}
+	return changelog
+}
+
+// jiraKeys returns the Jira keys the message references, other than the story key
+func jiraKeys(msg *conventional.Message, storyKey string) []string {
+	var keys []string
+	texts := []string{msg.Header, msg.Body}
+	for _, f := range msg.Footers {
+		texts = append(texts, f.Value)
+	}
+	for _, text := range texts {
+		for _, key := range jiraKeyPattern.FindAllString(text, -1) {
+			if key != storyKey && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// Markdown renders the changelog as a Markdown section. With a Jira host,
+// Jira keys link to their issues.
+func (c *Changelog) Markdown(jiraHost string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", c.Version, c.Date.Format("2006-01-02"))
+
+	if c.IsEmpty() {
+		b.WriteString("\nNo notable changes.\n")
+		return b.String()
+	}
+
+	if c.Breaking != nil {
+		fmt.Fprintf(&b, "\n### %s\n\n", c.Breaking.Title)
+		for _, e := range c.Breaking.Entries {
+			b.WriteString(markdownEntry(e, e.Breaking, jiraHost))
+		}
+	}
+	for _, s := range c.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Title)
+		for _, e := range s.Entries {
+			b.WriteString(markdownEntry(e, e.Description, jiraHost))
+		}
+	}
+	return b.String()
+}
+
+// markdownEntry renders an entry as a list item with the text, the commit and
+// the story and Jira issues it references
+func markdownEntry(e Entry, text, jiraHost string) string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if e.Scope != "" {
+		fmt.Fprintf(&b, "**%s:** ", e.Scope)
+	}
+	b.WriteString(text)
+
+	refs := []string{shortHash(e.Hash)}
+	if e.Story != "" {
+		story := jiraLink(e.Story, jiraHost)
+		if e.StoryTitle != "" {
+			story += " " + e.StoryTitle
+		}
+		refs = append(refs, story)
+	}
+	for _, key := range e.JiraKeys {
+		refs = append(refs, jiraLink(key, jiraHost))
+	}
+	fmt.Fprintf(&b, " (%s)\n", strings.Join(refs, ", "))
+	return b.String()
+}
+
+// jiraLink links a Jira key to its issue when the Jira host is known. Other
+// references are returned as they are.
+func jiraLink(key, jiraHost string) string {
+	if jiraHost == "" || jiraKeyPattern.FindString(key) != key {
+		return key
+	}
+	return fmt.Sprintf("[%s](%s)", key, config.JiraIssueURL(jiraHost, key))
+}
+
+// shortHash returns the abbreviated commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Prepend adds a release section to the top of a changelog file's content,
+// below its title, starting the file when it is empty
+func Prepend(content, section string) string {
+	section = strings.TrimRight(section, "\n") + "\n"
+	trimmed := strings.TrimLeft(content, "\n")
+	if strings.TrimSpace(trimmed) == "" {
+		return Title + "\n\n" + section
+	}
+
+	if strings.HasPrefix(trimmed, "# ") {
+		title, rest, _ := strings.Cut(trimmed, "\n")
+		rest = strings.TrimLeft(rest, "\n")
+		if rest == "" {
+			return title + "\n\n" + section
+		}
+		return title + "\n\n" + section + "\n" + rest
+	}
+	return Title + "\n\n" + section + "\n" + trimmed
+}
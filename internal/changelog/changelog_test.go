@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	commits := []Commit{
+		{Hash: "aaa1111111", Message: "feat(api)!: drop the v1 endpoints\n\nBREAKING CHANGE: clients must use /v2", StoryKey: "PROJ-7", StoryTitle: "API v2"},
+		{Hash: "bbb2222222", Message: "fix: handle timeouts\n\nRefs: PROJ-9", StoryKey: "tracer-3", StoryTitle: "Timeouts"},
+		{Hash: "ccc3333333", Message: "chore: bump deps"},
+		{Hash: "ddd4444444", Message: "update readme"},
+		{Hash: "eee5555555", Message: "feat: add export"},
+		{Hash: "fff6666666", Message: "refactor!: rename the config keys"},
+	}
+
+	cl := Build(commits, config.ChangelogConfig{})
+	require.NotNil(t, cl.Breaking)
+	assert.Equal(t, "BREAKING CHANGES", cl.Breaking.Title)
+	require.Len(t, cl.Breaking.Entries, 2)
+	assert.Equal(t, "clients must use /v2", cl.Breaking.Entries[0].Breaking)
+	assert.Equal(t, "rename the config keys", cl.Breaking.Entries[1].Breaking)
+
+	var titles []string
+	for _, s := range cl.Sections {
+		titles = append(titles, s.Title)
+	}
+	assert.Equal(t, []string{"Features", "Bug Fixes", "Code Refactoring"}, titles, "chore has no section")
+	assert.Len(t, cl.Sections[0].Entries, 2)
+	assert.Equal(t, []string{"PROJ-9"}, cl.Sections[1].Entries[0].JiraKeys)
+	assert.Empty(t, cl.Sections[0].Entries[0].JiraKeys, "the story key is not repeated")
+
+	cl.Version = "v1.2.0"
+	cl.Date = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, `## v1.2.0 (2026-03-02)
+
+### BREAKING CHANGES
+
+- **api:** clients must use /v2 (aaa1111, [PROJ-7](https://jira.example.com/browse/PROJ-7) API v2)
+- rename the config keys (fff6666)
+
+### Features
+
+- **api:** drop the v1 endpoints (aaa1111, [PROJ-7](https://jira.example.com/browse/PROJ-7) API v2)
+- add export (eee5555)
+
+### Bug Fixes
+
+- handle timeouts (bbb2222, tracer-3 Timeouts, [PROJ-9](https://jira.example.com/browse/PROJ-9))
+
+### Code Refactoring
+
+- rename the config keys (fff6666)
+`, cl.Markdown("jira.example.com"))
+
+	// Configured sections
+	cl = Build(commits, config.ChangelogConfig{
+		Sections:      []config.ChangelogSection{{Type: "chore", Title: "Maintenance"}},
+		BreakingTitle: "Breaking",
+	})
+	assert.Equal(t, "Breaking", cl.Breaking.Title)
+	require.Len(t, cl.Sections, 1)
+	assert.Equal(t, "Maintenance", cl.Sections[0].Title)
+
+	empty := Build([]Commit{{Hash: "ddd4444444", Message: "update readme"}}, config.ChangelogConfig{})
+	empty.Version = "Unreleased"
+	assert.True(t, empty.IsEmpty())
+	assert.Contains(t, empty.Markdown(""), "No notable changes.")
+}
+
+func TestPrepend(t *testing.T) {
+	section := "## v1.1.0 (2026-03-02)\n\n### Features\n\n- add export (aaa1111)\n"
+
+	assert.Equal(t, "# Changelog\n\n"+section, Prepend("", section))
+	assert.Equal(t, "# Changelog\n\n"+section+"\n## v1.0.0 (2026-01-01)\n", Prepend("# Changelog\n\n## v1.0.0 (2026-01-01)\n", section))
+	assert.Equal(t, "# Changes\n\n"+section, Prepend("# Changes\n", section))
+	assert.Equal(t, "# Changelog\n\n"+section+"\n## v1.0.0\n", Prepend("## v1.0.0\n", section))
+}
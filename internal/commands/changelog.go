@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/changelog"
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// changelogNow tells the date of a release
+var changelogNow = time.Now
+
+// unreleasedVersion is the version of changes that are not tagged yet
+const unreleasedVersion = "Unreleased"
+
+var ChangelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate release notes from conventional commits",
+	Long: `Generate release notes from the conventional commits between two revisions.
+
+Commits are grouped by type into the sections of the changelog config, and
+breaking changes are highlighted in a section of their own. Each entry links
+the story the commit belongs to and the Jira issues it references.
+
+By default the changes since the latest tag are listed. The Markdown is
+printed, or prepended to the changelog file with --write.
+
+Examples:
+  tracer changelog
+  tracer changelog --from v1.1.0 --to v1.2.0
+  tracer changelog --version v1.3.0 --write
+  tracer changelog --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		version, _ := cmd.Flags().GetString("version")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		write, _ := cmd.Flags().GetBool("write")
+		file, _ := cmd.Flags().GetString("file")
+		if asJSON && write {
+			return fmt.Errorf("--write writes Markdown and cannot be used with --json")
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		cl, err := buildChangelog(cfg, from, to, version)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if asJSON {
+			data, err := json.MarshalIndent(cl, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal changelog: %w", err)
+			}
+			fmt.Fprintln(out, string(data))
+			return nil
+		}
+
+		markdown := cl.Markdown(cfg.JiraHost)
+		if !write {
+			fmt.Fprint(out, markdown)
+			return nil
+		}
+
+		if file == "" {
+			file = cfg.Changelog.OutputFile()
+		}
+		path, err := writeChangelog(file, markdown)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Added %s to %s\n", cl.Version, path)
+		return nil
+	},
+}
+
+// latestTag returns the most recent tag reachable from the revision, other
+// than the revision itself, or "" when there is none
+func latestTag(rev string) (string, error) {
+	tags, err := utils.GitClient.Tags("--merged", rev, "--sort=-creatordate")
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if tag != rev {
+			return tag, nil
+		}
+	}
+	return "", nil
+}
+
+// buildChangelog builds the changelog of the commits after from up to to.
+// Without from the changes since the latest tag are listed, and without a
+// version the release is named after to when it is a tag.
+func buildChangelog(cfg *config.Config, from, to, version string) (*changelog.Changelog, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	if from == "" {
+		tag, err := latestTag(to)
+		if err != nil {
+			return nil, err
+		}
+		from = tag
+	}
+	if version == "" {
+		version = unreleasedVersion
+		if tags, err := utils.GitClient.Tags(to); err == nil && len(tags) == 1 && tags[0] == to {
+			version = to
+		}
+	}
+
+	revision := to
+	if from != "" {
+		revision = from + ".." + to
+	}
+	entries, err := utils.GitClient.Log("--no-merges", revision)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := story.ListStories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+	index := story.NewCommitIndex(stories)
+
+	commits := make([]changelog.Commit, 0, len(entries))
+	for _, entry := range entries {
+		c := changelog.Commit{Hash: entry.Hash, Message: entry.Message}
+		if s := index.StoryOf(entry.Hash, entry.Message); s != nil {
+			c.StoryKey, c.StoryTitle = s.Key(), s.Title
+		}
+		commits = append(commits, c)
+	}
+
+	cl := changelog.Build(commits, cfg.Changelog)
+	cl.Version = version
+	cl.Date = changelogNow()
+	cl.From = from
+	cl.To = to
+	return cl, nil
+}
+
+// writeChangelog prepends a release section to the changelog file, relative
+// to the repository root, and returns its path
+func writeChangelog(file, markdown string) (string, error) {
+	path := file
+	if !filepath.IsAbs(path) {
+		root, err := utils.GitClient.GetGitRoot()
+		if err != nil {
+			return "", fmt.Errorf("failed to get repository root: %w", err)
+		}
+		path = filepath.Join(root, file)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read changelog: %w", err)
+	}
+	if err := utils.WriteFileAtomic(path, []byte(changelog.Prepend(string(content), markdown)), utils.DefaultFilePerm); err != nil {
+		return "", fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return path, nil
+}
+
+func init() {
+	ChangelogCmd.Flags().String("from", "", "List the commits after this revision (default the latest tag)")
+	ChangelogCmd.Flags().String("to", "HEAD", "List the commits up to this revision")
+	ChangelogCmd.Flags().String("version", "", "Name of the release (default the --to tag, or Unreleased)")
+	ChangelogCmd.Flags().Bool("json", false, "Print the changelog as JSON")
+	ChangelogCmd.Flags().BoolP("write", "w", false, "Prepend the release notes to the changelog file")
+	ChangelogCmd.Flags().StringP("file", "f", "", "Changelog file, relative to the repository root (default from the changelog config)")
+}
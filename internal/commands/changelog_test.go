@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/changelog"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelogCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	origNow := changelogNow
+	defer func() { changelogNow = origNow }()
+	changelogNow = func() time.Time { return time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC) }
+
+	current, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	require.NoError(t, current.Save())
+
+	var logArgs [][]string
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		logArgs = append(logArgs, args)
+		return []utils.LogEntry{
+			{Hash: "aaa1111111", Message: "feat(auth)!: add login\n\nBREAKING CHANGE: sessions expire\nStory: test-project-7"},
+			{Hash: "bbb2222222", Message: "fix: handle timeouts"},
+		}, nil
+	}
+	mockGitClient.TagsFunc = func(args ...string) ([]string, error) {
+		if len(args) > 0 && args[0] == "--merged" {
+			return []string{"v1.1.0", "v1.0.0"}, nil
+		}
+		return nil, nil
+	}
+
+	run := func(args ...string) string {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: "changelog", RunE: ChangelogCmd.RunE, SilenceUsage: true}
+		cmd.Flags().String("from", "", "")
+		cmd.Flags().String("to", "HEAD", "")
+		cmd.Flags().String("version", "", "")
+		cmd.Flags().Bool("json", false, "")
+		cmd.Flags().Bool("write", false, "")
+		cmd.Flags().String("file", "", "")
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	output := run()
+	assert.Equal(t, [][]string{{"--no-merges", "v1.1.0..HEAD"}}, logArgs)
+	assert.Equal(t, `## Unreleased (2026-03-02)
+
+### BREAKING CHANGES
+
+- **auth:** sessions expire (aaa1111, test-project-7 Login page)
+
+### Features
+
+- **auth:** add login (aaa1111, test-project-7 Login page)
+
+### Bug Fixes
+
+- handle timeouts (bbb2222)
+`, output)
+
+	var cl changelog.Changelog
+	require.NoError(t, json.Unmarshal([]byte(run("--from", "v1.0.0", "--to", "v1.1.0", "--version", "v1.1.0", "--json")), &cl))
+	assert.Equal(t, []string{"--no-merges", "v1.0.0..v1.1.0"}, logArgs[len(logArgs)-1])
+	assert.Equal(t, "v1.1.0", cl.Version)
+	assert.Equal(t, "v1.0.0", cl.From)
+	require.Len(t, cl.Sections, 2)
+
+	repoDir, err := mockGitClient.GetGitRoot()
+	require.NoError(t, err)
+	path := filepath.Join(repoDir, "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Changelog\n\n## v1.0.0 (2026-01-01)\n"), 0644))
+
+	output = run("--version", "v1.2.0", "--write")
+	assert.Equal(t, "Added v1.2.0 to "+path+"\n", output)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Changelog\n\n## v1.2.0 (2026-03-02)\n\n### BREAKING CHANGES\n")
+	assert.Contains(t, string(data), "- handle timeouts (bbb2222)\n\n## v1.0.0 (2026-01-01)\n")
+}
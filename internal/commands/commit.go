@@ -1,13 +1,25 @@
 package commands
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+	"github.com/helmedeiros/tracer-bullet/internal/diffplan"
+	"github.com/helmedeiros/tracer-bullet/internal/llm"
 	"github.com/helmedeiros/tracer-bullet/internal/story"
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"github.com/spf13/cobra"
@@ -24,11 +36,16 @@ var CommitCmd = &cobra.Command{
 
 2. View History
    tracer commit show --id <commit-hash>
-   tracer commit list --story <story-id>
+   tracer commit list --story <story>
 
 3. Search and Filter
    tracer commit by --author <author>
    tracer commit since --date <date>
+   tracer commit list --story 42 --since "1 week ago" --type feat
+
+4. Check Messages
+   tracer commit lint origin/main..HEAD
+   tracer commit lint --file .git/COMMIT_EDITMSG
 
 Each command follows a natural progression, helping you:
 - Create well-structured commits
@@ -63,19 +80,72 @@ func buildCommitMessage(commitType, scope, message, body string, breaking bool)
 // addJiraUrl adds the Jira story URL to the commit message if requested
 func addJiraUrl(commitMsg string) (string, error) {
 	cfg, err := config.LoadConfig()
-	if err != nil || cfg.JiraHost == "" || cfg.JiraProject == "" {
+	if err != nil || cfg.JiraHost == "" {
+		return commitMsg, nil
+	}
+
+	// Prefer the Jira issue linked to the current story
+	if s, err := story.GetCurrent(); err == nil && s != nil && s.JiraKey != "" {
+		return commitMsg + "\n\nJira: " + config.JiraIssueURL(cfg.JiraHost, s.JiraKey), nil
+	}
+
+	if cfg.JiraProject == "" {
 		return commitMsg, nil
 	}
 
-	// Get current story from git config
+	// Fall back to the story number kept in git config
 	storyID, err := utils.GitClient.GetConfig(fmt.Sprintf("%s.current.story", cfg.JiraProject))
 	if err != nil || storyID == "" {
 		return commitMsg, nil
 	}
 
 	// Add Jira URL to commit message
-	jiraUrl := fmt.Sprintf("\n\nJira: https://%s/browse/%s-%s", cfg.JiraHost, cfg.JiraProject, storyID)
-	return commitMsg + jiraUrl, nil
+	jiraUrl := config.JiraIssueURL(cfg.JiraHost, fmt.Sprintf("%s-%s", cfg.JiraProject, storyID))
+	return commitMsg + "\n\nJira: " + jiraUrl, nil
+}
+
+// addCommitToCurrentStory records the commit and its files on the current story, if any
+func addCommitToCurrentStory(commitHash, commitMsg, author string) error {
+	s, err := story.GetCurrent()
+	if err != nil || s == nil {
+		return nil
+	}
+
+	// Read the commit back from git for its files, falling back to what we know
+	commit := story.Commit{Hash: commitHash, Message: commitMsg, Author: author, Timestamp: time.Now()}
+	var files []story.File
+	if entries, err := utils.GitClient.Log("-1", strings.TrimSpace(commitHash)); err == nil && len(entries) == 1 {
+		commit, files = story.CommitFromLog(entries[0])
+	}
+
+	// The post-commit hook may have recorded the commit already
+	if hasCommit(s, commit.Hash) {
+		return nil
+	}
+
+	// Add the commit to the story, retrying if another process saved it meanwhile
+	if err := story.Update(s, func(s *story.Story) error {
+		if hasCommit(s, commit.Hash) {
+			return nil
+		}
+		s.AddCommit(commit.Hash, commit.Message, commit.Author, commit.Timestamp)
+		s.Files = append(s.Files, files...)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update story: %w", err)
+	}
+
+	return nil
+}
+
+// hasCommit reports whether the commit is recorded on the story
+func hasCommit(s *story.Story, hash string) bool {
+	for _, c := range s.Commits {
+		if strings.TrimSpace(c.Hash) == strings.TrimSpace(hash) {
+			return true
+		}
+	}
+	return false
 }
 
 // addBreakingChange adds the breaking change footer if needed
@@ -91,22 +161,181 @@ func addBreakingChange(commitMsg, message, body string, breaking bool) string {
 	return commitMsg
 }
 
-// validateCommitType checks if the commit type is valid
-func validateCommitType(commitType string) error {
-	validTypes := map[string]bool{
-		"feat":     true,
-		"fix":      true,
-		"docs":     true,
-		"style":    true,
-		"refactor": true,
-		"test":     true,
-		"chore":    true,
+// validateCommitType checks if the commit type is one of the allowed types
+func validateCommitType(commitType string, policy config.CommitConfig) error {
+	names := policy.TypeNames()
+	if !slices.Contains(names, commitType) {
+		return fmt.Errorf("invalid commit type: %s. Must be one of: %s", commitType, strings.Join(names, ", "))
 	}
+	return nil
+}
 
-	if !validTypes[commitType] {
-		return fmt.Errorf("invalid commit type: %s. Must be one of: feat, fix, docs, style, refactor, test, chore", commitType)
+// commitPolicy returns the commit message policy of the repository
+func commitPolicy() (config.CommitConfig, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return config.CommitConfig{}, fmt.Errorf("failed to load config: %w", err)
 	}
-	return nil
+	return cfg.Commit, nil
+}
+
+// allowedScopes returns the scopes of the policy and, when enabled, the
+// top-level directories of the repository. No scopes means any scope.
+func allowedScopes(policy config.CommitConfig) []string {
+	scopes := append([]string{}, policy.Scopes...)
+	if !policy.ScopesFromDirs {
+		return scopes
+	}
+
+	root, err := utils.GitClient.GetGitRoot()
+	if err != nil {
+		return scopes
+	}
+	entries, err := os.ReadDir(strings.TrimSpace(root))
+	if err != nil {
+		return scopes
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !slices.Contains(scopes, entry.Name()) {
+			scopes = append(scopes, entry.Name())
+		}
+	}
+	return scopes
+}
+
+// policyRules returns the lint rules of a commit message policy
+func policyRules(policy config.CommitConfig) conventional.Rules {
+	return conventional.Rules{
+		Types:           policy.TypeNames(),
+		Scopes:          allowedScopes(policy),
+		MaxHeaderLength: policy.HeaderLimit(),
+		RequiredFooters: policy.RequiredFooters,
+		RequireStory:    policy.RequireStory,
+	}
+}
+
+// formatCommitTypes lists the commit types of a policy with their descriptions
+func formatCommitTypes(policy config.CommitConfig) string {
+	types := policy.AllowedTypes()
+	width := 0
+	for _, t := range types {
+		width = max(width, len(t.Name))
+	}
+	var list strings.Builder
+	for _, t := range types {
+		fmt.Fprintf(&list, "  %-*s - %s\n", width, t.Name, t.Description)
+	}
+	return strings.TrimSuffix(list.String(), "\n")
+}
+
+// newLLMProvider connects to the language model of the configuration
+var newLLMProvider = llm.New
+
+// commitModel is the language model that writes commit messages, with the
+// timeout of each request and the rules its messages are linted against
+type commitModel struct {
+	generator *utils.CommitMessageGenerator
+	timeout   time.Duration
+	rules     conventional.Rules
+}
+
+// newCommitModel connects to the language model of the configuration and
+// describes the commit message policy of the repository to it
+func newCommitModel() (*commitModel, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	provider, err := newLLMProvider(cfg.LLM)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := cfg.Commit
+	types := make([]utils.PromptType, 0, len(policy.AllowedTypes()))
+	for _, t := range policy.AllowedTypes() {
+		types = append(types, utils.PromptType{Name: t.Name, Description: t.Description})
+	}
+
+	// As for messages written by hand, footers are left to the commit-msg
+	// hook, since the prepare-commit-msg hook may add them
+	rules := policyRules(policy)
+	rules.RequiredFooters, rules.RequireStory = nil, false
+
+	return &commitModel{
+		generator: &utils.CommitMessageGenerator{
+			Provider: provider,
+			Policy: utils.CommitPolicy{
+				Types:           types,
+				Scopes:          allowedScopes(policy),
+				MaxHeaderLength: policy.HeaderLimit(),
+				Footers:         policy.RequiredFooters,
+			},
+			Context: cfg.Prompt.PlanOptions(),
+		},
+		timeout: cfg.LLM.RequestTimeout(),
+		rules:   rules,
+	}, nil
+}
+
+// lint returns the problems of a message against the commit message policy
+func (m *commitModel) lint(message string) []conventional.Problem {
+	_, problems := conventional.Lint(message, m.rules)
+	return problems
+}
+
+// generate asks the model for n commit messages following the author's hint,
+// if any. Identical messages are kept once.
+func (m *commitModel) generate(ctx context.Context, diffs []string, hint string, n int) ([]string, error) {
+	var messages []string
+	for i := 0; i < max(n, 1); i++ {
+		requestCtx, cancel := context.WithTimeout(ctx, m.timeout)
+		message, err := m.generator.Generate(requestCtx, diffs, hint)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		message = trimPreamble(message)
+		if !slices.Contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// trimPreamble removes the introductions some models put before the message
+func trimPreamble(message string) string {
+	for _, preamble := range []string{
+		"Here is a commit message that follows the exact format you specified:\n\n",
+		"Here is a commit message for the provided changes:\n\n",
+		"Here is the generated commit message:\n\n",
+		"Here is the commit message:\n\n",
+	} {
+		message = strings.TrimPrefix(message, preamble)
+	}
+	return message
+}
+
+// completeCommitTypes completes the allowed commit types
+func completeCommitTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	policy, err := commitPolicy()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, t := range policy.AllowedTypes() {
+		completions = append(completions, t.Name+"\t"+t.Description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCommitScopes completes the allowed commit scopes
+func completeCommitScopes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	policy, err := commitPolicy()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return allowedScopes(policy), cobra.ShellCompDirectiveNoFileComp
 }
 
 var commitCreateCmd = &cobra.Command{
@@ -118,9 +347,21 @@ Examples:
   tracer commit create --type feat --message "Add user authentication"
   tracer commit create --type fix --scope api --message "Fix timeout issue"
   tracer commit create --type feat --message "Breaking change" --breaking
-  tracer commit create --auto  # Automatically generate commit message from changes
-
-Commit Types:
+  tracer commit create --auto  # Generate the commit message from the staged changes
+  tracer commit create --auto --all  # Stage and commit all changes, untracked files included
+  tracer commit create --auto --candidates 3  # Choose among three generated messages
+  tracer commit create --auto --hint "mention the retry logic"
+  tracer commit create --auto --yes  # Commit the generated message without reviewing it
+
+With --auto the generated message is shown for review: accept it, edit it in
+$VISUAL or $EDITOR, regenerate it, optionally with a hint for the model, or
+pick one of the candidates. When the changes are the ones commit preview was
+run on, the previewed message is reused. The review is skipped when the input
+is not a terminal. Messages that break the commit message policy are shown
+with their problems and must be edited or regenerated; without a review they
+fail the commit.
+
+Commit Types (defaults, configurable in the commit section of the config):
   feat     - A new feature
   fix      - A bug fix
   docs     - Documentation only changes
@@ -136,7 +377,11 @@ Flags:
   --body     Optional. Detailed description of the change
   --breaking Optional. Mark as a breaking change
   --jira    Optional. Include Jira story URL in commit body
-  --auto    Optional. Automatically generate commit message from changes`,
+  --auto    Optional. Generate the commit message from the staged changes
+  --all     Optional. With --auto, stage and commit all changes
+  --candidates Optional. With --auto, the number of messages to choose from
+  --hint    Optional. With --auto, an instruction for the model
+  --yes     Optional. With --auto, commit the generated message without review`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		commitType, _ := cmd.Flags().GetString("type")
@@ -146,46 +391,57 @@ Flags:
 		breaking, _ := cmd.Flags().GetBool("breaking")
 		includeJira, _ := cmd.Flags().GetBool("jira")
 		auto, _ := cmd.Flags().GetBool("auto")
+		all, _ := cmd.Flags().GetBool("all")
+		candidates, _ := cmd.Flags().GetInt("candidates")
+		hint, _ := cmd.Flags().GetString("hint")
+		yes, _ := cmd.Flags().GetBool("yes")
 
 		// If auto flag is set, generate commit message from changes
 		if auto {
-			// Get unstaged and untracked files
-			unstagedFiles, err := utils.GitClient.GetUnstagedFiles()
-			if err != nil {
-				return fmt.Errorf("failed to get unstaged files: %w", err)
+			if candidates < 1 {
+				return fmt.Errorf("--candidates must be at least 1")
 			}
 
-			untrackedFiles, err := utils.GitClient.GetUntrackedFiles()
+			diffs, err := autoCommitDiffs(all, "commit")
 			if err != nil {
-				return fmt.Errorf("failed to get untracked files: %w", err)
+				return err
 			}
-
-			if len(unstagedFiles) == 0 && len(untrackedFiles) == 0 {
-				return fmt.Errorf("no changes to commit")
+			model, err := newCommitModel()
+			if err != nil {
+				return fmt.Errorf("failed to generate commit message: %w", err)
 			}
 
-			// Get diffs for all files
-			var diffs []string
-			for _, file := range unstagedFiles {
-				diff, err := utils.GitClient.GetDiff(file)
-				if err != nil {
-					return fmt.Errorf("failed to get diff for %s: %w", file, err)
+			// Reuse the message commit preview showed for these changes, or
+			// ask the language model for new ones
+			var messages []string
+			if hint == "" && candidates == 1 {
+				if message, ok := loadPreviewedMessage(diffs); ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "Using the message from commit preview\n")
+					messages = []string{message}
+				}
+			}
+			if messages == nil {
+				if messages, err = model.generate(cmd.Context(), diffs, hint, candidates); err != nil {
+					return fmt.Errorf("failed to generate commit message: %w", err)
 				}
-				diffs = append(diffs, fmt.Sprintf("File: %s\n%s", file, diff))
 			}
 
-			for _, file := range untrackedFiles {
-				content, err := os.ReadFile(file)
+			commitMsg := messages[0]
+			if !yes && isTerminal(cmd.InOrStdin()) {
+				commitMsg, err = reviewCommitMessage(cmd, messages, model.lint, func(hint string) ([]string, error) {
+					return model.generate(cmd.Context(), diffs, hint, candidates)
+				})
 				if err != nil {
-					return fmt.Errorf("failed to read untracked file %s: %w", file, err)
+					return err
 				}
-				diffs = append(diffs, fmt.Sprintf("New file: %s\n%s", file, string(content)))
+			} else if problems := model.lint(commitMsg); len(problems) > 0 {
+				return problemsError(problems)
 			}
 
-			// Use llama to generate commit message
-			commitMsg, err := utils.GenerateCommitMessage(diffs)
+			// Credit the pair session
+			commitMsg, err = addCoAuthors(commitMsg, cmd.ErrOrStderr())
 			if err != nil {
-				return fmt.Errorf("failed to generate commit message: %w", err)
+				return err
 			}
 
 			// Create temporary file for commit message
@@ -195,9 +451,11 @@ Flags:
 			}
 			defer os.Remove(tmpFile)
 
-			// Stage all changes
-			if err := utils.GitClient.StageAll(); err != nil {
-				return fmt.Errorf("failed to stage changes: %w", err)
+			// Stage all changes, when asked to commit them
+			if all {
+				if err := utils.GitClient.StageAll(); err != nil {
+					return fmt.Errorf("failed to stage changes: %w", err)
+				}
 			}
 
 			// Create commit using the temporary file
@@ -211,6 +469,14 @@ Flags:
 				return fmt.Errorf("failed to get commit hash: %w", err)
 			}
 
+			// If we have a current story, associate this commit with it
+			author, _ := utils.GitClient.GetAuthor()
+			if err := addCommitToCurrentStory(commitHash, commitMsg, strings.TrimSpace(author)); err != nil {
+				return err
+			}
+			recordSessionCommit(commitHash, cmd.ErrOrStderr())
+			clearPreviewedMessage()
+
 			// Display success message
 			fmt.Fprintf(cmd.OutOrStdout(), "\nCommit created successfully!\n\n")
 			fmt.Fprintf(cmd.OutOrStdout(), "Details:\n")
@@ -220,21 +486,20 @@ Flags:
 			return nil
 		}
 
+		policy, err := commitPolicy()
+		if err != nil {
+			return err
+		}
+
 		// Validate commit type with better error message
-		if err := validateCommitType(commitType); err != nil {
+		if err := validateCommitType(commitType, policy); err != nil {
 			return fmt.Errorf(`%w
 
 Valid commit types:
-  feat     - A new feature
-  fix      - A bug fix
-  docs     - Documentation only changes
-  style    - Changes that don't affect code meaning
-  refactor - Code changes (no fixes/features)
-  test     - Changes to tests
-  chore    - Changes to build process/tools
+%s
 
 Example:
-  tracer commit create --type feat --message "Add user auth"`, err)
+  tracer commit create --type feat --message "Add user auth"`, err, formatCommitTypes(policy))
 		}
 
 		// Validate message with better guidance
@@ -253,6 +518,14 @@ Usage:
 		// Build commit message
 		commitMsg := buildCommitMessage(commitType, scope, message, body, breaking)
 
+		// Check the scope and header against the policy. Footers are left to
+		// the commit-msg hook, since the prepare-commit-msg hook may add them.
+		rules := policyRules(policy)
+		rules.RequiredFooters, rules.RequireStory = nil, false
+		if _, problems := conventional.Lint(commitMsg, rules); len(problems) > 0 {
+			return problemsError(problems)
+		}
+
 		// Add Jira story URL if requested
 		if includeJira {
 			var err error
@@ -266,10 +539,13 @@ Usage:
 		commitMsg = addBreakingChange(commitMsg, message, body, breaking)
 
 		// Clean up the commit message
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is a commit message that follows the exact format you specified:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is a commit message for the provided changes:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is the generated commit message:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is the commit message:\n\n")
+		commitMsg = trimPreamble(commitMsg)
+
+		// Credit the pair session
+		commitMsg, err = addCoAuthors(commitMsg, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
 
 		// Create temporary file for commit message
 		configDir, err := utils.GetConfigDir()
@@ -312,31 +588,10 @@ Try these steps:
 		}
 
 		// If we have a current story, associate this commit with it
-		cfg, err := config.LoadConfig()
-		if err == nil && cfg.JiraHost != "" && cfg.JiraProject != "" {
-			storyID, err := utils.GitClient.GetConfig(fmt.Sprintf("%s.current.story", cfg.JiraProject))
-			if err == nil && storyID != "" {
-				// Load the story
-				s, err := story.LoadStory(storyID)
-				if err == nil {
-					// Add the commit to the story
-					s.AddCommit(commitHash, commitMsg, author, time.Now())
-
-					// Get changed files
-					files, err := utils.GitClient.GetChangedFiles()
-					if err == nil {
-						for _, file := range files {
-							s.AddFile(file, "M") // Assuming modified for now
-						}
-					}
-
-					// Save the updated story
-					if err := s.Save(); err != nil {
-						return fmt.Errorf("failed to update story: %w", err)
-					}
-				}
-			}
+		if err := addCommitToCurrentStory(commitHash, commitMsg, author); err != nil {
+			return err
 		}
+		recordSessionCommit(commitHash, cmd.ErrOrStderr())
 
 		// Display success message with next steps
 		fmt.Fprintf(cmd.OutOrStdout(), "\nCommit created successfully!\n\n")
@@ -365,67 +620,899 @@ var commitPreviewCmd = &cobra.Command{
 	Long: `Preview a commit message that would be generated from your changes.
 
 Examples:
-  tracer commit preview --auto  # Preview auto-generated commit message from changes
+  tracer commit preview --auto        # Preview the message for the staged changes
+  tracer commit preview --auto --all  # Preview the message for all changes
+  tracer commit preview --auto --hint "mention the retry logic"
+
+The previewed message is kept, and commit create --auto reuses it as long as
+the changes stay the same.
 
 Flags:
-  --auto    Optional. Automatically generate commit message from changes`,
+  --auto    Optional. Generate the commit message from the staged changes
+  --all     Optional. With --auto, use all changes, untracked files included
+  --hint    Optional. With --auto, an instruction for the model`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get flag values
 		auto, _ := cmd.Flags().GetBool("auto")
+		all, _ := cmd.Flags().GetBool("all")
+		hint, _ := cmd.Flags().GetString("hint")
 
 		if !auto {
 			return fmt.Errorf("preview command currently only supports --auto flag")
 		}
 
-		// Get unstaged and untracked files
-		unstagedFiles, err := utils.GitClient.GetUnstagedFiles()
+		diffs, err := autoCommitDiffs(all, "preview")
 		if err != nil {
-			return fmt.Errorf("failed to get unstaged files: %w", err)
+			return err
 		}
 
-		untrackedFiles, err := utils.GitClient.GetUntrackedFiles()
+		// Ask the language model for a commit message
+		model, err := newCommitModel()
 		if err != nil {
-			return fmt.Errorf("failed to get untracked files: %w", err)
+			return fmt.Errorf("failed to generate commit message: %w", err)
+		}
+		messages, err := model.generate(cmd.Context(), diffs, hint, 1)
+		if err != nil {
+			return fmt.Errorf("failed to generate commit message: %w", err)
+		}
+		commitMsg := messages[0]
+
+		// Keep the message for commit create
+		if err := savePreviewedMessage(diffs, commitMsg); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "tracer: %v\n", err)
 		}
 
-		if len(unstagedFiles) == 0 && len(untrackedFiles) == 0 {
-			return fmt.Errorf("no changes to preview")
+		// Display the preview
+		fmt.Fprintf(cmd.OutOrStdout(), "\nPreview of commit message:\n\n%s\n", commitMsg)
+		printContextPlan(cmd.OutOrStdout(), model.generator.PlanContext(diffs))
+		createCmd := "tracer commit create --auto"
+		if all {
+			createCmd += " --all"
 		}
+		fmt.Fprintf(cmd.OutOrStdout(), "\nTo create this commit, run:\n  %s\n", createCmd)
 
-		// Get diffs for all files
-		var diffs []string
-		for _, file := range unstagedFiles {
-			diff, err := utils.GitClient.GetDiff(file)
+		return nil
+	},
+}
+
+// autoCommitDiffs returns the diffs a commit message is generated from: the
+// staged changes or, with all, the unstaged changes and untracked files that
+// --all stages. The action, commit or preview, is named in errors.
+func autoCommitDiffs(all bool, action string) ([]string, error) {
+	var diffs []string
+	if !all {
+		stagedFiles, err := utils.GitClient.GetStagedFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get staged files: %w", err)
+		}
+		if len(stagedFiles) == 0 {
+			return nil, fmt.Errorf("no changes to %s: nothing is staged. Stage changes with git add, or use --all to include all changes", action)
+		}
+		for _, file := range stagedFiles {
+			diff, err := utils.GitClient.GetStagedDiff(file)
 			if err != nil {
-				return fmt.Errorf("failed to get diff for %s: %w", file, err)
+				return nil, fmt.Errorf("failed to get staged diff for %s: %w", file, err)
 			}
 			diffs = append(diffs, fmt.Sprintf("File: %s\n%s", file, diff))
 		}
+		return diffs, nil
+	}
+
+	// Get unstaged and untracked files
+	unstagedFiles, err := utils.GitClient.GetUnstagedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unstaged files: %w", err)
+	}
+
+	untrackedFiles, err := utils.GitClient.GetUntrackedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get untracked files: %w", err)
+	}
+
+	if len(unstagedFiles) == 0 && len(untrackedFiles) == 0 {
+		return nil, fmt.Errorf("no changes to %s", action)
+	}
+
+	// Get diffs for all files
+	for _, file := range unstagedFiles {
+		diff, err := utils.GitClient.GetDiff(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get diff for %s: %w", file, err)
+		}
+		diffs = append(diffs, fmt.Sprintf("File: %s\n%s", file, diff))
+	}
+
+	for _, file := range untrackedFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read untracked file %s: %w", file, err)
+		}
+		diffs = append(diffs, fmt.Sprintf("New file: %s\n%s", file, string(content)))
+	}
+	return diffs, nil
+}
+
+// printContextPlan shows how much of each changed file the model was shown
+func printContextPlan(out io.Writer, plan *diffplan.Plan) {
+	if len(plan.Files) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nChanges shown to the model (about %d of %d tokens):\n", plan.Tokens, plan.Budget)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, f := range plan.Files {
+		fmt.Fprintf(w, "  %s\t%s\t+%d -%d", f.Status, f.Path, f.Stats.Added, f.Stats.Removed)
+		switch f.Status {
+		case diffplan.StatusTruncated:
+			fmt.Fprintf(w, "\t%d lines not shown", f.Omitted)
+		case diffplan.StatusSkipped:
+			fmt.Fprintf(w, "\t%s", f.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// reviewCommitMessage shows the generated messages and lets the author accept
+// or pick one, edit it, or regenerate them with an optional hint for the model.
+// The problems lint finds are shown with each message, and a message with
+// problems cannot be accepted until it is edited or regenerated.
+func reviewCommitMessage(cmd *cobra.Command, messages []string, lint func(message string) []conventional.Problem, regenerate func(hint string) ([]string, error)) (string, error) {
+	out := cmd.OutOrStdout()
+	in := bufio.NewReader(cmd.InOrStdin())
+	for {
+		problems := make([][]conventional.Problem, len(messages))
+		for i, message := range messages {
+			problems[i] = lint(message)
+		}
+		if len(messages) == 1 {
+			fmt.Fprintf(out, "\nGenerated commit message:\n\n%s\n", messages[0])
+			printProblems(out, problems[0])
+			fmt.Fprintf(out, "\n[a]ccept, [e]dit, [r]egenerate [hint], [q]uit: ")
+		} else {
+			for i, message := range messages {
+				fmt.Fprintf(out, "\nCandidate %d:\n\n%s\n", i+1, message)
+				printProblems(out, problems[i])
+			}
+			fmt.Fprintf(out, "\nChoose [1-%d], [e]dit <n>, [r]egenerate [hint], [q]uit: ", len(messages))
+		}
+
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("commit aborted")
+		}
+		choice, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		arg = strings.TrimSpace(arg)
+
+		switch strings.ToLower(choice) {
+		case "", "a", "accept", "y", "yes":
+			if len(messages) > 1 {
+				fmt.Fprintf(out, "Choose one of the %d messages by number\n", len(messages))
+			} else if len(problems[0]) > 0 {
+				fmt.Fprintf(out, "The message does not follow the conventions. Edit or regenerate it\n")
+			} else {
+				return messages[0], nil
+			}
+		case "e", "edit":
+			i := 0
+			if len(messages) > 1 {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 || n > len(messages) {
+					fmt.Fprintf(out, "Choose the message to edit, such as e 1\n")
+					continue
+				}
+				i = n - 1
+			}
+			edited, err := editCommitMessage(messages[i])
+			if err != nil {
+				fmt.Fprintf(out, "%v\n", err)
+				continue
+			}
+			messages = []string{edited}
+		case "r", "regenerate":
+			regenerated, err := regenerate(arg)
+			if err != nil {
+				fmt.Fprintf(out, "failed to generate commit message: %v\n", err)
+				continue
+			}
+			messages = regenerated
+		case "q", "quit":
+			return "", fmt.Errorf("commit aborted")
+		default:
+			n, err := strconv.Atoi(choice)
+			switch {
+			case err != nil || n < 1 || n > len(messages):
+				fmt.Fprintf(out, "Unknown choice: %s\n", choice)
+			case len(problems[n-1]) > 0:
+				fmt.Fprintf(out, "Candidate %d does not follow the conventions. Edit it with e %d or regenerate\n", n, n)
+			default:
+				return messages[n-1], nil
+			}
+		}
+	}
+}
+
+// printProblems lists the problems of a generated message, if any
+func printProblems(out io.Writer, problems []conventional.Problem) {
+	if len(problems) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nDoes not follow the conventions:\n")
+	for _, p := range problems {
+		fmt.Fprintf(out, "  %s\n", p)
+	}
+}
+
+// editCommitMessage opens the message in the user's editor and returns it
+// without comment lines, as git commit does
+func editCommitMessage(message string) (string, error) {
+	tmpFile, err := os.CreateTemp("", "tracer-commit-msg-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	content := message + "\n\n# Edit the commit message. Lines starting with # are ignored, and an\n# empty message keeps the generated one.\n"
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := openEditor(tmpFile.Name()); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited message: %w", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	edited := strings.TrimSpace(strings.Join(lines, "\n"))
+	if edited == "" {
+		return "", fmt.Errorf("empty commit message, keeping the generated one")
+	}
+	return edited, nil
+}
+
+// isTerminal reports whether the author can answer prompts on the input. Pipes,
+// files and the null device cannot, while input set on the command, as in
+// tests, can.
+func isTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// previewFile keeps the message commit preview showed. It lives in the git
+// directory, so it is never committed.
+const previewFile = "tracer-commit-preview.json"
+
+// previewedMessage is the message commit preview showed for a set of changes
+type previewedMessage struct {
+	Changes   string    `json:"changes"` // SHA-256 of the diffs the message was generated from
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// changesKey identifies the diffs a message was generated from
+func changesKey(diffs []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(diffs, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// savePreviewedMessage keeps the previewed message for commit create
+func savePreviewedMessage(diffs []string, message string) error {
+	path, err := utils.GitClient.GetGitPath(previewFile)
+	if err != nil {
+		return fmt.Errorf("failed to keep the previewed message: %w", err)
+	}
+	data, err := json.MarshalIndent(previewedMessage{Changes: changesKey(diffs), Message: message, CreatedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the previewed message: %w", err)
+	}
+	if err := utils.WriteFileAtomic(path, data, utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to keep the previewed message: %w", err)
+	}
+	return nil
+}
+
+// loadPreviewedMessage returns the message commit preview showed, when it was
+// generated from the same diffs
+func loadPreviewedMessage(diffs []string) (string, bool) {
+	path, err := utils.GitClient.GetGitPath(previewFile)
+	if err != nil || path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var previewed previewedMessage
+	if err := json.Unmarshal(data, &previewed); err != nil || previewed.Changes != changesKey(diffs) || previewed.Message == "" {
+		return "", false
+	}
+	return previewed.Message, true
+}
+
+// clearPreviewedMessage forgets the previewed message once it is committed
+func clearPreviewedMessage() {
+	if path, err := utils.GitClient.GetGitPath(previewFile); err == nil && path != "" {
+		_ = os.Remove(path)
+	}
+}
+
+// lintResult is the outcome of linting one commit message
+type lintResult struct {
+	Commit   string                 `json:"commit,omitempty"`
+	File     string                 `json:"file,omitempty"`
+	Header   string                 `json:"header"`
+	Valid    bool                   `json:"valid"`
+	Problems []conventional.Problem `json:"problems"`
+	Parsed   *conventional.Message  `json:"parsed,omitempty"`
+}
+
+// commitLintRules returns the lint rules of the commit message policy,
+// overridden by the flags of cmd when given
+func commitLintRules(cmd *cobra.Command) (conventional.Rules, error) {
+	policy, err := commitPolicy()
+	if err != nil {
+		return conventional.Rules{}, err
+	}
+	rules := policyRules(policy)
+	if cmd == nil {
+		return rules, nil
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("types") {
+		rules.Types, _ = flags.GetStringSlice("types")
+	}
+	if flags.Changed("scopes") {
+		rules.Scopes, _ = flags.GetStringSlice("scopes")
+	}
+	if flags.Changed("max-header-length") {
+		rules.MaxHeaderLength, _ = flags.GetInt("max-header-length")
+		if rules.MaxHeaderLength < 0 {
+			return rules, fmt.Errorf("invalid max header length: %d", rules.MaxHeaderLength)
+		}
+	}
+	if flags.Changed("require-story") {
+		rules.RequireStory, _ = flags.GetBool("require-story")
+	}
+	return rules, nil
+}
+
+// problemsError returns an error listing the problems of a commit message
+func problemsError(problems []conventional.Problem) error {
+	var msg strings.Builder
+	msg.WriteString("commit message does not follow the conventions:")
+	for _, p := range problems {
+		msg.WriteString("\n  " + p.String())
+	}
+	return fmt.Errorf("%s", msg.String())
+}
+
+// lintMessage lints one commit message
+func lintMessage(message string, rules conventional.Rules) lintResult {
+	parsed, problems := conventional.Lint(message, rules)
+	if problems == nil {
+		problems = []conventional.Problem{}
+	}
+	return lintResult{
+		Header:   conventional.Header(message),
+		Valid:    len(problems) == 0,
+		Problems: problems,
+		Parsed:   parsed,
+	}
+}
+
+// readMessageFile reads a commit message from a file, or from stdin for "-"
+func readMessageFile(cmd *cobra.Command, file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+	return string(data), nil
+}
+
+var commitLintCmd = &cobra.Command{
+	Use:   "lint [<range>|<revision>]",
+	Short: "Check commit messages against the conventional commit rules",
+	Long: `Check commit messages against the conventional commit rules.
+
+Messages are read from a revision range, a single revision, or a file. Without
+arguments the last commit is checked. Merge, revert and fixup messages written
+by git are skipped. The command fails when any message has problems.
+
+Rules:
+  header-format       <type>(<scope>)!: <description>
+  type-enum           Type is one of the allowed types
+  scope-enum          Scope is one of the allowed scopes, when any are set
+  description-empty   Description is not empty
+  header-max-length   Header is at most --max-header-length characters
+  body-leading-blank  Body is separated from the header by a blank line
+  story-reference     With --require-story, a Story, Refs or Jira footer or a Jira key
+
+Examples:
+  tracer commit lint
+  tracer commit lint origin/main..HEAD
+  tracer commit lint --file .git/COMMIT_EDITMSG
+  tracer commit lint origin/main..HEAD --require-story --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if file != "" && len(args) > 0 {
+			return fmt.Errorf("give either a revision range or --file, not both")
+		}
+
+		rules, err := commitLintRules(cmd)
+		if err != nil {
+			return err
+		}
 
-		for _, file := range untrackedFiles {
-			content, err := os.ReadFile(file)
+		var results []lintResult
+		if file != "" {
+			message, err := readMessageFile(cmd, file)
+			if err != nil {
+				return err
+			}
+			result := lintMessage(message, rules)
+			result.File = file
+			results = append(results, result)
+		} else {
+			logArgs := []string{"-1", "HEAD"}
+			if len(args) > 0 {
+				logArgs = []string{"-1", args[0]}
+				if strings.Contains(args[0], "..") {
+					logArgs = []string{"--no-merges", args[0]}
+				}
+			}
+			entries, err := utils.GitClient.Log(logArgs...)
 			if err != nil {
-				return fmt.Errorf("failed to read untracked file %s: %w", file, err)
+				return err
+			}
+			// Oldest first, the order the commits were made in
+			for i := len(entries) - 1; i >= 0; i-- {
+				result := lintMessage(entries[i].Message, rules)
+				result.Commit = entries[i].Hash
+				results = append(results, result)
 			}
-			diffs = append(diffs, fmt.Sprintf("New file: %s\n%s", file, string(content)))
 		}
 
-		// Use llama to generate commit message
-		commitMsg, err := utils.GenerateCommitMessage(diffs)
+		failed := 0
+		for _, r := range results {
+			if !r.Valid {
+				failed++
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		if asJSON {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal results: %w", err)
+			}
+			fmt.Fprintln(out, string(data))
+		} else {
+			for _, r := range results {
+				label := shortHash(r.Commit)
+				if r.File != "" {
+					label = r.File
+				}
+				state := "ok  "
+				if !r.Valid {
+					state = "FAIL"
+				}
+				fmt.Fprintf(out, "%s %s %s\n", state, label, r.Header)
+				for _, p := range r.Problems {
+					fmt.Fprintf(out, "       %s\n", p)
+				}
+			}
+			fmt.Fprintf(out, "\n%d message(s) checked, %d with problems\n", len(results), failed)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d commit message(s) do not follow the conventions", failed, len(results))
+		}
+		return nil
+	},
+}
+
+// commitStory is the story a listed commit belongs to
+type commitStory struct {
+	ID    string `json:"id"`
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+// commitRecord is a commit read from git, with the story it belongs to and
+// its parsed conventional commit message
+type commitRecord struct {
+	Hash         string                `json:"hash"`
+	Author       string                `json:"author"`
+	Email        string                `json:"email"`
+	CoAuthors    []string              `json:"co_authors,omitempty"`
+	Date         time.Time             `json:"date"`
+	Header       string                `json:"header"`
+	Message      string                `json:"message"`
+	Story        *commitStory          `json:"story,omitempty"`
+	Conventional *conventional.Message `json:"conventional,omitempty"`
+	Files        []story.File          `json:"files,omitempty"`
+}
+
+// commitFilter selects commits. Empty fields select everything.
+type commitFilter struct {
+	Revision string       // Revision or range, HEAD by default or every branch for a story
+	Story    *story.Story // Only commits of the story
+	Author   string       // Only commits with an author or co-author matching this name or email
+	Since    string       // Only commits after this date, in any format git understands
+	Until    string       // Only commits before this date
+	Types    []string     // Only conventional commits of these types
+	Scopes   []string     // Only conventional commits with these scopes
+	Limit    int          // Most commits returned, all when 0
+}
+
+// addCommitFilterFlags registers the flags of the commit listing commands
+func addCommitFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("story", "s", "", "Only commits of this story (number, ID or ID prefix, branch name or Jira key)")
+	cmd.Flags().StringP("author", "a", "", "Only commits with this author or co-author (name or email, partial)")
+	cmd.Flags().String("since", "", "Only commits after this date (e.g. 2026-03-01 or \"1 week ago\")")
+	cmd.Flags().String("until", "", "Only commits before this date")
+	cmd.Flags().StringSliceP("type", "t", nil, "Only conventional commits of these types (comma-separated)")
+	cmd.Flags().StringSlice("scope", nil, "Only conventional commits with these scopes (comma-separated)")
+	cmd.Flags().IntP("limit", "n", 0, "Show at most this many commits, 0 for all")
+	cmd.Flags().Bool("json", false, "Print the commits as JSON")
+	_ = cmd.RegisterFlagCompletionFunc("type", completeCommitTypes)
+	_ = cmd.RegisterFlagCompletionFunc("scope", completeCommitScopes)
+}
+
+// commitFilterFromFlags builds a commit filter from the flags of a listing command
+func commitFilterFromFlags(cmd *cobra.Command) (commitFilter, error) {
+	var f commitFilter
+	f.Author, _ = cmd.Flags().GetString("author")
+	f.Since, _ = cmd.Flags().GetString("since")
+	f.Until, _ = cmd.Flags().GetString("until")
+	f.Types, _ = cmd.Flags().GetStringSlice("type")
+	f.Scopes, _ = cmd.Flags().GetStringSlice("scope")
+	f.Limit, _ = cmd.Flags().GetInt("limit")
+	if f.Limit < 0 {
+		return f, fmt.Errorf("invalid limit: %d", f.Limit)
+	}
+
+	if ref, _ := cmd.Flags().GetString("story"); ref != "" {
+		s, err := story.Resolve(ref)
 		if err != nil {
-			return fmt.Errorf("failed to generate commit message: %w", err)
+			return f, fmt.Errorf("failed to load story: %w", err)
 		}
+		f.Story = s
+	}
+	return f, nil
+}
 
-		// Clean up the commit message
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is a commit message that follows the exact format you specified:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is a commit message for the provided changes:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is the generated commit message:\n\n")
-		commitMsg = strings.TrimPrefix(commitMsg, "Here is the commit message:\n\n")
+// newCommitRecord annotates a commit read from git
+func newCommitRecord(entry utils.LogEntry, index *story.CommitIndex) commitRecord {
+	commit, files := story.CommitFromLog(entry)
+	record := commitRecord{
+		Hash:      entry.Hash,
+		Author:    entry.Author,
+		Email:     entry.Email,
+		CoAuthors: commit.CoAuthors,
+		Date:      entry.Timestamp,
+		Header:    conventional.Header(entry.Message),
+		Message:   entry.Message,
+		Files:     files,
+	}
+	if parsed, err := conventional.Parse(entry.Message); err == nil {
+		record.Conventional = parsed
+	}
+	if s := index.StoryOf(entry.Hash, entry.Message); s != nil {
+		record.Story = &commitStory{ID: s.ID, Key: s.Key(), Title: s.Title}
+	}
+	return record
+}
 
-		// Display the preview
-		fmt.Fprintf(cmd.OutOrStdout(), "\nPreview of commit message:\n\n%s\n", commitMsg)
-		fmt.Fprintf(cmd.OutOrStdout(), "\nTo create this commit, run:\n  tracer commit create --auto\n")
+// matches reports whether the commit is selected by the filter, apart from
+// the revision and dates git selects on
+func (f commitFilter) matches(r commitRecord) bool {
+	if f.Story != nil && (r.Story == nil || r.Story.ID != f.Story.ID) {
+		return false
+	}
+	if f.Author != "" {
+		author := strings.ToLower(f.Author)
+		found := strings.Contains(strings.ToLower(r.Author), author) || strings.Contains(strings.ToLower(r.Email), author)
+		for _, coAuthor := range r.CoAuthors {
+			found = found || strings.Contains(strings.ToLower(coAuthor), author)
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Types) > 0 && (r.Conventional == nil || !slices.Contains(f.Types, r.Conventional.Type)) {
+		return false
+	}
+	if len(f.Scopes) > 0 && (r.Conventional == nil || !slices.Contains(f.Scopes, r.Conventional.Scope)) {
+		return false
+	}
+	return true
+}
+
+// listCommits returns the commits selected by the filter, newest first
+func listCommits(f commitFilter) ([]commitRecord, error) {
+	var logArgs []string
+	if f.Since != "" {
+		logArgs = append(logArgs, "--since="+f.Since)
+	}
+	if f.Until != "" {
+		logArgs = append(logArgs, "--until="+f.Until)
+	}
+	switch {
+	case f.Revision != "":
+		logArgs = append(logArgs, f.Revision)
+	case f.Story != nil:
+		// Story commits may be on any branch
+		logArgs = append(logArgs, "--all")
+	default:
+		logArgs = append(logArgs, "HEAD")
+	}
+
+	entries, err := utils.GitClient.Log(logArgs...)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := story.ListStories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+	index := story.NewCommitIndex(stories)
+
+	var records []commitRecord
+	for _, entry := range entries {
+		record := newCommitRecord(entry, index)
+		if !f.matches(record) {
+			continue
+		}
+		records = append(records, record)
+		if f.Limit > 0 && len(records) == f.Limit {
+			break
+		}
+	}
+	return records, nil
+}
+
+// printCommits prints the commits one per line, or as JSON
+func printCommits(cmd *cobra.Command, records []commitRecord) error {
+	out := cmd.OutOrStdout()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		if records == nil {
+			records = []commitRecord{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal commits: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintf(out, "No commits found\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMIT\tDATE\tAUTHORS\tSTORY\tMESSAGE")
+	for _, r := range records {
+		commit := story.Commit{Author: r.Author, CoAuthors: r.CoAuthors}
+		storyKey := "-"
+		if r.Story != nil {
+			storyKey = r.Story.Key
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortHash(r.Hash), r.Date.Local().Format("2006-01-02 15:04"),
+			strings.Join(commit.Authors(), ", "), storyKey, r.Header)
+	}
+	return w.Flush()
+}
+
+// runCommitList lists the commits selected by the flags of cmd and the given filter changes
+func runCommitList(cmd *cobra.Command, revision string, adjust func(*commitFilter)) error {
+	f, err := commitFilterFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	f.Revision = revision
+	if adjust != nil {
+		adjust(&f)
+	}
+
+	records, err := listCommits(f)
+	if err != nil {
+		return err
+	}
+	return printCommits(cmd, records)
+}
+
+var commitListCmd = &cobra.Command{
+	Use:   "list [<revision>|<range>]",
+	Short: "List commits with their stories and authors",
+	Long: `List commits, newest first, with the story each belongs to and its authors,
+including pair and mob co-authors. A commit belongs to the story that recorded
+it or whose key its message references.
+
+Filters combine: only commits matching all of them are listed. Without a
+revision the history of HEAD is listed, or of every branch with --story.
+
+Examples:
+  tracer commit list
+  tracer commit list --story 42 --since "1 week ago"
+  tracer commit list origin/main..HEAD --type feat,fix
+  tracer commit list --author jane --scope api --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		revision := ""
+		if len(args) > 0 {
+			revision = args[0]
+		}
+		return runCommitList(cmd, revision, nil)
+	},
+}
+
+var commitByCmd = &cobra.Command{
+	Use:   "by <author> [<revision>|<range>]",
+	Short: "List commits by an author or co-author",
+	Long: `List the commits an author made or co-authored. The author is matched by
+part of the name or email, case-insensitively. Accepts the filters of list.
+
+Examples:
+  tracer commit by jane
+  tracer commit by --author jane@example.com --since 2026-03-01`,
+	Args:         cobra.MaximumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		author, _ := cmd.Flags().GetString("author")
+		revision := ""
+		if len(args) > 0 {
+			author = args[0]
+		}
+		if len(args) > 1 {
+			revision = args[1]
+		}
+		if author == "" {
+			return fmt.Errorf("author is required. Use 'tracer commit by <author>' or --author")
+		}
+		return runCommitList(cmd, revision, func(f *commitFilter) { f.Author = author })
+	},
+}
+
+var commitSinceCmd = &cobra.Command{
+	Use:   "since <date> [<revision>|<range>]",
+	Short: "List commits made since a date",
+	Long: `List the commits made since a date, in any format git understands such as
+2026-03-01, "2026-03-01 14:00" or "1 week ago". Accepts the filters of list.
 
+Examples:
+  tracer commit since 2026-03-01
+  tracer commit since --date "1 week ago" --story 42`,
+	Args:         cobra.MaximumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, _ := cmd.Flags().GetString("date")
+		revision := ""
+		if len(args) > 0 {
+			date = args[0]
+		}
+		if len(args) > 1 {
+			revision = args[1]
+		}
+		if date == "" {
+			return fmt.Errorf("date is required. Use 'tracer commit since <date>' or --date")
+		}
+		return runCommitList(cmd, revision, func(f *commitFilter) { f.Since = date })
+	},
+}
+
+var commitShowCmd = &cobra.Command{
+	Use:   "show [<revision>]",
+	Short: "Show a commit with its story, authors and conventional commit fields",
+	Long: `Show a commit in detail: its authors and co-authors, the story it belongs
+to, the fields of its conventional commit message and the files it changed.
+Without a revision the last commit is shown.
+
+Examples:
+  tracer commit show
+  tracer commit show --id abc1234
+  tracer commit show HEAD~2 --json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		revision, _ := cmd.Flags().GetString("id")
+		if len(args) > 0 {
+			revision = args[0]
+		}
+		if revision == "" {
+			revision = "HEAD"
+		}
+
+		entries, err := utils.GitClient.Log("-1", revision)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("commit %s not found", revision)
+		}
+		stories, err := story.ListStories()
+		if err != nil {
+			return fmt.Errorf("failed to list stories: %w", err)
+		}
+		r := newCommitRecord(entries[0], story.NewCommitIndex(stories))
+
+		out := cmd.OutOrStdout()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal commit: %w", err)
+			}
+			fmt.Fprintln(out, string(data))
+			return nil
+		}
+
+		fmt.Fprintf(out, "Commit: %s\n", r.Hash)
+		fmt.Fprintf(out, "Author: %s <%s>\n", r.Author, r.Email)
+		for _, coAuthor := range r.CoAuthors {
+			fmt.Fprintf(out, "Co-author: %s\n", coAuthor)
+		}
+		fmt.Fprintf(out, "Date: %s\n", r.Date.Local().Format("2006-01-02 15:04:05"))
+		if r.Story != nil {
+			fmt.Fprintf(out, "Story: %s (%s)\n", r.Story.Key, r.Story.Title)
+		}
+		if c := r.Conventional; c != nil {
+			fmt.Fprintf(out, "Type: %s\n", c.Type)
+			if c.Scope != "" {
+				fmt.Fprintf(out, "Scope: %s\n", c.Scope)
+			}
+			if c.Breaking {
+				fmt.Fprintf(out, "Breaking: yes\n")
+			}
+			fmt.Fprintf(out, "Description: %s\n", c.Description)
+		}
+
+		fmt.Fprintf(out, "\n")
+		for _, line := range strings.Split(r.Message, "\n") {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+
+		if len(r.Files) > 0 {
+			fmt.Fprintf(out, "\nFiles:\n")
+			for _, file := range r.Files {
+				path := file.Path
+				if file.OldPath != "" {
+					path = file.OldPath + " -> " + file.Path
+				}
+				fmt.Fprintf(out, "  %-8s %s (+%d -%d)\n", file.Status, path, file.Additions, file.Deletions)
+			}
+		}
 		return nil
 	},
 }
@@ -433,18 +1520,49 @@ Flags:
 func init() {
 	CommitCmd.AddCommand(commitCreateCmd)
 	CommitCmd.AddCommand(commitPreviewCmd)
+	CommitCmd.AddCommand(commitLintCmd)
+	CommitCmd.AddCommand(commitListCmd)
+	CommitCmd.AddCommand(commitShowCmd)
+	CommitCmd.AddCommand(commitByCmd)
+	CommitCmd.AddCommand(commitSinceCmd)
+
+	// Add flags for the listing commands
+	for _, cmd := range []*cobra.Command{commitListCmd, commitByCmd, commitSinceCmd} {
+		addCommitFilterFlags(cmd)
+	}
+	commitSinceCmd.Flags().String("date", "", "Only commits after this date (same as the first argument)")
+	commitShowCmd.Flags().String("id", "", "Hash or revision of the commit (same as the first argument)")
+	commitShowCmd.Flags().Bool("json", false, "Print the commit as JSON")
+
+	// Add flags for lint command
+	commitLintCmd.Flags().StringP("file", "f", "", "Read the message from a file, - for stdin")
+	commitLintCmd.Flags().StringSlice("types", nil, "Allowed commit types (default from the commit config)")
+	commitLintCmd.Flags().StringSlice("scopes", nil, "Allowed scopes (default from the commit config)")
+	commitLintCmd.Flags().Int("max-header-length", 0, "Longest header allowed, 0 for no limit (default from the commit config)")
+	commitLintCmd.Flags().Bool("require-story", false, "Require a story reference (default from the commit config)")
+	_ = commitLintCmd.RegisterFlagCompletionFunc("types", completeCommitTypes)
+	_ = commitLintCmd.RegisterFlagCompletionFunc("scopes", completeCommitScopes)
+	commitLintCmd.Flags().Bool("json", false, "Print the results as JSON")
 
 	// Add flags with better descriptions
-	commitCreateCmd.Flags().String("type", "", "Type of change (feat, fix, docs, style, refactor, test, chore)")
+	commitCreateCmd.Flags().String("type", "", "Type of change (feat, fix, docs, style, refactor, test, chore, or as configured)")
 	commitCreateCmd.Flags().String("message", "", "Short, descriptive commit message")
 	commitCreateCmd.Flags().String("scope", "", "Scope of the change (e.g., api, core)")
 	commitCreateCmd.Flags().String("body", "", "Detailed description of the change")
 	commitCreateCmd.Flags().Bool("breaking", false, "Mark as a breaking change")
 	commitCreateCmd.Flags().Bool("jira", false, "Include Jira story URL in commit body")
-	commitCreateCmd.Flags().Bool("auto", false, "Automatically generate commit message from changes")
+	commitCreateCmd.Flags().Bool("auto", false, "Generate the commit message from the staged changes")
+	commitCreateCmd.Flags().Bool("all", false, "With --auto, stage and commit all changes, untracked files included")
+	commitCreateCmd.Flags().Int("candidates", 1, "With --auto, the number of messages to generate and choose from")
+	commitCreateCmd.Flags().String("hint", "", "With --auto, an instruction for the model, such as \"mention the retry logic\"")
+	commitCreateCmd.Flags().BoolP("yes", "y", false, "With --auto, commit the generated message without reviewing it")
+	_ = commitCreateCmd.RegisterFlagCompletionFunc("type", completeCommitTypes)
+	_ = commitCreateCmd.RegisterFlagCompletionFunc("scope", completeCommitScopes)
 
 	// Add flags for preview command
-	commitPreviewCmd.Flags().Bool("auto", false, "Automatically generate commit message from changes")
+	commitPreviewCmd.Flags().Bool("auto", false, "Generate the commit message from the staged changes")
+	commitPreviewCmd.Flags().Bool("all", false, "With --auto, use all changes, untracked files included")
+	commitPreviewCmd.Flags().String("hint", "", "With --auto, an instruction for the model, such as \"mention the retry logic\"")
 
 	// Mark required flags only if auto is not set
 	commitCreateCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
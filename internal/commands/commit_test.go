@@ -2,17 +2,25 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+	"github.com/helmedeiros/tracer-bullet/internal/llm"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommitCommand(t *testing.T) {
@@ -208,9 +216,12 @@ func TestAutoCommit(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override llama API URL for testing
-	err = utils.SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
+	// Point the language model at the test server
+	origProvider := newLLMProvider
+	defer func() { newLLMProvider = origProvider }()
+	newLLMProvider = func(llm.Config) (llm.Provider, error) {
+		return llm.NewOllama(llm.Config{Endpoint: server.URL}), nil
+	}
 
 	// Create a mock git client
 	mockGit := utils.NewMockGit()
@@ -226,7 +237,9 @@ func TestAutoCommit(t *testing.T) {
 	mockGit.(*utils.MockGit).GetDiffFunc = func(file string) (string, error) {
 		return "diff content", nil
 	}
+	staged := false
 	mockGit.(*utils.MockGit).StageAllFunc = func() error {
+		staged = true
 		return nil
 	}
 	mockGit.(*utils.MockGit).CommitWithFileFunc = func(file string) error {
@@ -247,7 +260,8 @@ func TestAutoCommit(t *testing.T) {
 	rootCmd.AddCommand(CommitCmd)
 
 	// Set up the command arguments
-	args := []string{"commit", "create", "--auto"}
+	args := []string{"commit", "create", "--auto", "--all"}
+	defer func() { _ = commitCreateCmd.Flags().Set("all", "false") }()
 
 	// Set the command's args
 	rootCmd.SetArgs(args)
@@ -255,6 +269,7 @@ func TestAutoCommit(t *testing.T) {
 	// Execute the command
 	err = rootCmd.Execute()
 	assert.NoError(t, err)
+	assert.True(t, staged, "--all stages the changes")
 }
 
 func TestAutoCommitNoChanges(t *testing.T) {
@@ -371,14 +386,17 @@ func TestAutoCommitErrorHandling(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override llama API URL for testing
-			err := utils.SetLlamaAPIURL(server.URL)
-			assert.NoError(t, err)
+			// Point the language model at the test server
+			origProvider := newLLMProvider
+			defer func() { newLLMProvider = origProvider }()
+			newLLMProvider = func(llm.Config) (llm.Provider, error) {
+				return llm.NewOllama(llm.Config{Endpoint: server.URL}), nil
+			}
 
 			// Create a temporary directory for testing
 			tmpDir := t.TempDir()
 			repoDir := filepath.Join(tmpDir, "repo")
-			err = os.MkdirAll(repoDir, 0755)
+			err := os.MkdirAll(repoDir, 0755)
 			assert.NoError(t, err)
 
 			// Save current directory
@@ -434,7 +452,8 @@ func TestAutoCommitErrorHandling(t *testing.T) {
 			rootCmd.AddCommand(CommitCmd)
 
 			// Set up the command arguments
-			args := []string{"commit", "create", "--auto"}
+			args := []string{"commit", "create", "--auto", "--all"}
+			defer func() { _ = commitCreateCmd.Flags().Set("all", "false") }()
 
 			// Set the command's args
 			rootCmd.SetArgs(args)
@@ -447,6 +466,274 @@ func TestAutoCommitErrorHandling(t *testing.T) {
 	}
 }
 
+// promptRecorder is a language model that records its prompts
+type promptRecorder struct {
+	prompts []string
+	message string
+	replies []string // Returned in turn instead of message when set
+}
+
+func (p *promptRecorder) Name() string { return "recorder" }
+
+func (p *promptRecorder) Generate(ctx context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	if len(p.replies) > 0 {
+		return p.replies[(len(p.prompts)-1)%len(p.replies)], nil
+	}
+	return p.message, nil
+}
+
+// slowModel is a language model that answers only when its request is cancelled
+type slowModel struct{}
+
+func (slowModel) Name() string { return "slow" }
+
+func (slowModel) Generate(ctx context.Context, prompt string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestNewCommitModel(t *testing.T) {
+	tmpDir, _, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	model, err := newCommitModel()
+	require.NoError(t, err)
+
+	// The prompt describes the default policy of the configuration
+	var types []string
+	for _, pt := range model.generator.Policy.Types {
+		types = append(types, pt.Name)
+	}
+	assert.Equal(t, config.CommitConfig{}.TypeNames(), types)
+	assert.Equal(t, config.DefaultCommitMaxHeaderLength, model.generator.Policy.MaxHeaderLength)
+	assert.Equal(t, llm.DefaultTimeout, model.timeout)
+
+	// Messages are linted against the same policy, footers left to the hooks
+	assert.Equal(t, config.CommitConfig{}.TypeNames(), model.rules.Types)
+	assert.Empty(t, model.rules.RequiredFooters)
+	assert.Empty(t, model.lint("fix: retry requests"))
+	assert.NotEmpty(t, model.lint("wip: retry requests"))
+}
+
+func TestCommitModelTimeout(t *testing.T) {
+	model := &commitModel{generator: &utils.CommitMessageGenerator{Provider: slowModel{}}, timeout: 10 * time.Millisecond}
+	_, err := model.generate(context.Background(), []string{"File: a.go\n@@ -1 +1 @@\n+a"}, "", 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAutoCommitStaged(t *testing.T) {
+	tmpDir, mockGit, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	model := &promptRecorder{message: "feat(api): add login"}
+	origProvider := newLLMProvider
+	defer func() { newLLMProvider = origProvider }()
+	newLLMProvider = func(llm.Config) (llm.Provider, error) { return model, nil }
+
+	mockGit.GetStagedFilesFunc = func() ([]string, error) {
+		return []string{"api.go"}, nil
+	}
+	mockGit.GetStagedDiffFunc = func(file string) (string, error) {
+		return "diff --git a/api.go b/api.go\n--- a/api.go\n+++ b/api.go\n@@ -1 +1,2 @@\n package api\n+func Login() {}\n", nil
+	}
+	mockGit.GetUnstagedFilesFunc = func() ([]string, error) {
+		t.Error("unstaged changes are only read with --all")
+		return nil, nil
+	}
+	mockGit.StageAllFunc = func() error {
+		t.Error("changes are only staged with --all")
+		return nil
+	}
+	var committed string
+	mockGit.CommitWithFileFunc = func(file string) error {
+		data, err := os.ReadFile(file)
+		committed = string(data)
+		return err
+	}
+	previewPath := filepath.Join(tmpDir, previewFile)
+	mockGit.GetGitPathFunc = func(name string) (string, error) {
+		return filepath.Join(tmpDir, name), nil
+	}
+
+	rootCmd := &cobra.Command{Use: "tracer"}
+	rootCmd.AddCommand(CommitCmd)
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+
+	rootCmd.SetArgs([]string{"commit", "preview", "--auto"})
+	require.NoError(t, rootCmd.Execute())
+	assert.Contains(t, out.String(), "feat(api): add login")
+	assert.Contains(t, out.String(), "tracer commit create --auto\n")
+	assert.FileExists(t, previewPath)
+	require.Len(t, model.prompts, 1)
+	assert.Contains(t, model.prompts[0], "File: api.go\n@@ -1 +1,2 @@\n package api\n+func Login() {}\n")
+
+	// Create commits the previewed message without asking the model again
+	model.message = "feat(api): add sign in"
+	rootCmd.SetArgs([]string{"commit", "create", "--auto"})
+	require.NoError(t, rootCmd.Execute())
+	assert.Equal(t, "feat(api): add login", committed)
+	assert.Contains(t, out.String(), "Using the message from commit preview")
+	assert.Len(t, model.prompts, 1)
+	assert.NoFileExists(t, previewPath)
+
+	// A preview of other changes is not reused
+	rootCmd.SetArgs([]string{"commit", "preview", "--auto"})
+	require.NoError(t, rootCmd.Execute())
+	mockGit.GetStagedDiffFunc = func(file string) (string, error) {
+		return "@@ -1 +1,2 @@\n package api\n+func Logout() {}\n", nil
+	}
+	rootCmd.SetArgs([]string{"commit", "create", "--auto"})
+	require.NoError(t, rootCmd.Execute())
+	assert.Len(t, model.prompts, 3)
+
+	mockGit.GetStagedFilesFunc = func() ([]string, error) {
+		return nil, fmt.Errorf("index locked")
+	}
+	rootCmd.SetArgs([]string{"commit", "create", "--auto"})
+	assert.ErrorContains(t, rootCmd.Execute(), "failed to get staged files: index locked")
+}
+
+func TestAutoCommitReview(t *testing.T) {
+	tmpDir, mockGit, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	model := &promptRecorder{}
+	origProvider := newLLMProvider
+	defer func() { newLLMProvider = origProvider }()
+	newLLMProvider = func(llm.Config) (llm.Provider, error) { return model, nil }
+
+	originalEditor := openEditor
+	defer func() { openEditor = originalEditor }()
+
+	mockGit.GetStagedFilesFunc = func() ([]string, error) {
+		return []string{"client.go"}, nil
+	}
+	mockGit.GetStagedDiffFunc = func(file string) (string, error) {
+		return "@@ -1 +1,2 @@\n package client\n+func retry() {}\n", nil
+	}
+	var committed string
+	mockGit.CommitWithFileFunc = func(file string) error {
+		data, err := os.ReadFile(file)
+		committed = string(data)
+		return err
+	}
+
+	rootCmd := &cobra.Command{Use: "tracer"}
+	rootCmd.AddCommand(CommitCmd)
+	run := func(input string, args ...string) (string, error) {
+		defer func() {
+			for name, value := range map[string]string{"candidates": "1", "hint": "", "yes": "false"} {
+				_ = commitCreateCmd.Flags().Set(name, value)
+			}
+		}()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetIn(strings.NewReader(input))
+		rootCmd.SetArgs(append([]string{"commit", "create", "--auto"}, args...))
+		committed = ""
+		model.prompts = nil
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		model.replies = []string{"fix: retry requests"}
+		out, err := run("\n")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Generated commit message:\n\nfix: retry requests\n")
+		assert.Equal(t, "fix: retry requests", committed)
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		_, err := run("q\n")
+		assert.EqualError(t, err, "commit aborted")
+		assert.Empty(t, committed)
+
+		_, err = run("")
+		assert.EqualError(t, err, "commit aborted", "no answer aborts")
+	})
+
+	t.Run("regenerate with a hint", func(t *testing.T) {
+		model.replies = []string{"fix: update client", "fix: retry failed requests"}
+		out, err := run("r mention the retry logic\nwhat\na\n")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Unknown choice: what")
+		assert.Equal(t, "fix: retry failed requests", committed)
+		require.Len(t, model.prompts, 2)
+		assert.NotContains(t, model.prompts[0], "mention the retry logic")
+		assert.Contains(t, model.prompts[1], "mention the retry logic")
+	})
+
+	t.Run("choose a candidate", func(t *testing.T) {
+		model.replies = []string{"fix: retry requests", "fix: retry requests", "fix(client): add retries"}
+		out, err := run("a\n2\n", "--candidates", "3", "--hint", "mention the retry logic")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Candidate 1:\n\nfix: retry requests\n")
+		assert.Contains(t, out, "Candidate 2:\n\nfix(client): add retries\n")
+		assert.NotContains(t, out, "Candidate 3:", "identical messages are shown once")
+		assert.Contains(t, out, "Choose one of the 2 messages by number")
+		assert.Equal(t, "fix(client): add retries", committed)
+		require.Len(t, model.prompts, 3)
+		assert.Contains(t, model.prompts[2], "mention the retry logic")
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		model.replies = []string{"fix: retry requests", "fix(client): add retries"}
+		openEditor = func(path string) error {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(data), "fix(client): add retries\n\n# "))
+			return os.WriteFile(path, []byte("fix(client): retry with backoff\n\n- Wait longer each time\n# a comment\n"), 0600)
+		}
+		out, err := run("e\ne 2\ny\n", "--candidates", "2")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Choose the message to edit, such as e 1")
+		assert.Equal(t, "fix(client): retry with backoff\n\n- Wait longer each time", committed)
+
+		openEditor = func(path string) error {
+			return os.WriteFile(path, []byte("# nothing\n"), 0600)
+		}
+		model.replies = []string{"fix: retry requests"}
+		out, err = run("e\na\n")
+		require.NoError(t, err)
+		assert.Contains(t, out, "empty commit message, keeping the generated one")
+		assert.Equal(t, "fix: retry requests", committed)
+	})
+
+	t.Run("policy violations", func(t *testing.T) {
+		model.replies = []string{"wip: retry requests", "fix: retry requests"}
+		out, err := run("a\nr\na\n")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Generated commit message:\n\nwip: retry requests\n\nDoes not follow the conventions:\n  ")
+		assert.Contains(t, out, "The message does not follow the conventions. Edit or regenerate it")
+		assert.Equal(t, "fix: retry requests", committed)
+
+		model.replies = []string{"wip: retry requests", "fix(client): add retries"}
+		out, err = run("1\n2\n", "--candidates", "2")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Candidate 1 does not follow the conventions. Edit it with e 1 or regenerate")
+		assert.Equal(t, "fix(client): add retries", committed)
+
+		model.replies = []string{"wip: retry requests"}
+		_, err = run("", "--yes")
+		assert.ErrorContains(t, err, "commit message does not follow the conventions:")
+		assert.Empty(t, committed)
+	})
+
+	t.Run("yes", func(t *testing.T) {
+		model.replies = []string{"fix: retry requests"}
+		out, err := run("q\n", "--yes")
+		require.NoError(t, err)
+		assert.NotContains(t, out, "Generated commit message")
+		assert.Equal(t, "fix: retry requests", committed)
+
+		_, err = run("", "--candidates", "0")
+		assert.EqualError(t, err, "--candidates must be at least 1")
+	})
+}
+
 func TestPreviewCommit(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir := t.TempDir()
@@ -477,9 +764,12 @@ func TestPreviewCommit(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override llama API URL for testing
-	err = utils.SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
+	// Point the language model at the test server
+	origProvider := newLLMProvider
+	defer func() { newLLMProvider = origProvider }()
+	newLLMProvider = func(llm.Config) (llm.Provider, error) {
+		return llm.NewOllama(llm.Config{Endpoint: server.URL}), nil
+	}
 
 	// Create a mock git client
 	mockGit := utils.NewMockGit()
@@ -507,7 +797,8 @@ func TestPreviewCommit(t *testing.T) {
 	rootCmd.AddCommand(CommitCmd)
 
 	// Set up the command arguments
-	args := []string{"commit", "preview", "--auto"}
+	args := []string{"commit", "preview", "--auto", "--all"}
+	defer func() { _ = commitPreviewCmd.Flags().Set("all", "false") }()
 
 	// Set the command's args
 	rootCmd.SetArgs(args)
@@ -524,7 +815,10 @@ func TestPreviewCommit(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "Preview of commit message")
 	assert.Contains(t, output, "feat: add new feature")
-	assert.Contains(t, output, "tracer commit create --auto")
+	assert.Contains(t, output, "tracer commit create --auto --all")
+	assert.Contains(t, output, "Changes shown to the model")
+	assert.Regexp(t, `full\s+new\.go\s+\+1 -0\n`, output)
+	assert.Regexp(t, `skipped\s+modified\.go\s+\+0 -0\s+no changes\n`, output)
 }
 
 func TestPreviewCommitNoChanges(t *testing.T) {
@@ -573,3 +867,347 @@ func TestPreviewCommitNoChanges(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no changes to preview")
 }
+
+func TestCommitLintCommand(t *testing.T) {
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	utils.GitClient = mockGit
+
+	mockGit.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		switch args[len(args)-1] {
+		case "origin/main..HEAD":
+			// Newest first, as git log lists them
+			return []utils.LogEntry{
+				{Hash: "3333333333", Message: "added export\n"},
+				{Hash: "2222222222", Message: "Merge branch 'main'\n"},
+				{Hash: "1111111111", Message: "feat(api): add export\n\nStory: PROJ-7\n"},
+			}, nil
+		case "HEAD":
+			return []utils.LogEntry{{Hash: "1111111111", Message: "feat(api): add export\n"}}, nil
+		default:
+			return nil, fmt.Errorf("unknown revision")
+		}
+	}
+
+	messageFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(messageFile, []byte("feat(ui): add export\n"), 0644))
+
+	newLintCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "lint", Args: commitLintCmd.Args, RunE: commitLintCmd.RunE, SilenceUsage: true, SilenceErrors: true}
+		cmd.Flags().StringP("file", "f", "", "")
+		cmd.Flags().StringSlice("types", config.CommitConfig{}.TypeNames(), "")
+		cmd.Flags().StringSlice("scopes", nil, "")
+		cmd.Flags().Int("max-header-length", config.DefaultCommitMaxHeaderLength, "")
+		cmd.Flags().Bool("require-story", false, "")
+		cmd.Flags().Bool("json", false, "")
+		return cmd
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectError    bool
+		expectedOutput []string
+	}{
+		{
+			name:           "last commit",
+			args:           []string{},
+			expectedOutput: []string{"ok   1111111 feat(api): add export", "1 message(s) checked, 0 with problems"},
+		},
+		{
+			name:        "range",
+			args:        []string{"origin/main..HEAD"},
+			expectError: true,
+			expectedOutput: []string{
+				"ok   1111111 feat(api): add export",
+				"FAIL 3333333 added export",
+				"header-format: header \"added export\" does not follow the format",
+				"3 message(s) checked, 1 with problems",
+			},
+		},
+		{
+			name:           "file with scope rule",
+			args:           []string{"--file", messageFile, "--scopes", "api,story"},
+			expectError:    true,
+			expectedOutput: []string{"FAIL " + messageFile, "scope-enum: scope \"ui\" is not allowed"},
+		},
+		{
+			name:           "required story",
+			args:           []string{"HEAD", "--require-story"},
+			expectError:    true,
+			expectedOutput: []string{"story-reference"},
+		},
+		{
+			name:        "range and file",
+			args:        []string{"HEAD", "--file", messageFile},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := newLintCmd()
+			cmd.SetOut(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, expected := range tt.expectedOutput {
+				assert.Contains(t, buf.String(), expected)
+			}
+		})
+	}
+
+	t.Run("json output", func(t *testing.T) {
+		var buf bytes.Buffer
+		cmd := newLintCmd()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"origin/main..HEAD", "--json"})
+		assert.Error(t, cmd.Execute())
+
+		var results []lintResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+		require.Len(t, results, 3)
+		assert.Equal(t, "1111111111", results[0].Commit)
+		assert.True(t, results[0].Valid)
+		require.NotNil(t, results[0].Parsed)
+		assert.Equal(t, "api", results[0].Parsed.Scope)
+		assert.Equal(t, []conventional.Footer{{Token: "Story", Value: "PROJ-7"}}, results[0].Parsed.Footers)
+		assert.True(t, results[1].Valid)
+		assert.False(t, results[2].Valid)
+		assert.Equal(t, conventional.RuleHeaderFormat, results[2].Problems[0].Rule)
+	})
+}
+
+// newCommitCreateCmd returns a commit create command with flags of its own
+func newCommitCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "create", RunE: commitCreateCmd.RunE, SilenceUsage: true, SilenceErrors: true}
+	cmd.Flags().String("type", "", "")
+	cmd.Flags().String("message", "", "")
+	cmd.Flags().String("scope", "", "")
+	cmd.Flags().String("body", "", "")
+	cmd.Flags().Bool("breaking", false, "")
+	cmd.Flags().Bool("jira", false, "")
+	cmd.Flags().Bool("auto", false, "")
+	return cmd
+}
+
+func TestCommitPolicyFromConfig(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	repoDir, err := mockGitClient.GetGitRoot()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "internal"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".tracer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", "config.yaml"), []byte(`
+commit:
+  types:
+    - name: perf
+      description: A performance improvement
+    - name: feat
+      description: A new feature
+  scopes: [api]
+  scopes_from_dirs: true
+  required_footers: [Signed-off-by]
+  max_header_length: 30
+`), 0644))
+
+	var committed []string
+	mockGitClient.CommitFunc = func(message string) error {
+		committed = append(committed, message)
+		return nil
+	}
+	mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+		return "abc123", nil
+	}
+	mockGitClient.GetAuthorFunc = func() (string, error) {
+		return "john.doe", nil
+	}
+
+	create := func(args ...string) error {
+		cmd := newCommitCreateCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{name: "configured type", args: []string{"--type", "perf", "--message", "cache stories"}},
+		{name: "scope from a directory", args: []string{"--type", "feat", "--scope", "internal", "--message", "add export"}},
+		{name: "type not configured", args: []string{"--type", "chore", "--message", "tidy"}, expectedError: "Must be one of: perf, feat"},
+		{name: "scope not allowed", args: []string{"--type", "feat", "--scope", "web", "--message", "add export"}, expectedError: "scope-enum"},
+		{name: "header too long", args: []string{"--type", "feat", "--message", "add the export of stories to YAML"}, expectedError: "header-max-length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := create(tt.args...)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+	assert.Equal(t, []string{"perf: cache stories", "feat(internal): add export"}, committed)
+
+	t.Run("valid types are listed with descriptions", func(t *testing.T) {
+		err := create("--type", "chore", "--message", "tidy")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "  perf - A performance improvement\n  feat - A new feature")
+	})
+
+	t.Run("lint rules", func(t *testing.T) {
+		rules, err := commitLintRules(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"perf", "feat"}, rules.Types)
+		assert.Equal(t, []string{"api", "internal"}, rules.Scopes)
+		assert.Equal(t, []string{"Signed-off-by"}, rules.RequiredFooters)
+		assert.Equal(t, 30, rules.MaxHeaderLength)
+		assert.Error(t, validateCommitMessage("perf: cache stories"))
+		assert.NoError(t, validateCommitMessage("perf: cache stories\n\nSigned-off-by: John <john@example.com>"))
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		types, _ := completeCommitTypes(commitCreateCmd, nil, "")
+		assert.Equal(t, []string{"perf\tA performance improvement", "feat\tA new feature"}, types)
+		scopes, _ := completeCommitScopes(commitCreateCmd, nil, "")
+		assert.Equal(t, []string{"api", "internal"}, scopes)
+	})
+}
+
+func TestCommitHistoryCommands(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	mockGitClient.GetConfigFunc = func(key string) (string, error) {
+		switch key {
+		case CurrentProject:
+			return TestProjectName, nil
+		case ProjectUser:
+			return TestUserName, nil
+		}
+		return "", nil
+	}
+
+	current, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	current.AddCommit("abc1234", "feat(api): add login", "John Doe", time.Now())
+	require.NoError(t, current.Save())
+
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	entries := []utils.LogEntry{
+		{
+			Hash: "0123456789", Author: "Sam Smith", Email: "sam@example.com", Timestamp: base.Add(2 * time.Hour),
+			Message: "update readme",
+		},
+		{
+			Hash: "def4567890", Author: "Jane Doe", Email: "jane@example.com", Timestamp: base.Add(time.Hour),
+			Message: "fix: handle timeouts\n\nStory: test-project-7",
+		},
+		{
+			Hash: "abc1234567", Author: "John Doe", Email: "john@example.com", Timestamp: base,
+			Message: "feat(api)!: add login\n\nCo-authored-by: Jane Doe <jane@example.com>",
+			Files:   []utils.FileChange{{Path: "login.go", Status: "A", Additions: 12}},
+		},
+	}
+	var logArgs [][]string
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		logArgs = append(logArgs, args)
+		if len(args) > 0 && args[0] == "-1" {
+			return entries[2:], nil
+		}
+		return entries, nil
+	}
+
+	run := func(source *cobra.Command, args ...string) string {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: source.Use, Args: source.Args, RunE: source.RunE, SilenceUsage: true}
+		if source == commitShowCmd {
+			cmd.Flags().String("id", "", "")
+			cmd.Flags().Bool("json", false, "")
+		} else {
+			addCommitFilterFlags(cmd)
+			cmd.Flags().String("date", "", "")
+		}
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+	hashes := func(output string) []string {
+		var result []string
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n")[1:] {
+			result = append(result, strings.Fields(line)[0])
+		}
+		return result
+	}
+
+	t.Run("list annotates stories and co-authors", func(t *testing.T) {
+		logArgs = nil
+		output := run(commitListCmd)
+		assert.Equal(t, [][]string{{"HEAD"}}, logArgs)
+		assert.Equal(t, []string{"0123456", "def4567", "abc1234"}, hashes(output))
+		assert.Regexp(t, `abc1234\s+2026-03-02 09:00\s+John Doe, Jane Doe\s+test-project-7\s+feat\(api\)!: add login`, output)
+		assert.Regexp(t, `def4567\s+.*\s+Jane Doe\s+test-project-7\s+fix: handle timeouts`, output)
+		assert.Regexp(t, `0123456\s+.*\s+Sam Smith\s+-\s+update readme`, output)
+	})
+
+	t.Run("filters combine", func(t *testing.T) {
+		logArgs = nil
+		output := run(commitListCmd, "--story", "7", "--since", "1 week ago")
+		assert.Equal(t, [][]string{{"--since=1 week ago", "--all"}}, logArgs)
+		assert.Equal(t, []string{"def4567", "abc1234"}, hashes(output))
+
+		output = run(commitListCmd, "--story", "7", "--type", "feat")
+		assert.Equal(t, []string{"abc1234"}, hashes(output))
+
+		output = run(commitListCmd, "--scope", "api", "--author", "JANE")
+		assert.Equal(t, []string{"abc1234"}, hashes(output))
+
+		output = run(commitListCmd, "--limit", "1")
+		assert.Equal(t, []string{"0123456"}, hashes(output))
+
+		output = run(commitListCmd, "--type", "docs")
+		assert.Equal(t, "No commits found\n", output)
+	})
+
+	t.Run("by and since", func(t *testing.T) {
+		output := run(commitByCmd, "jane")
+		assert.Equal(t, []string{"def4567", "abc1234"}, hashes(output), "authored and co-authored")
+
+		logArgs = nil
+		output = run(commitSinceCmd, "2026-03-01", "main..HEAD")
+		assert.Equal(t, [][]string{{"--since=2026-03-01", "main..HEAD"}}, logArgs)
+		assert.Len(t, hashes(output), 3)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var records []commitRecord
+		require.NoError(t, json.Unmarshal([]byte(run(commitListCmd, "--json", "--type", "feat")), &records))
+		require.Len(t, records, 1)
+		assert.Equal(t, current.ID, records[0].Story.ID)
+		assert.Equal(t, "api", records[0].Conventional.Scope)
+		assert.True(t, records[0].Conventional.Breaking)
+		assert.Equal(t, []string{"Jane Doe <jane@example.com>"}, records[0].CoAuthors)
+	})
+
+	t.Run("show", func(t *testing.T) {
+		logArgs = nil
+		output := run(commitShowCmd, "--id", "abc1234")
+		assert.Equal(t, [][]string{{"-1", "abc1234"}}, logArgs)
+		assert.Contains(t, output, "Commit: abc1234567\nAuthor: John Doe <john@example.com>\nCo-author: Jane Doe <jane@example.com>\n")
+		assert.Contains(t, output, "Story: test-project-7 (Login page)\nType: feat\nScope: api\nBreaking: yes\nDescription: add login\n")
+		assert.Contains(t, output, "\nFiles:\n  added    login.go (+12 -0)\n")
+	})
+}
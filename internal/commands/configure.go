@@ -68,6 +68,7 @@ var configureShowCmd = &cobra.Command{
 		fmt.Fprintf(cmd.OutOrStdout(), "Current Configuration:\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "Project: %s\n", cfg.GitRepo)
 		fmt.Fprintf(cmd.OutOrStdout(), "User: %s\n", cfg.AuthorName)
+		fmt.Fprintf(cmd.OutOrStdout(), "Story store: %s\n", cfg.StoryStore)
 		fmt.Fprintf(cmd.OutOrStdout(), "Jira:\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "  Host: %s\n", cfg.JiraHost)
 		fmt.Fprintf(cmd.OutOrStdout(), "  Project: %s\n", cfg.JiraProject)
@@ -271,9 +272,10 @@ func configureProject(projectName string) error {
 
 	// Create or update config file
 	cfg := &config.Config{
-		GitRepo:   projectName,
-		GitBranch: config.DefaultGitBranch,
-		GitRemote: config.DefaultGitRemote,
+		SchemaVersion: config.CurrentConfigSchemaVersion,
+		GitRepo:       projectName,
+		GitBranch:     config.DefaultGitBranch,
+		GitRemote:     config.DefaultGitRemote,
 	}
 
 	data, err := yaml.Marshal(cfg)
@@ -323,10 +325,11 @@ func configureUser(username string) error {
 
 	// Create or update config file
 	cfg := &config.Config{
-		GitRepo:    projectName,
-		GitBranch:  config.DefaultGitBranch,
-		GitRemote:  config.DefaultGitRemote,
-		AuthorName: username,
+		SchemaVersion: config.CurrentConfigSchemaVersion,
+		GitRepo:       projectName,
+		GitBranch:     config.DefaultGitBranch,
+		GitRemote:     config.DefaultGitRemote,
+		AuthorName:    username,
 	}
 
 	data, err := yaml.Marshal(cfg)
@@ -0,0 +1,336 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+	"github.com/helmedeiros/tracer-bullet/internal/hooks"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// storyTrailer is the commit message trailer naming the story a commit belongs to
+const storyTrailer = "Story"
+
+var HooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage the git hooks that connect plain git commands to tracer",
+	Long: `Install git hooks so that commits made with plain git commit and branch
+switches are tracked like those made through tracer:
+
+  prepare-commit-msg  Adds a "Story: <key>" trailer for the current story
+  commit-msg          Rejects messages that break the commit lint rules
+  post-commit         Records the commit on the current story
+  post-checkout       Makes the story of a checked out story branch current
+
+Hooks that are already in place are kept and run before the tracer hooks.
+
+Examples:
+  tracer hooks install
+  tracer hooks status
+  tracer hooks uninstall`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the tracer git hooks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := utils.GitClient.GetHooksDir()
+		if err != nil {
+			return err
+		}
+
+		// Hooks run the binary that installed them, which need not be on the PATH
+		tracerPath, err := os.Executable()
+		if err != nil {
+			tracerPath = "tracer"
+		}
+
+		statuses, err := hooks.Install(dir, tracerPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Installed tracer hooks in %s\n", dir)
+		printHookStatuses(cmd.OutOrStdout(), statuses)
+		return nil
+	},
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the tracer git hooks and restore the hooks they chained",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := utils.GitClient.GetHooksDir()
+		if err != nil {
+			return err
+		}
+
+		statuses, err := hooks.Uninstall(dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed tracer hooks from %s\n", dir)
+		printHookStatuses(cmd.OutOrStdout(), statuses)
+		return nil
+	},
+}
+
+var hooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which tracer git hooks are installed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := utils.GitClient.GetHooksDir()
+		if err != nil {
+			return err
+		}
+
+		statuses, err := hooks.Check(dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Hooks directory: %s\n", dir)
+		printHookStatuses(cmd.OutOrStdout(), statuses)
+		return nil
+	},
+}
+
+// hooksRunCmd is what the installed hook scripts call
+var hooksRunCmd = &cobra.Command{
+	Use:          "run <hook> [args...]",
+	Short:        "Run a tracer git hook",
+	Hidden:       true,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hookArgs := args[1:]
+		switch args[0] {
+		case hooks.CommitMsg:
+			if len(hookArgs) < 1 {
+				return fmt.Errorf("%s: missing message file", hooks.CommitMsg)
+			}
+			return runCommitMsgHook(hookArgs[0])
+		case hooks.PrepareCommitMsg:
+			if len(hookArgs) < 1 {
+				return fmt.Errorf("%s: missing message file", hooks.PrepareCommitMsg)
+			}
+			source := ""
+			if len(hookArgs) > 1 {
+				source = hookArgs[1]
+			}
+			// Never stop a commit because the story could not be added
+			if err := runPrepareCommitMsgHook(hookArgs[0], source, cmd.ErrOrStderr()); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "tracer: %v\n", err)
+			}
+			return nil
+		case hooks.PostCommit:
+			if err := runPostCommitHook(cmd.ErrOrStderr()); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "tracer: failed to record commit: %v\n", err)
+			}
+			return nil
+		case hooks.PostCheckout:
+			// The third argument is 1 for branch checkouts and 0 for file checkouts
+			if len(hookArgs) < 3 || hookArgs[2] != "1" {
+				return nil
+			}
+			s, err := runPostCheckoutHook()
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "tracer: failed to switch story: %v\n", err)
+			} else if s != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "tracer: current story is now %s (%s)\n", s.ID, s.Title)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unknown hook: %s", args[0])
+		}
+	},
+}
+
+// printHookStatuses prints the state of every hook
+func printHookStatuses(out io.Writer, statuses []hooks.Status) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, s := range statuses {
+		fmt.Fprintf(w, "  %s\t%s\n", s.Name, s.State)
+	}
+	w.Flush()
+}
+
+// validateCommitMessage checks a commit message against the commit lint rules
+func validateCommitMessage(message string) error {
+	rules, err := commitLintRules(nil)
+	if err != nil {
+		return err
+	}
+	if _, problems := conventional.Lint(message, rules); len(problems) > 0 {
+		return problemsError(problems)
+	}
+	return nil
+}
+
+// runCommitMsgHook rejects commit messages that are not conventional commits
+func runCommitMsgHook(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	if err := validateCommitMessage(string(data)); err != nil {
+		return fmt.Errorf("%w\n\nExample:\n  feat(api): add story export", err)
+	}
+	return nil
+}
+
+// appendTrailers adds trailers to the trailer block of a commit message,
+// starting one if there is none. Comment lines git appends for the editor stay
+// at the end.
+func appendTrailers(message string, trailers ...string) string {
+	if len(trailers) == 0 {
+		return message
+	}
+	lines := strings.Split(message, "\n")
+
+	// Split off the trailing comments, blank lines and verbose diff
+	end := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") && strings.Contains(line, ">8") {
+			end = i
+			break
+		}
+	}
+	for end > 0 && (strings.HasPrefix(lines[end-1], "#") || strings.TrimSpace(lines[end-1]) == "") {
+		end--
+	}
+	content, rest := lines[:end], lines[end:]
+
+	var result []string
+	switch {
+	case len(content) == 0:
+		// Leave the first line for the subject
+		result = append([]string{"", ""}, trailers...)
+	case endsWithTrailers(content):
+		result = append(append(result, content...), trailers...)
+	default:
+		result = append(append(append(result, content...), ""), trailers...)
+	}
+	if len(rest) == 0 || strings.TrimSpace(rest[0]) != "" {
+		result = append(result, "")
+	}
+
+	return strings.Join(append(result, rest...), "\n")
+}
+
+// trailerLine matches a git trailer such as "Refs: PROJ-12"
+var trailerLine = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*): \S`)
+
+// endsWithTrailers reports whether the last paragraph of a message, other
+// than its subject, consists of git trailers
+func endsWithTrailers(lines []string) bool {
+	start := len(lines)
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start == 0 || start == len(lines) {
+		return false
+	}
+	for _, line := range lines[start:] {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// runPrepareCommitMsgHook adds the key of the current story and the
+// co-authors of the active pair session to the commit message, unless the
+// message already mentions them
+func runPrepareCommitMsgHook(file, source string, out io.Writer) error {
+	// Merges, squashes and reused messages keep their own message
+	if source == "merge" || source == "squash" || source == "commit" {
+		return nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	message := string(data)
+
+	var trailers []string
+	if s, err := story.GetCurrent(); err == nil && s != nil {
+		if key := s.Key(); !strings.Contains(conventional.Clean(message), key) {
+			trailers = append(trailers, storyTrailer+": "+key)
+		}
+	}
+	members, err := pairCoAuthors(out)
+	if err != nil {
+		return err
+	}
+	trailers = append(trailers, missingCoAuthorTrailers(message, members)...)
+	if len(trailers) == 0 {
+		return nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(appendTrailers(message, trailers...)), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write commit message: %w", err)
+	}
+	return nil
+}
+
+// runPostCommitHook records the new commit on the current story and in the
+// running pair and mob sessions
+func runPostCommitHook(out io.Writer) error {
+	head, err := utils.GitClient.GetCurrentHead()
+	if err != nil {
+		return err
+	}
+	head = strings.TrimSpace(head)
+	recordSessionCommit(head, out)
+	author, _ := utils.GitClient.GetAuthor()
+	return addCommitToCurrentStory(head, "", strings.TrimSpace(author))
+}
+
+// runPostCheckoutHook makes the story of the checked out branch the current
+// one and returns it, or nil when the branch is not a story branch
+func runPostCheckoutHook() (*story.Story, error) {
+	branch, err := utils.GitClient.GetCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	// Story branches are always named <type>/<name>
+	if !strings.Contains(branch, "/") {
+		return nil, nil
+	}
+
+	s, err := story.Resolve(branch)
+	if err != nil {
+		return nil, nil
+	}
+
+	current, err := story.GetCurrent()
+	if err == nil && current != nil && current.ID == s.ID {
+		return nil, nil
+	}
+
+	if err := story.SetCurrent(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func init() {
+	HooksCmd.AddCommand(hooksInstallCmd)
+	HooksCmd.AddCommand(hooksUninstallCmd)
+	HooksCmd.AddCommand(hooksStatusCmd)
+	HooksCmd.AddCommand(hooksRunCmd)
+}
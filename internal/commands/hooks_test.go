@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/hooks"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     string
+		expectError bool
+	}{
+		{name: "conventional commit", message: "feat(api): add export\n\nbody\n"},
+		{name: "breaking change", message: "fix!: drop old flag\n"},
+		{name: "comments and verbose diff", message: "# Please enter the message\nchore: tidy\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"},
+		{name: "merge commit", message: "Merge branch 'main' into feature/login\n"},
+		{name: "fixup commit", message: "fixup! feat: add export\n"},
+		{name: "unknown type", message: "feature: add export\n", expectError: true},
+		{name: "missing type", message: "add export\n", expectError: true},
+		{name: "empty message", message: "# only comments\n\n", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommitMessage(tt.message)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		trailers []string
+		expected string
+	}{
+		{
+			name:     "no trailers",
+			message:  "feat: add export\n",
+			expected: "feat: add export\n",
+		},
+		{
+			name:     "empty message with comments",
+			trailers: []string{"Story: PROJ-1"},
+			message:  "\n# Please enter the commit message\n",
+			expected: "\n\nStory: PROJ-1\n\n# Please enter the commit message\n",
+		},
+		{
+			name:     "subject only",
+			trailers: []string{"Story: PROJ-1"},
+			message:  "feat: add export\n",
+			expected: "feat: add export\n\nStory: PROJ-1\n",
+		},
+		{
+			name:     "existing trailers",
+			trailers: []string{"Story: PROJ-1"},
+			message:  "feat: add export\n\nSigned-off-by: John <john@example.com>\n",
+			expected: "feat: add export\n\nSigned-off-by: John <john@example.com>\nStory: PROJ-1\n",
+		},
+		{
+			name:     "several trailers before a verbose diff",
+			message:  "feat: add export\n\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n",
+			trailers: []string{"Story: PROJ-1", "Co-authored-by: Jane <jane@example.com>"},
+			expected: "feat: add export\n\nStory: PROJ-1\nCo-authored-by: Jane <jane@example.com>\n\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, appendTrailers(tt.message, tt.trailers...))
+		})
+	}
+}
+
+func TestHooksRunCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	current, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	require.NoError(t, current.Save())
+	require.NoError(t, story.SetCurrent(current))
+
+	other, err := story.NewStoryWithNumber("Logout", "Description", "john.doe", 8)
+	require.NoError(t, err)
+	require.NoError(t, other.Save())
+
+	run := func(args ...string) (string, error) {
+		var stderr bytes.Buffer
+		cmd := &cobra.Command{Use: "run", Args: hooksRunCmd.Args, RunE: hooksRunCmd.RunE}
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&stderr)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stderr.String(), err
+	}
+
+	t.Run("prepare-commit-msg adds the story key", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "COMMIT_EDITMSG")
+		require.NoError(t, os.WriteFile(file, []byte("feat: add login\n"), 0644))
+
+		_, err := run(hooks.PrepareCommitMsg, file, "message")
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: add login\n\nStory: test-project-7\n", string(data))
+
+		// Running it again leaves the message alone
+		_, err = run(hooks.PrepareCommitMsg, file, "message")
+		require.NoError(t, err)
+		data, err = os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: add login\n\nStory: test-project-7\n", string(data))
+	})
+
+	t.Run("commit-msg rejects invalid messages", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "COMMIT_EDITMSG")
+		require.NoError(t, os.WriteFile(file, []byte("added login\n"), 0644))
+
+		_, err := run(hooks.CommitMsg, file)
+		assert.Error(t, err)
+
+		require.NoError(t, os.WriteFile(file, []byte("feat: add login\n"), 0644))
+		_, err = run(hooks.CommitMsg, file)
+		assert.NoError(t, err)
+	})
+
+	t.Run("post-commit records the commit once", func(t *testing.T) {
+		mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+			return "abc123\n", nil
+		}
+		mockGitClient.GetAuthorFunc = func() (string, error) {
+			return "john.doe", nil
+		}
+		mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+			return []utils.LogEntry{{
+				Hash:      "abc123",
+				Author:    "john.doe",
+				Timestamp: time.Now(),
+				Message:   "feat: add login",
+				Files:     []utils.FileChange{{Path: "login.go", Status: "A", Additions: 5}},
+			}}, nil
+		}
+
+		for i := 0; i < 2; i++ {
+			stderr, err := run(hooks.PostCommit)
+			require.NoError(t, err)
+			assert.Empty(t, stderr)
+		}
+
+		loaded, err := story.LoadStory(current.Filename)
+		require.NoError(t, err)
+		require.Len(t, loaded.Commits, 1)
+		assert.Equal(t, "abc123", loaded.Commits[0].Hash)
+		require.Len(t, loaded.Files, 1)
+		assert.Equal(t, "login.go", loaded.Files[0].Path)
+	})
+
+	t.Run("post-checkout switches to the story of the branch", func(t *testing.T) {
+		mockGitClient.GetCurrentBranchFunc = func() (string, error) {
+			return other.BranchName(), nil
+		}
+
+		// File checkouts are ignored
+		_, err := run(hooks.PostCheckout, "a", "b", "0")
+		require.NoError(t, err)
+		selected, err := story.GetCurrent()
+		require.NoError(t, err)
+		assert.Equal(t, current.ID, selected.ID)
+
+		stderr, err := run(hooks.PostCheckout, "a", "b", "1")
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "current story is now "+other.ID)
+		selected, err = story.GetCurrent()
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, selected.ID)
+
+		// Branches that are not story branches leave the current story alone
+		mockGitClient.GetCurrentBranchFunc = func() (string, error) {
+			return "main", nil
+		}
+		_, err = run(hooks.PostCheckout, "a", "b", "1")
+		require.NoError(t, err)
+		selected, err = story.GetCurrent()
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, selected.ID)
+	})
+
+	t.Run("unknown hook", func(t *testing.T) {
+		_, err := run("pre-push")
+		assert.Error(t, err)
+	})
+}
+
+func TestHooksInstallAndStatusCommands(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	hooksDir := filepath.Join(tmpDir, "hooks")
+	mockGitClient.GetHooksDirFunc = func() (string, error) {
+		return hooksDir, nil
+	}
+	require.NoError(t, os.MkdirAll(hooksDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, hooks.PostCommit), []byte("#!/bin/sh\necho done\n"), 0755))
+
+	run := func(source *cobra.Command) string {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: source.Use, RunE: source.RunE}
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	output := run(hooksStatusCmd)
+	assert.Contains(t, output, hooks.StateForeign)
+
+	output = run(hooksInstallCmd)
+	assert.Contains(t, output, "Installed tracer hooks in "+hooksDir)
+	assert.Contains(t, output, hooks.StateChained)
+	assert.FileExists(t, filepath.Join(hooksDir, hooks.CommitMsg))
+
+	output = run(hooksUninstallCmd)
+	assert.Contains(t, output, "Removed tracer hooks from "+hooksDir)
+	assert.NotContains(t, output, "  "+hooks.StateInstalled)
+	data, err := os.ReadFile(filepath.Join(hooksDir, hooks.PostCommit))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho done\n", string(data))
+}
@@ -156,7 +156,6 @@ var jiraLinkCmd = &cobra.Command{
 		}
 
 		// Get flag values
-		storyID, _ := cmd.Flags().GetString("story")
 		issueID, _ := cmd.Flags().GetString("issue")
 
 		// Verify the Jira issue exists
@@ -165,19 +164,21 @@ var jiraLinkCmd = &cobra.Command{
 			return fmt.Errorf("failed to get Jira issue: %w", err)
 		}
 
-		// Load the story
-		s, err := story.LoadStory(storyID)
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "story")
 		if err != nil {
-			return fmt.Errorf("failed to load story: %w", err)
+			return err
 		}
 
 		// Update story with Jira issue key
-		s.JiraKey = issue.Key
-		if err := story.SaveStory(s); err != nil {
-			return fmt.Errorf("failed to save story: %w", err)
+		if err := story.Update(s, func(s *story.Story) error {
+			s.JiraKey = issue.Key
+			return nil
+		}); err != nil {
+			return err
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Linked story %s to Jira issue %s\n", storyID, issue.Key)
+		fmt.Fprintf(cmd.OutOrStdout(), "Linked story %s to Jira issue %s\n", s.ID, issue.Key)
 		fmt.Fprintf(cmd.OutOrStdout(), "URL: %s/browse/%s\n", cfg.JiraHost, issue.Key)
 		return nil
 	},
@@ -208,14 +209,14 @@ func init() {
 	jiraUpdateCmd.Flags().String("assignee", "", "New assignee")
 
 	// Add link command flags
-	jiraLinkCmd.Flags().String("story", "", "Story ID")
+	jiraLinkCmd.Flags().String("story", "", storyRefUsage)
 	jiraLinkCmd.Flags().String("issue", "", "Jira issue ID")
 
 	// Handle required flags
 	requiredFlags := map[*cobra.Command][]string{
 		jiraCreateCmd: {"title"},
 		jiraUpdateCmd: {"id"},
-		jiraLinkCmd:   {"story", "issue"},
+		jiraLinkCmd:   {"issue"},
 	}
 
 	for cmd, flags := range requiredFlags {
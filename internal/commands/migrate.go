@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+)
+
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade stories and configuration to the current file format",
+	Long: `Rewrite every story in .tracer/stories and the configuration files in the
+current schema version. Files already in the current version are left alone.
+
+tracer reads older files without migrating them, so running this is only needed
+to keep the files on disk readable by tools other than tracer, or before
+sharing the .tracer directory.
+
+Examples:
+  tracer migrate --dry-run   # Show a diff of the changes
+  tracer migrate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		out := cmd.OutOrStdout()
+
+		// Stories
+		migrations, err := story.MigrateStoryFiles(dryRun)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			fmt.Fprintf(out, "Story %s: schema version %d -> %d\n", filepath.Base(m.Path), m.From, story.CurrentSchemaVersion)
+			if dryRun {
+				printMigrationDiff(out, m.Path, m.Old, m.New)
+			}
+		}
+		total := len(migrations)
+
+		// Configuration
+		configFiles, err := config.ConfigFiles()
+		if err != nil {
+			return err
+		}
+		for _, path := range configFiles {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read config file: %w", err)
+			}
+			migrated, version, err := config.MigrateYAML(data)
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s: %w", path, err)
+			}
+			if version == config.CurrentConfigSchemaVersion {
+				continue
+			}
+
+			fmt.Fprintf(out, "Config %s: schema version %d -> %d\n", path, version, config.CurrentConfigSchemaVersion)
+			total++
+			if dryRun {
+				printMigrationDiff(out, path, data, migrated)
+				continue
+			}
+			if err := utils.WriteFileAtomic(path, migrated, utils.DefaultFilePerm); err != nil {
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+		}
+
+		switch {
+		case total == 0:
+			fmt.Fprintf(out, "Everything is already in the current format\n")
+		case dryRun:
+			fmt.Fprintf(out, "\nDry run, %d file(s) would be migrated\n", total)
+		default:
+			fmt.Fprintf(out, "\nMigrated %d file(s)\n", total)
+		}
+
+		return nil
+	},
+}
+
+// printMigrationDiff prints a unified diff of a migrated file
+func printMigrationDiff(out io.Writer, path string, old, migrated []byte) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(old)),
+		B:        difflib.SplitLines(string(migrated)),
+		FromFile: path,
+		ToFile:   path + " (migrated)",
+		Context:  3,
+	})
+	if err != nil {
+		fmt.Fprintf(out, "  (failed to compute diff: %v)\n", err)
+		return
+	}
+	fmt.Fprint(out, diff)
+}
+
+func init() {
+	MigrateCmd.Flags().Bool("dry-run", false, "Only show a diff of the changes that would be made")
+}
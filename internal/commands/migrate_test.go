@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateCommand(t *testing.T) {
+	tmpDir, _, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	// A story and a configuration written by an older tracer
+	storiesDir, err := story.GetStoriesDir()
+	require.NoError(t, err)
+	storyPath := filepath.Join(storiesDir, "abc123.yaml")
+	require.NoError(t, os.WriteFile(storyPath, []byte("id: abc123\ntitle: Legacy\nstatus: open\njirakey: PROJ-1\nfilename: abc123.yaml\n"), 0600))
+
+	repoConfigDir, err := utils.GetRepoConfigDir()
+	require.NoError(t, err)
+	configPath := filepath.Join(repoConfigDir, config.DefaultConfigFile)
+	require.NoError(t, os.WriteFile(configPath, []byte("git_repo: test-project\n"), 0600))
+
+	runMigrate := func(args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  "migrate",
+			RunE: MigrateCmd.RunE,
+		}
+		cmd.Flags().Bool("dry-run", false, "Dry run")
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	t.Run("dry run shows a diff", func(t *testing.T) {
+		output, err := runMigrate("--dry-run")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Story abc123.yaml: schema version 1 -> 2")
+		assert.Contains(t, output, "-jirakey: PROJ-1")
+		assert.Contains(t, output, "+jira_key: PROJ-1")
+		assert.Contains(t, output, "Config "+configPath+": schema version 1 -> 2")
+		assert.Contains(t, output, "+schema_version: 2")
+		assert.Contains(t, output, "Dry run, 2 file(s) would be migrated")
+
+		data, err := os.ReadFile(storyPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "jirakey: PROJ-1")
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		output, err := runMigrate()
+		require.NoError(t, err)
+		assert.Contains(t, output, "Migrated 2 file(s)")
+
+		data, err := os.ReadFile(storyPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "jira_key: PROJ-1")
+		data, err = os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "schema_version: 2")
+	})
+
+	t.Run("nothing left to migrate", func(t *testing.T) {
+		output, err := runMigrate()
+		require.NoError(t, err)
+		assert.Contains(t, output, "Everything is already in the current format")
+	})
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/mob"
+	"github.com/spf13/cobra"
+)
+
+// mobNow and mobSleep tell and pass the time for the rotation timer
+var (
+	mobNow   = time.Now
+	mobSleep = time.Sleep
+)
+
+// mobPollInterval is how often the timer checks for a manual rotation after
+// notifying that one is due
+const mobPollInterval = 5 * time.Second
+
+var MobCmd = &cobra.Command{
+	Use:   "mob",
+	Short: "Manage mob programming sessions",
+	Long: `Manage mob programming sessions with any number of participants:
+
+1. Start a Session
+   tracer mob start alice bob carol   # alice drives first
+   tracer mob start alice bob --rotate 10m
+
+2. Rotate the Driver
+   tracer mob next                    # bob takes the keyboard
+   tracer mob timer                   # Ring when the rotation is due
+
+3. Review and End
+   tracer mob status
+   tracer mob journal                 # Who drove which commits
+   tracer mob stop
+
+Commits made during a session credit every other participant with a
+Co-authored-by trailer, and are journaled along with who was driving.`,
+}
+
+var mobStartCmd = &cobra.Command{
+	Use:   "start <participant> <participant>...",
+	Short: "Start a mob session, the first participant driving",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("rotate")
+
+		s, err := mob.Start(args, interval, mobNow())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\nStarted mob programming session!\n\n")
+		printMobSession(cmd.OutOrStdout(), s)
+		fmt.Fprintf(cmd.OutOrStdout(), "\nNext steps:\n")
+		fmt.Fprintf(cmd.OutOrStdout(), "1. Hand over the keyboard with 'tracer mob next'\n")
+		if s.Interval > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "2. Run 'tracer mob timer' in another terminal to be told when to rotate\n")
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "2. Create commits with 'tracer commit create'\n")
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "3. End the session with 'tracer mob stop'\n")
+		return nil
+	},
+}
+
+var mobNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Hand the keyboard to the next participant",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := mob.Next(mobNow())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is driving, %s is next\n", s.DriverName(), s.NextDriverName())
+		return nil
+	},
+}
+
+var mobStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running mob session",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := mob.Current()
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nNo active mob programming session\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "\nTo start a session:\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "  tracer mob start <participant> <participant>...\n")
+			return nil
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\nActive Mob Programming Session:\n")
+		printMobSession(cmd.OutOrStdout(), s)
+		if due := s.NextRotation(); !due.IsZero() {
+			if wait := due.Sub(mobNow()); wait > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "  Next Rotation: in %s\n", wait.Round(time.Second))
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "  Next Rotation: due %s ago\n", (-wait).Round(time.Second))
+			}
+		}
+		return nil
+	},
+}
+
+var mobStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "End the mob session",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := mob.Stop(mobNow())
+		if err != nil {
+			return err
+		}
+
+		events, err := mob.Journal()
+		if err != nil {
+			return err
+		}
+		session := mob.SessionEvents(events)
+		rotations := 0
+		for _, e := range session {
+			if e.Kind == mob.EventRotate {
+				rotations++
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "\nEnded mob programming session!\n\n")
+		fmt.Fprintf(cmd.OutOrStdout(), "Session Summary:\n")
+		fmt.Fprintf(cmd.OutOrStdout(), "  Participants: %s\n", strings.Join(s.Participants, ", "))
+		fmt.Fprintf(cmd.OutOrStdout(), "  Duration: %s\n", mobNow().Sub(s.StartedAt).Round(time.Minute))
+		fmt.Fprintf(cmd.OutOrStdout(), "  Rotations: %d\n", rotations)
+		printCommitsByDriver(cmd.OutOrStdout(), session)
+		return nil
+	},
+}
+
+var mobJournalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Show the rotations and commits of mob sessions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+
+		events, err := mob.Journal()
+		if err != nil {
+			return err
+		}
+		if !all {
+			events = mob.SessionEvents(events)
+		}
+		if len(events) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No mob sessions journaled\n")
+			return nil
+		}
+
+		out := cmd.OutOrStdout()
+		for _, e := range events {
+			at := e.At.Format("2006-01-02 15:04")
+			switch e.Kind {
+			case mob.EventStart:
+				fmt.Fprintf(out, "%s  start   %s (%s drives)\n", at, strings.Join(e.Participants, ", "), e.Driver)
+			case mob.EventRotate:
+				fmt.Fprintf(out, "%s  rotate  %s drives\n", at, e.Driver)
+			case mob.EventCommit:
+				fmt.Fprintf(out, "%s  commit  %s driven by %s\n", at, shortHash(e.Commit), e.Driver)
+			case mob.EventStop:
+				fmt.Fprintf(out, "%s  stop\n", at)
+			}
+		}
+		printCommitsByDriver(out, events)
+		return nil
+	},
+}
+
+var mobTimerCmd = &cobra.Command{
+	Use:   "timer",
+	Short: "Ring the terminal bell when the driver is due to rotate",
+	Long: `Wait for the rotations of the running mob session and ring the terminal
+bell when one is due. With --auto the driver is rotated too. The timer runs
+until the session ends.
+
+Examples:
+  tracer mob timer
+  tracer mob timer --auto`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		auto, _ := cmd.Flags().GetBool("auto")
+		once, _ := cmd.Flags().GetBool("once")
+		out := cmd.OutOrStdout()
+
+		// The rotation notified last, so it is not notified again
+		var notified time.Time
+		for {
+			s, err := mob.Current()
+			if err != nil {
+				return err
+			}
+			if s == nil {
+				fmt.Fprintf(out, "The mob session has ended\n")
+				return nil
+			}
+			due := s.NextRotation()
+			if due.IsZero() {
+				return fmt.Errorf("the mob session has no rotation interval. Start it with --rotate, e.g. --rotate 10m")
+			}
+
+			if s.RotatedAt.Equal(notified) {
+				mobSleep(mobPollInterval)
+				continue
+			}
+			if wait := due.Sub(mobNow()); wait > 0 {
+				mobSleep(wait)
+				continue
+			}
+
+			fmt.Fprintf(out, "\aTime to rotate! %s hands the keyboard to %s\n", s.DriverName(), s.NextDriverName())
+			if auto {
+				s, err = mob.Next(mobNow())
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s is driving, %s is next\n", s.DriverName(), s.NextDriverName())
+			} else {
+				fmt.Fprintf(out, "Run 'tracer mob next' when the keyboard has changed hands\n")
+				notified = s.RotatedAt
+			}
+
+			if once {
+				return nil
+			}
+		}
+	},
+}
+
+// printMobSession prints the participants of a session and who is driving
+func printMobSession(out io.Writer, s *mob.Session) {
+	fmt.Fprintf(out, "  Driver: %s\n", s.DriverName())
+	fmt.Fprintf(out, "  Next Driver: %s\n", s.NextDriverName())
+	fmt.Fprintf(out, "  Participants: %s\n", strings.Join(s.Participants, ", "))
+	if s.Interval > 0 {
+		fmt.Fprintf(out, "  Rotation: every %s\n", s.Interval)
+	}
+}
+
+// printCommitsByDriver prints the journaled commits by who drove them
+func printCommitsByDriver(out io.Writer, events []mob.Event) {
+	commits, drivers := mob.CommitsByDriver(events)
+	if len(drivers) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nCommits by Driver:\n")
+	for _, driver := range drivers {
+		hashes := make([]string, 0, len(commits[driver]))
+		for _, hash := range commits[driver] {
+			hashes = append(hashes, shortHash(hash))
+		}
+		fmt.Fprintf(out, "  %s: %s\n", driver, strings.Join(hashes, ", "))
+	}
+}
+
+// journalMobCommit records a commit in the journal of the running mob session,
+// warning on out rather than failing the commit
+func journalMobCommit(hash string, out io.Writer) {
+	if err := mob.RecordCommit(hash, mobNow()); err != nil {
+		fmt.Fprintf(out, "tracer: failed to journal commit: %v\n", err)
+	}
+}
+
+func init() {
+	MobCmd.AddCommand(mobStartCmd)
+	MobCmd.AddCommand(mobNextCmd)
+	MobCmd.AddCommand(mobStatusCmd)
+	MobCmd.AddCommand(mobStopCmd)
+	MobCmd.AddCommand(mobJournalCmd)
+	MobCmd.AddCommand(mobTimerCmd)
+
+	mobStartCmd.Flags().Duration("rotate", 0, "Rotate the driver every interval, e.g. 10m")
+	mobJournalCmd.Flags().Bool("all", false, "Show every session, not only the last one")
+	mobTimerCmd.Flags().Bool("auto", false, "Rotate the driver when due, not only notify")
+	mobTimerCmd.Flags().Bool("once", false, "Stop after the next rotation")
+}
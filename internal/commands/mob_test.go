@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/mob"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMobCommands(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	origNow, origSleep := mobNow, mobSleep
+	defer func() { mobNow, mobSleep = origNow, origSleep }()
+	mobNow = func() time.Time { return now }
+	mobSleep = func(d time.Duration) { now = now.Add(d) }
+
+	mockGitClient.GetConfigFunc = func(key string) (string, error) {
+		switch key {
+		case CurrentProject:
+			return TestProjectName, nil
+		case ProjectUser:
+			return TestUserName, nil
+		}
+		return "", nil
+	}
+	var committed []string
+	mockGitClient.CommitFunc = func(message string) error {
+		committed = append(committed, message)
+		return nil
+	}
+	mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+		return "abc1234567", nil
+	}
+	mockGitClient.GetAuthorFunc = func() (string, error) {
+		return "John Doe", nil
+	}
+
+	repoDir, err := mockGitClient.GetGitRoot()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".tracer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", config.DefaultTeamFile), []byte(`
+members:
+  - handle: jane.doe
+    name: Jane Doe
+    email: jane@example.com
+  - handle: sam
+    name: Sam Smith
+    email: sam@example.com
+`), 0644))
+
+	current, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	require.NoError(t, current.Save())
+	require.NoError(t, story.SetCurrent(current))
+
+	run := func(source *cobra.Command, flags func(*cobra.Command), args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: source.Use, Args: source.Args, RunE: source.RunE}
+		if flags != nil {
+			flags(cmd)
+		}
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+	startFlags := func(cmd *cobra.Command) { cmd.Flags().Duration("rotate", 0, "") }
+	timerFlags := func(cmd *cobra.Command) {
+		cmd.Flags().Bool("auto", false, "")
+		cmd.Flags().Bool("once", false, "")
+	}
+
+	output, err := run(mobStatusCmd, nil)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No active mob programming session")
+
+	_, err = run(mobStartCmd, startFlags, "john.doe")
+	assert.Error(t, err)
+
+	output, err = run(mobStartCmd, startFlags, "john.doe", "jane.doe", "sam", "--rotate", "10m")
+	require.NoError(t, err)
+	assert.Contains(t, output, "  Driver: john.doe\n  Next Driver: jane.doe\n  Participants: john.doe, jane.doe, sam\n  Rotation: every 10m0s\n")
+
+	t.Run("commits credit the other participants", func(t *testing.T) {
+		cmd := newCommitCreateCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--type", "feat", "--message", "add login"})
+		require.NoError(t, cmd.Execute())
+
+		require.Len(t, committed, 1)
+		assert.Equal(t, "feat: add login\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: Sam Smith <sam@example.com>\n", committed[0])
+	})
+
+	t.Run("timer notifies when the rotation is due", func(t *testing.T) {
+		output, err := run(mobTimerCmd, timerFlags, "--once")
+		require.NoError(t, err)
+		assert.Equal(t, "\aTime to rotate! john.doe hands the keyboard to jane.doe\nRun 'tracer mob next' when the keyboard has changed hands\n", output)
+		assert.Equal(t, time.Date(2026, 3, 2, 9, 10, 0, 0, time.UTC), now)
+
+		output, err = run(mobStatusCmd, nil)
+		require.NoError(t, err)
+		assert.Contains(t, output, "  Driver: john.doe\n")
+		assert.Contains(t, output, "  Next Rotation: due 0s ago\n")
+	})
+
+	output, err = run(mobNextCmd, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "jane.doe is driving, sam is next\n", output)
+
+	t.Run("hook commits are journaled for the driver", func(t *testing.T) {
+		mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+			return "def4567890", nil
+		}
+		require.NoError(t, runPostCommitHook(&bytes.Buffer{}))
+	})
+
+	t.Run("timer rotates with --auto", func(t *testing.T) {
+		output, err := run(mobTimerCmd, timerFlags, "--auto", "--once")
+		require.NoError(t, err)
+		assert.Contains(t, output, "sam is driving, john.doe is next\n")
+	})
+
+	output, err = run(mobJournalCmd, func(cmd *cobra.Command) { cmd.Flags().Bool("all", false, "") })
+	require.NoError(t, err)
+	assert.Contains(t, output, "2026-03-02 09:00  start   john.doe, jane.doe, sam (john.doe drives)\n")
+	assert.Contains(t, output, "2026-03-02 09:10  commit  def4567 driven by jane.doe\n")
+	assert.Contains(t, output, "\nCommits by Driver:\n  john.doe: abc1234\n  jane.doe: def4567\n")
+
+	output, err = run(mobStopCmd, nil)
+	require.NoError(t, err)
+	assert.Contains(t, output, "  Rotations: 2\n")
+	assert.Contains(t, output, "  Duration: 20m0s\n")
+
+	s, err := mob.Current()
+	require.NoError(t, err)
+	assert.Nil(t, s)
+	assert.Empty(t, activePartners())
+
+	_, err = run(mobTimerCmd, timerFlags)
+	require.NoError(t, err)
+}
@@ -2,12 +2,24 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+	"github.com/helmedeiros/tracer-bullet/internal/mob"
+	"github.com/helmedeiros/tracer-bullet/internal/pair"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/team"
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"github.com/spf13/cobra"
 )
 
+// pairNow tells the time sessions start and end
+var pairNow = time.Now
+
 var PairCmd = &cobra.Command{
 	Use:   "pair",
 	Short: "Manage pair programming sessions",
@@ -22,6 +34,10 @@ var PairCmd = &cobra.Command{
 3. End Session
    tracer pair stop                 # End the current session
 
+4. Review
+   tracer pair history              # Past sessions, newest first
+   tracer pair stats                # Time paired, frequent and stale pairs
+
 Each command helps you maintain effective pair programming practices.
 Use these commands to:
 - Track who you're working with
@@ -46,6 +62,10 @@ Example:
 			return stopPair(cmd)
 		case "show":
 			return showPairStatus(cmd)
+		case "history":
+			return showPairHistory(cmd)
+		case "stats":
+			return showPairStats(cmd)
 		default:
 			return fmt.Errorf(`unknown command: %s
 
@@ -53,6 +73,8 @@ Available Commands:
   start <partner-name>  Start a pair programming session
   show                  Show current session status
   stop                  End the current session
+  history               List past sessions
+  stats                 Show pairing statistics
 
 Example:
   tracer pair start john.doe`, args[0])
@@ -96,6 +118,23 @@ Example:
 
 	// Get current user for better context
 	currentUser, _ := utils.GitClient.GetConfig(fmt.Sprintf("%s.user", projectName))
+
+	// Record the session in the pair history, ending any left running
+	storyID := ""
+	if s, err := story.GetCurrent(); err == nil && s != nil {
+		storyID = s.ID
+	}
+	var participants []string
+	if currentUser != "" {
+		participants = append(participants, currentUser)
+	}
+	if err := pair.Update(cfg.PairFile, func(history *pair.History) error {
+		history.Start(append(participants, partner), projectName, storyID, pairNow())
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	if currentUser == "" {
 		currentUser = "unknown user"
 	}
@@ -105,6 +144,13 @@ Example:
 	fmt.Fprintf(cmd.OutOrStdout(), "  Project: %s\n", projectName)
 	fmt.Fprintf(cmd.OutOrStdout(), "  Current User: %s\n", currentUser)
 	fmt.Fprintf(cmd.OutOrStdout(), "  Pair Partner: %s\n", partner)
+	if roster, err := team.LoadRoster(cfg.TeamFile); err == nil && roster.Path != "" {
+		if member, ok := roster.Find(partner); ok {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Co-author: %s\n", member)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nAdd %s to the team roster (%s) with a name and email to credit them as co-author.\n", partner, cfg.TeamFile)
+		}
+	}
 	fmt.Fprintf(cmd.OutOrStdout(), "\nNext steps:\n")
 	fmt.Fprintf(cmd.OutOrStdout(), "1. Create a new story with 'tracer story new'\n")
 	fmt.Fprintf(cmd.OutOrStdout(), "2. Make changes together\n")
@@ -137,11 +183,24 @@ func stopPair(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
+	// End the session in the pair history
+	var session *pair.Session
+	if err := pair.Update(cfg.PairFile, func(history *pair.History) error {
+		session = history.End(pairNow())
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	fmt.Fprintf(cmd.OutOrStdout(), "\nEnded pair programming session!\n\n")
 	if pairName != "" {
 		fmt.Fprintf(cmd.OutOrStdout(), "Session Summary:\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "  Project: %s\n", projectName)
 		fmt.Fprintf(cmd.OutOrStdout(), "  Participants: %s and %s\n", currentUser, pairName)
+		if session != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Duration: %s\n", session.Duration(pairNow()).Round(time.Minute))
+			fmt.Fprintf(cmd.OutOrStdout(), "  Commits: %d\n", len(session.Commits))
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "\nThanks for pairing! 👥\n")
 	}
 
@@ -183,11 +242,10 @@ func showPairStatus(cmd *cobra.Command) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "  Pair Partner: %s\n", pairName)
 
 	// If there's a story associated with the pair, show it
-	if cfg.JiraHost != "" && cfg.JiraProject != "" {
-		storyID, err := utils.GitClient.GetConfig(fmt.Sprintf("%s.current.story", cfg.JiraProject))
-		if err == nil && storyID != "" {
-			fmt.Fprintf(cmd.OutOrStdout(), "  Current Story: %s\n", storyID)
-			fmt.Fprintf(cmd.OutOrStdout(), "  Story URL: %s/browse/%s\n", cfg.JiraHost, storyID)
+	if s, err := story.GetCurrent(); err == nil && s != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "  Current Story: %s (%s)\n", s.ID, s.Title)
+		if cfg.JiraHost != "" && s.JiraKey != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Story URL: %s/browse/%s\n", cfg.JiraHost, s.JiraKey)
 		}
 	}
 
@@ -198,3 +256,212 @@ func showPairStatus(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// showPairHistory lists the recorded sessions, newest first
+func showPairHistory(cmd *cobra.Command) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	history, err := pair.Load(cfg.PairFile)
+	if err != nil {
+		return err
+	}
+	if len(history.Sessions) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No pair programming sessions recorded\n")
+		return nil
+	}
+
+	now := pairNow()
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tDURATION\tPARTICIPANTS\tSTORY\tCOMMITS")
+	for i, shown := len(history.Sessions)-1, 0; i >= 0 && (limit <= 0 || shown < limit); i, shown = i-1, shown+1 {
+		s := history.Sessions[i]
+		duration := s.Duration(now).Round(time.Minute).String()
+		if s.Active() {
+			duration += " (active)"
+		}
+		storyID := s.Story
+		if storyID == "" {
+			storyID = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", s.StartedAt.Local().Format("2006-01-02 15:04"), duration,
+			strings.Join(s.Participants, " & "), storyID, len(s.Commits))
+	}
+	return w.Flush()
+}
+
+// showPairStats reports the time paired per person, the most frequent pairs
+// and the pairs that have not paired recently
+func showPairStats(cmd *cobra.Command) error {
+	staleDays, _ := cmd.Flags().GetInt("stale-days")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	history, err := pair.Load(cfg.PairFile)
+	if err != nil {
+		return err
+	}
+	if len(history.Sessions) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No pair programming sessions recorded\n")
+		return nil
+	}
+
+	now := pairNow()
+	stats := history.Stats(now)
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "Time Paired:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, p := range stats.People {
+		fmt.Fprintf(w, "  %s\t%s\t%d session(s)\n", p.Name, p.Time.Round(time.Minute), p.Sessions)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nMost Frequent Pairs:\n")
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, p := range stats.Pairs {
+		fmt.Fprintf(w, "  %s\t%d session(s)\t%s\tlast %s\n", p.Pair, p.Sessions, p.Time.Round(time.Minute), p.Last.Local().Format("2006-01-02"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Everyone in the history and the team roster can pair
+	people := stats.Names()
+	if roster, err := team.LoadRoster(cfg.TeamFile); err == nil {
+		for _, m := range roster.Members {
+			if m.Handle != "" && !containsFold(people, m.Handle) {
+				people = append(people, m.Handle)
+			}
+		}
+	}
+	stale := stats.Stale(people, now.AddDate(0, 0, -staleDays))
+	fmt.Fprintf(out, "\nNot Paired in the Last %d Days:\n", staleDays)
+	if len(stale) == 0 {
+		fmt.Fprintf(out, "  Everyone has paired recently\n")
+		return nil
+	}
+	for _, p := range stale {
+		if p.Last.IsZero() {
+			fmt.Fprintf(out, "  %s: never\n", p.Pair)
+		} else {
+			fmt.Fprintf(out, "  %s: last %s\n", p.Pair, p.Last.Local().Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// containsFold reports whether the names contain the name, compared case-insensitively
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordSessionCommit records a commit in the running pair and mob sessions,
+// warning on out rather than failing the commit
+func recordSessionCommit(hash string, out io.Writer) {
+	journalMobCommit(hash, out)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return
+	}
+	err = pair.Update(cfg.PairFile, func(history *pair.History) error {
+		history.RecordCommit(hash)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(out, "tracer: failed to record commit in the pair history: %v\n", err)
+	}
+}
+
+// activePartners returns the names of the teammates in the active pair
+// session, or the other participants of the running mob session
+func activePartners() []string {
+	if s, err := mob.Current(); err == nil && s != nil {
+		project, _ := utils.GitClient.GetConfig("current.project")
+		user, _ := utils.GitClient.GetConfig(strings.TrimSpace(project) + ".user")
+		var partners []string
+		for _, p := range s.Participants {
+			if !strings.EqualFold(p, strings.TrimSpace(user)) {
+				partners = append(partners, p)
+			}
+		}
+		return partners
+	}
+
+	partner, err := utils.GitClient.GetConfig("current.pair")
+	partner = strings.TrimSpace(partner)
+	if err != nil || partner == "" {
+		return nil
+	}
+	return []string{partner}
+}
+
+// pairCoAuthors returns the teammates of the active pair session, resolved
+// through the team roster. Partners the roster does not know are reported on
+// out and left out, rather than failing the commit.
+func pairCoAuthors(out io.Writer) ([]team.Member, error) {
+	partners := activePartners()
+	if len(partners) == 0 {
+		return nil, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	roster, err := team.LoadRoster(cfg.TeamFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []team.Member
+	for _, partner := range partners {
+		member, ok := roster.Find(partner)
+		if !ok {
+			fmt.Fprintf(out, "tracer: %s is not in the team roster (%s) with a name and email, not crediting them as co-author\n", partner, cfg.TeamFile)
+			continue
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// missingCoAuthorTrailers returns the co-author trailers of the members that
+// the commit message does not credit yet
+func missingCoAuthorTrailers(message string, members []team.Member) []string {
+	cleaned := strings.ToLower(conventional.Clean(message))
+	var trailers []string
+	for _, m := range members {
+		if !strings.Contains(cleaned, "<"+strings.ToLower(m.Email)+">") {
+			trailers = append(trailers, m.Trailer())
+		}
+	}
+	return trailers
+}
+
+// addCoAuthors credits the teammates of the active pair session in the commit message
+func addCoAuthors(commitMsg string, out io.Writer) (string, error) {
+	members, err := pairCoAuthors(out)
+	if err != nil {
+		return commitMsg, err
+	}
+	return appendTrailers(commitMsg, missingCoAuthorTrailers(commitMsg, members)...), nil
+}
+
+func init() {
+	PairCmd.Flags().Int("limit", 20, "Number of sessions to show with history, all when 0")
+	PairCmd.Flags().Int("stale-days", 14, "Days without pairing after which stats report a pair as stale")
+}
@@ -2,8 +2,14 @@ package commands
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -76,7 +82,7 @@ func TestPairCommand(t *testing.T) {
 			name:       "invalid command",
 			args:       []string{"invalid"},
 			wantErr:    true,
-			errMessage: "unknown command: invalid\n\nAvailable Commands:\n  start <partner-name>  Start a pair programming session\n  show                  Show current session status\n  stop                  End the current session\n\nExample:\n  tracer pair start john.doe",
+			errMessage: "unknown command: invalid\n\nAvailable Commands:\n  start <partner-name>  Start a pair programming session\n  show                  Show current session status\n  stop                  End the current session\n  history               List past sessions\n  stats                 Show pairing statistics\n\nExample:\n  tracer pair start john.doe",
 		},
 	}
 
@@ -98,3 +104,152 @@ func TestPairCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestPairCoAuthors(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	partner := "jane.doe"
+	mockGitClient.GetConfigFunc = func(key string) (string, error) {
+		switch key {
+		case CurrentProject:
+			return TestProjectName, nil
+		case ProjectUser:
+			return TestUserName, nil
+		case "current.pair":
+			return partner, nil
+		}
+		return "", nil
+	}
+	var committed []string
+	mockGitClient.CommitFunc = func(message string) error {
+		committed = append(committed, message)
+		return nil
+	}
+	mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+		return "abc123", nil
+	}
+	mockGitClient.GetAuthorFunc = func() (string, error) {
+		return "John Doe", nil
+	}
+
+	repoDir, err := mockGitClient.GetGitRoot()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".tracer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", config.DefaultTeamFile), []byte(`
+members:
+  - handle: jane.doe
+    name: Jane Doe
+    email: jane@example.com
+`), 0644))
+
+	current, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	require.NoError(t, current.Save())
+	require.NoError(t, story.SetCurrent(current))
+
+	t.Run("commit create credits the partner", func(t *testing.T) {
+		cmd := newCommitCreateCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--type", "feat", "--message", "add login", "--breaking"})
+		require.NoError(t, cmd.Execute())
+
+		require.Len(t, committed, 1)
+		assert.Equal(t, "feat!: add login\n\nBREAKING CHANGE: add login\nCo-authored-by: Jane Doe <jane@example.com>\n", committed[0])
+
+		loaded, err := story.LoadStory(current.Filename)
+		require.NoError(t, err)
+		require.Len(t, loaded.Commits, 1)
+		assert.Equal(t, "John Doe", loaded.Commits[0].Author)
+		assert.Equal(t, []string{"Jane Doe <jane@example.com>"}, loaded.Commits[0].CoAuthors)
+	})
+
+	t.Run("prepare-commit-msg credits the partner once", func(t *testing.T) {
+		file := filepath.Join(tmpDir, "COMMIT_EDITMSG")
+		require.NoError(t, os.WriteFile(file, []byte("feat: add login\n"), 0644))
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, runPrepareCommitMsgHook(file, "message", &bytes.Buffer{}))
+		}
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: add login\n\nStory: test-project-7\nCo-authored-by: Jane Doe <jane@example.com>\n", string(data))
+	})
+
+	t.Run("partners missing from the roster are not credited", func(t *testing.T) {
+		partner = "sam"
+		var stderr bytes.Buffer
+		message, err := addCoAuthors("feat: add login", &stderr)
+		require.NoError(t, err)
+		assert.Equal(t, "feat: add login", message)
+		assert.Contains(t, stderr.String(), "sam is not in the team roster")
+	})
+
+	t.Run("no pair session", func(t *testing.T) {
+		partner = ""
+		message, err := addCoAuthors("feat: add login", &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, "feat: add login", message)
+	})
+}
+
+func TestPairHistory(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	origNow := pairNow
+	defer func() { pairNow = origNow }()
+	pairNow = func() time.Time { return now }
+
+	gitConfig := map[string]string{CurrentProject: TestProjectName, ProjectUser: TestUserName}
+	mockGitClient.GetConfigFunc = func(key string) (string, error) {
+		return gitConfig[key], nil
+	}
+	mockGitClient.SetConfigFunc = func(key, value string) error {
+		gitConfig[key] = value
+		return nil
+	}
+
+	repoDir, err := mockGitClient.GetGitRoot()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".tracer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", config.DefaultTeamFile), []byte(`
+members:
+  - handle: jane.doe
+  - handle: sam
+`), 0644))
+
+	run := func(args ...string) string {
+		var out bytes.Buffer
+		PairCmd.SetOut(&out)
+		require.NoError(t, PairCmd.RunE(PairCmd, args))
+		return out.String()
+	}
+
+	output := run("history")
+	assert.Equal(t, "No pair programming sessions recorded\n", output)
+
+	run("start", "jane.doe")
+	recordSessionCommit("abc123", &bytes.Buffer{})
+	recordSessionCommit("abc123", &bytes.Buffer{})
+	now = now.Add(90 * time.Minute)
+	output = run("stop")
+	assert.Contains(t, output, "  Duration: 1h30m0s\n  Commits: 1\n")
+
+	now = now.AddDate(0, 0, 1)
+	run("start", "sam")
+	now = now.Add(30 * time.Minute)
+
+	output = run("history")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^2026-03-03 10:30\s+30m0s \(active\)\s+john\.doe & sam\s+-\s+0$`, lines[1])
+	assert.Regexp(t, `^2026-03-02 09:00\s+1h30m0s\s+john\.doe & jane\.doe\s+-\s+1$`, lines[2])
+
+	output = run("stats")
+	assert.Regexp(t, `Time Paired:\n  john\.doe\s+2h0m0s\s+2 session\(s\)\n  jane\.doe\s+1h30m0s\s+1 session\(s\)\n  sam\s+30m0s\s+1 session\(s\)\n`, output)
+	assert.Regexp(t, `Most Frequent Pairs:\n  jane\.doe & john\.doe\s+1 session\(s\)\s+1h30m0s\s+last 2026-03-02\n  john\.doe & sam\s+1 session\(s\)\s+30m0s\s+last 2026-03-03\n`, output)
+	assert.Contains(t, output, "Not Paired in the Last 14 Days:\n  jane.doe & sam: never\n")
+}
@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+	"github.com/helmedeiros/tracer-bullet/internal/jira"
+	"github.com/helmedeiros/tracer-bullet/internal/release"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// issueUpdater changes the status of Jira issues
+type issueUpdater interface {
+	UpdateIssue(issueID, status, assignee string) error
+}
+
+// newIssueUpdater connects to Jira to transition the issues of released stories
+var newIssueUpdater = func(cfg *config.Config) (issueUpdater, error) {
+	client, err := jira.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// channelPattern matches a pre-release channel such as rc or beta
+var channelPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+var ReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Compute the next version and tag releases",
+	Long: `Compute the next semantic version from the conventional commits made since
+the last release, and tag it:
+
+  BREAKING CHANGE or !   major  1.2.3 -> 2.0.0
+  feat                   minor  1.2.3 -> 1.3.0
+  fix                    patch  1.2.3 -> 1.2.4
+
+Pre-release channels number their versions: 1.3.0-rc.1, 1.3.0-rc.2 and so on.
+
+Examples:
+  tracer release next
+  tracer release next --pre rc
+  tracer release cut --jira-status Done`,
+}
+
+// releasePlan is the next release computed from the commit history
+type releasePlan struct {
+	Current  string         `json:"current,omitempty"` // Tag of the latest release, empty before the first one
+	Next     string         `json:"next,omitempty"`    // Empty when there is nothing to release
+	Bump     string         `json:"bump"`
+	Commits  int            `json:"commits"`
+	Breaking int            `json:"breaking"`
+	Features int            `json:"features"`
+	Fixes    int            `json:"fixes"`
+	Stories  []*story.Story `json:"-"`
+	Changes  []string       `json:"changes"` // Headers of the commits, oldest first
+
+	storyKeys []string
+}
+
+// planRelease computes the next release from the commits since the latest
+// release tag reachable from HEAD. A bump or channel given overrides the one
+// the commits call for.
+func planRelease(bumpName, channel string) (*releasePlan, error) {
+	if channel != "" && !channelPattern.MatchString(channel) {
+		return nil, fmt.Errorf("invalid pre-release channel: %s", channel)
+	}
+
+	merged, err := utils.GitClient.Tags("--merged", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	current, found := release.LatestRelease(release.Versions(merged))
+	plan := &releasePlan{}
+	revision := "HEAD"
+	if found {
+		for _, tag := range merged {
+			if v, err := release.ParseVersion(tag); err == nil && !v.IsPrerelease() && v.Compare(current) == 0 {
+				plan.Current = tag
+				break
+			}
+		}
+		revision = plan.Current + "..HEAD"
+	} else {
+		current = release.Version{Prefix: release.DefaultPrefix}
+	}
+
+	entries, err := utils.GitClient.Log("--no-merges", revision)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := story.ListStories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+	index := story.NewCommitIndex(stories)
+
+	bump := release.BumpNone
+	// Oldest first, the order the commits were made in
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		plan.Commits++
+		b := release.BumpOf(entry.Message)
+		switch b {
+		case release.BumpMajor:
+			plan.Breaking++
+		case release.BumpMinor:
+			plan.Features++
+		case release.BumpPatch:
+			plan.Fixes++
+		}
+		bump = max(bump, b)
+		plan.Changes = append(plan.Changes, fmt.Sprintf("%s (%s)", conventional.Header(entry.Message), shortHash(entry.Hash)))
+
+		if s := index.StoryOf(entry.Hash, entry.Message); s != nil && !slices.Contains(plan.storyKeys, s.ID) {
+			plan.storyKeys = append(plan.storyKeys, s.ID)
+			plan.Stories = append(plan.Stories, s)
+		}
+	}
+
+	if bumpName != "" {
+		if bump, err = release.ParseBump(bumpName); err != nil {
+			return nil, err
+		}
+	}
+	plan.Bump = bump.String()
+	if bump == release.BumpNone {
+		return plan, nil
+	}
+
+	all, err := utils.GitClient.Tags()
+	if err != nil {
+		return nil, err
+	}
+	plan.Next = release.Next(current, bump, channel, release.Versions(all)).String()
+	return plan, nil
+}
+
+// since describes what the plan counts commits since
+func (p *releasePlan) since() string {
+	if p.Current == "" {
+		return "the first commit"
+	}
+	return p.Current
+}
+
+// tagMessage returns the message of the release tag: the stories and the
+// commits it includes
+func (p *releasePlan) tagMessage(version string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release %s\n", version)
+	if len(p.Stories) > 0 {
+		b.WriteString("\nStories:\n")
+		for _, s := range p.Stories {
+			fmt.Fprintf(&b, "- %s %s\n", s.Key(), s.Title)
+		}
+	}
+	if len(p.Changes) > 0 {
+		b.WriteString("\nChanges:\n")
+		for _, change := range p.Changes {
+			fmt.Fprintf(&b, "- %s\n", change)
+		}
+	}
+	return b.String()
+}
+
+var releaseNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Show the next version from the commits since the last release",
+	Long: `Show the next semantic version from the conventional commits made since the
+latest release tag reachable from HEAD.
+
+Examples:
+  tracer release next
+  tracer release next --pre beta
+  tracer release next --short`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bump, _ := cmd.Flags().GetString("bump")
+		channel, _ := cmd.Flags().GetString("pre")
+		short, _ := cmd.Flags().GetBool("short")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		plan, err := planRelease(bump, channel)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch {
+		case asJSON:
+			data, err := json.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal release: %w", err)
+			}
+			fmt.Fprintln(out, string(data))
+		case short:
+			if plan.Next != "" {
+				fmt.Fprintln(out, plan.Next)
+			}
+		default:
+			current := plan.Current
+			if current == "" {
+				current = "none"
+			}
+			fmt.Fprintf(out, "Current version: %s\n", current)
+			fmt.Fprintf(out, "Commits since %s: %d (%d breaking, %d features, %d fixes)\n",
+				plan.since(), plan.Commits, plan.Breaking, plan.Features, plan.Fixes)
+			if plan.Next == "" {
+				fmt.Fprintf(out, "No releasable changes. Use --bump to release anyway\n")
+			} else {
+				fmt.Fprintf(out, "Next version: %s (%s)\n", plan.Next, plan.Bump)
+			}
+		}
+		return nil
+	},
+}
+
+var releaseCutCmd = &cobra.Command{
+	Use:   "cut",
+	Short: "Tag the next release",
+	Long: `Create an annotated tag for the next release on HEAD. The tag message lists
+the stories and commits the release includes. With --jira-status, the Jira
+issues of the included stories are transitioned, for example to Done.
+
+Examples:
+  tracer release cut
+  tracer release cut --pre rc
+  tracer release cut --version v2.0.0 --jira-status Done
+  tracer release cut --dry-run`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bump, _ := cmd.Flags().GetString("bump")
+		channel, _ := cmd.Flags().GetString("pre")
+		version, _ := cmd.Flags().GetString("version")
+		jiraStatus, _ := cmd.Flags().GetString("jira-status")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		plan, err := planRelease(bump, channel)
+		if err != nil {
+			return err
+		}
+		if version != "" {
+			if _, err := release.ParseVersion(version); err != nil {
+				return err
+			}
+			plan.Next = version
+		}
+		if plan.Next == "" {
+			return fmt.Errorf("no releasable changes since %s. Use --bump or --version to release anyway", plan.since())
+		}
+
+		existing, err := utils.GitClient.Tags(plan.Next)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return fmt.Errorf("tag %s already exists", plan.Next)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		out := cmd.OutOrStdout()
+		message := plan.tagMessage(plan.Next)
+		if dryRun {
+			fmt.Fprintf(out, "Would create tag %s with the message:\n\n%s", plan.Next, message)
+			return nil
+		}
+
+		if err := utils.GitClient.CreateTag(plan.Next, message); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Created tag %s with %s and %s\n", plan.Next, plural(plan.Commits, "commit", "commits"), plural(len(plan.Stories), "story", "stories"))
+
+		if jiraStatus != "" {
+			transitionReleasedIssues(cmd, cfg, plan.Stories, jiraStatus)
+		}
+
+		fmt.Fprintf(out, "\nPublish it with:\n  git push %s %s\n", cfg.GitRemote, plan.Next)
+		return nil
+	},
+}
+
+// transitionReleasedIssues moves the Jira issues of the released stories to
+// the status. Failures are reported without failing the release, which is
+// already tagged.
+func transitionReleasedIssues(cmd *cobra.Command, cfg *config.Config, stories []*story.Story, status string) {
+	var keys []string
+	for _, s := range stories {
+		if s.JiraKey != "" {
+			keys = append(keys, s.JiraKey)
+		}
+	}
+	if len(keys) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No released story is linked to a Jira issue\n")
+		return
+	}
+
+	client, err := newIssueUpdater(cfg)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "tracer: failed to create Jira client, issues not transitioned: %v\n", err)
+		return
+	}
+	for _, key := range keys {
+		if err := client.UpdateIssue(key, status, ""); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "tracer: failed to move %s to %s: %v\n", key, status, err)
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Moved %s to %s\n", key, status)
+	}
+}
+
+// plural returns the count followed by the singular or the plural noun
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+func init() {
+	ReleaseCmd.AddCommand(releaseNextCmd)
+	ReleaseCmd.AddCommand(releaseCutCmd)
+
+	for _, cmd := range []*cobra.Command{releaseNextCmd, releaseCutCmd} {
+		cmd.Flags().String("bump", "", "Bump patch, minor or major instead of what the commits call for")
+		cmd.Flags().String("pre", "", "Pre-release channel, such as rc or beta")
+	}
+	releaseNextCmd.Flags().Bool("short", false, "Print only the next version")
+	releaseNextCmd.Flags().Bool("json", false, "Print the release plan as JSON")
+	releaseCutCmd.Flags().String("version", "", "Tag this version instead of the computed one")
+	releaseCutCmd.Flags().String("jira-status", "", "Transition the Jira issues of the released stories to this status")
+	releaseCutCmd.Flags().Bool("dry-run", false, "Show the tag without creating it")
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssueUpdater records the issues it is asked to transition
+type fakeIssueUpdater struct {
+	updated map[string]string
+	fail    string
+}
+
+func (f *fakeIssueUpdater) UpdateIssue(issueID, status, assignee string) error {
+	if issueID == f.fail {
+		return errors.New("transition not allowed")
+	}
+	f.updated[issueID] = status
+	return nil
+}
+
+func TestReleaseCommands(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	linked, err := story.NewStoryWithNumber("Login page", "Description", "john.doe", 7)
+	require.NoError(t, err)
+	linked.JiraKey = "PROJ-7"
+	require.NoError(t, linked.Save())
+
+	var logArgs []string
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		logArgs = args
+		return []utils.LogEntry{
+			{Hash: "ccc3333333", Message: "docs: update readme"},
+			{Hash: "bbb2222222", Message: "fix: handle timeouts"},
+			{Hash: "aaa1111111", Message: "feat(auth): add login\n\nStory: PROJ-7"},
+		}, nil
+	}
+	tags := []string{"v1.2.0", "v1.1.0", "nightly"}
+	mockGitClient.TagsFunc = func(args ...string) ([]string, error) {
+		if len(args) == 1 {
+			for _, tag := range tags {
+				if tag == args[0] {
+					return []string{tag}, nil
+				}
+			}
+			return nil, nil
+		}
+		return tags, nil
+	}
+	var tagName, tagMessage string
+	mockGitClient.CreateTagFunc = func(name, message string) error {
+		tagName, tagMessage = name, message
+		return nil
+	}
+
+	origUpdater := newIssueUpdater
+	defer func() { newIssueUpdater = origUpdater }()
+	updater := &fakeIssueUpdater{updated: map[string]string{}}
+	newIssueUpdater = func(cfg *config.Config) (issueUpdater, error) { return updater, nil }
+
+	run := func(command *cobra.Command, args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := &cobra.Command{Use: command.Use, RunE: command.RunE, SilenceUsage: true, SilenceErrors: true}
+		cmd.Flags().String("bump", "", "")
+		cmd.Flags().String("pre", "", "")
+		cmd.Flags().Bool("short", false, "")
+		cmd.Flags().Bool("json", false, "")
+		cmd.Flags().String("version", "", "")
+		cmd.Flags().String("jira-status", "", "")
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	output, err := run(releaseNextCmd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--no-merges", "v1.2.0..HEAD"}, logArgs)
+	assert.Equal(t, "Current version: v1.2.0\nCommits since v1.2.0: 3 (0 breaking, 1 features, 1 fixes)\nNext version: v1.3.0 (minor)\n", output)
+
+	output, err = run(releaseNextCmd, "--pre", "rc", "--short")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0-rc.1\n", output)
+
+	output, err = run(releaseNextCmd, "--bump", "major", "--short")
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0\n", output)
+
+	_, err = run(releaseNextCmd, "--pre", "rc.1")
+	assert.ErrorContains(t, err, "invalid pre-release channel")
+
+	output, err = run(releaseCutCmd, "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would create tag v1.3.0")
+	assert.Empty(t, tagName)
+
+	output, err = run(releaseCutCmd, "--jira-status", "Done")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", tagName)
+	assert.Equal(t, `Release v1.3.0
+
+Stories:
+- PROJ-7 Login page
+
+Changes:
+- feat(auth): add login (aaa1111)
+- fix: handle timeouts (bbb2222)
+- docs: update readme (ccc3333)
+`, tagMessage)
+	assert.Contains(t, output, "Created tag v1.3.0 with 3 commits and 1 story\n")
+	assert.Contains(t, output, "Moved PROJ-7 to Done\n")
+	assert.Equal(t, map[string]string{"PROJ-7": "Done"}, updater.updated)
+
+	// A failed transition does not undo the tag
+	updater.fail = "PROJ-7"
+	output, err = run(releaseCutCmd, "--version", "v1.3.1", "--jira-status", "Done")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.1", tagName)
+	assert.Contains(t, output, "failed to move PROJ-7 to Done")
+
+	_, err = run(releaseCutCmd, "--version", "v1.2.0")
+	assert.ErrorContains(t, err, "tag v1.2.0 already exists")
+
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		return []utils.LogEntry{{Hash: "ddd4444444", Message: "chore: bump deps"}}, nil
+	}
+	_, err = run(releaseCutCmd)
+	assert.ErrorContains(t, err, "no releasable changes since v1.2.0")
+}
+
+func TestPlural(t *testing.T) {
+	assert.Equal(t, "0 stories", plural(0, "story", "stories"))
+	assert.Equal(t, "1 story", plural(1, "story", "stories"))
+	assert.Equal(t, "2 commits", plural(2, "commit", "commits"))
+}
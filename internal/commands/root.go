@@ -20,13 +20,20 @@ var RootCmd = &cobra.Command{
 2. Work: Create and track stories, manage commits
    tracer story        # Manage development stories
    tracer commit       # Create and manage commits
+   tracer hooks        # Track plain git commits and checkouts
+   tracer changelog    # Generate release notes from the commits
+   tracer release      # Compute the next version and tag releases
 
-3. Collaborate: Handle pair programming sessions
+3. Collaborate: Handle pair and mob programming sessions
    tracer pair         # Manage pair programming
+   tracer mob          # Manage mob programming and driver rotation
 
 4. Integrate: Connect with external tools
    tracer jira         # Jira integration
 
+5. Maintain: Keep tracer data up to date
+   tracer migrate      # Upgrade stories and configuration files
+
 Each command follows a natural workflow, making it easy to:
 - Start new projects
 - Track your work
@@ -40,8 +47,13 @@ func init() {
 	RootCmd.AddCommand(ConfigureCmd)
 	RootCmd.AddCommand(StoryCmd)
 	RootCmd.AddCommand(CommitCmd)
+	RootCmd.AddCommand(HooksCmd)
+	RootCmd.AddCommand(ChangelogCmd)
+	RootCmd.AddCommand(ReleaseCmd)
 	RootCmd.AddCommand(PairCmd)
+	RootCmd.AddCommand(MobCmd)
 	RootCmd.AddCommand(JiraCmd)
+	RootCmd.AddCommand(MigrateCmd)
 }
 
 // Execute runs the root command
@@ -1,12 +1,21 @@
 package commands
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/helmedeiros/tracer-bullet/internal/config"
 	"github.com/helmedeiros/tracer-bullet/internal/story"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var StoryCmd = &cobra.Command{
@@ -16,38 +25,69 @@ var StoryCmd = &cobra.Command{
 
 1. Create Stories
    tracer story new --title "Feature X" --description "Implement feature X"
-
-2. Track Progress
-   tracer story status --id <story-id>
+   tracer story edit --id <story-id> --title "Feature X, revised"
+   tracer story switch <story-id|number>
+   tracer story current
+
+2. Browse Stories
+   tracer story list --status in-progress
+   tracer story show --id <story-id>
+
+3. Track Progress
+   tracer story start --id <story-id>
+   tracer story block --id <story-id> --reason "Waiting on API"
+   tracer story review --id <story-id>
+   tracer story done --id <story-id>
    tracer story files --id <story-id>
    tracer story commits --id <story-id>
 
-3. View History
+4. View History
    tracer story diary --id <story-id>
    tracer story diff --id <story-id>
 
-4. Search and Filter
+5. Search and Filter
    tracer story by --author <author>
    tracer story after-hash --hash <commit-hash>
 
 Each command builds on the previous ones, helping you maintain a clear development diary.`,
 }
 
+// newStoryNumber picks the number for a new story: the one given with --number,
+// the one taken from the Jira key, or 0 to take the next free one on save
+func newStoryNumber(cmd *cobra.Command, number int, jiraKey string) (int, error) {
+	switch {
+	case cmd.Flags().Changed("number"):
+		if number <= 0 {
+			return 0, fmt.Errorf("story number must be greater than 0")
+		}
+	case jiraKey != "":
+		n, err := story.NumberFromJiraKey(jiraKey)
+		if err != nil {
+			return 0, err
+		}
+		number = n
+	default:
+		return 0, nil
+	}
+	return number, nil
+}
+
 var storyNewCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Create a new story",
 	Long: `Create a new story with title, description, and other metadata.
 
-Example:
-  tracer story new --title "Add user authentication" --description "Implement OAuth2" --number 123 --tags "auth,security"
+Examples:
+  tracer story new --title "Add user authentication" --description "Implement OAuth2" --tags "auth,security"
+  tracer story new --title "Add user authentication" --number 123
+  tracer story new --title "Add user authentication" --jira PROJ-123
 
-Required Flags:
-  --number    Story number (must be > 0)
-
-Optional Flags:
+Flags:
   --title       Story title
   --description Story description
-  --tags        Comma-separated list of tags`,
+  --tags        Comma-separated list of tags
+  --number      Story number (must be > 0 and unused). Defaults to the next free number
+  --jira        Jira key to link the story to. Its number is used when --number is not given`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get current user from config
 		cfg, err := config.LoadConfig()
@@ -72,30 +112,37 @@ Optional Flags:
 		description, _ := cmd.Flags().GetString("description")
 		tags, _ := cmd.Flags().GetStringSlice("tags")
 		number, _ := cmd.Flags().GetInt("number")
+		jiraKey, _ := cmd.Flags().GetString("jira")
 
-		// Create new story with better validation
-		var s *story.Story
-		if !cmd.Flags().Changed("number") {
-			return fmt.Errorf("story number is required. Use --number flag to specify a unique story number")
-		}
-		if number <= 0 {
-			return fmt.Errorf("story number must be greater than 0")
+		// Pick the requested story number, if any
+		number, err = newStoryNumber(cmd, number, jiraKey)
+		if err != nil {
+			return err
 		}
-		s, err = story.NewStoryWithNumber(title, description, cfg.AuthorName, number)
+
+		// Create new story with better validation
+		s, err := story.NewDraft(title, description, cfg.AuthorName)
 		if err != nil {
 			return fmt.Errorf("failed to create story: %w", err)
 		}
+		s.Number = number
+		s.JiraKey = jiraKey
 
 		// Set tags if provided
 		if len(tags) > 0 {
 			s.Tags = tags
 		}
 
-		// Save story
-		if err := s.Save(); err != nil {
+		// Save story, numbering it under the stories lock
+		if err := story.SaveNew(s); err != nil {
 			return fmt.Errorf("failed to save story: %w", err)
 		}
 
+		// The new story becomes the current one
+		if err := story.SetCurrent(s); err != nil {
+			return fmt.Errorf("failed to set current story: %w", err)
+		}
+
 		// Write output to stdout with better formatting
 		fmt.Fprintf(cmd.OutOrStdout(), "\nCreated new story successfully!\n\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "Story Details:\n")
@@ -110,6 +157,9 @@ Optional Flags:
 		if len(s.Tags) > 0 {
 			fmt.Fprintf(cmd.OutOrStdout(), "  Tags: %v\n", s.Tags)
 		}
+		if s.JiraKey != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Jira: %s\n", s.JiraKey)
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "  Author: %s\n", s.Author)
 		fmt.Fprintf(cmd.OutOrStdout(), "  Status: %s\n", s.Status)
 		fmt.Fprintf(cmd.OutOrStdout(), "\nNext steps:\n")
@@ -121,42 +171,691 @@ Optional Flags:
 	},
 }
 
-var storyAfterHashCmd = &cobra.Command{
-	Use:   "after-hash",
-	Short: "Show stories after a specific commit hash",
-	Long:  `Display stories that have been modified after a specific commit hash.`,
+// parseTimeFlag parses a time given either in RFC3339 format or as a date (YYYY-MM-DD)
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q. Use RFC3339 (2006-01-02T15:04:05Z07:00) or a date (2006-01-02)", value)
+	}
+	return t, nil
+}
+
+// storyQueryFromFlags builds a story query from the list command flags
+func storyQueryFromFlags(cmd *cobra.Command) (story.Query, error) {
+	var q story.Query
+	q.Statuses, _ = cmd.Flags().GetStringSlice("status")
+	q.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	q.Author, _ = cmd.Flags().GetString("author")
+	q.JiraKey, _ = cmd.Flags().GetString("jira")
+	q.SortBy, _ = cmd.Flags().GetString("sort")
+	q.Reverse, _ = cmd.Flags().GetBool("reverse")
+	q.Limit, _ = cmd.Flags().GetInt("limit")
+	q.Offset, _ = cmd.Flags().GetInt("offset")
+
+	times := map[string]*time.Time{
+		"created-after":  &q.CreatedAfter,
+		"created-before": &q.CreatedBefore,
+		"updated-after":  &q.UpdatedAfter,
+		"updated-before": &q.UpdatedBefore,
+	}
+	for flag, target := range times {
+		value, _ := cmd.Flags().GetString(flag)
+		t, err := parseTimeFlag(value)
+		if err != nil {
+			return q, fmt.Errorf("invalid --%s: %w", flag, err)
+		}
+		*target = t
+	}
+
+	return q, q.Validate()
+}
+
+// shortID returns the first characters of a story ID, enough to resolve it in most repositories
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// addStoryListFlags registers the filtering, sorting and paging flags of the list command
+func addStoryListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("status", "s", []string{}, "Only stories in any of these statuses (comma-separated)")
+	cmd.Flags().StringSliceP("tag", "g", []string{}, "Only stories with all of these tags (comma-separated)")
+	cmd.Flags().StringP("author", "a", "", "Only stories by this author")
+	cmd.Flags().StringP("jira", "j", "", "Only stories linked to this Jira key")
+	cmd.Flags().String("created-after", "", "Only stories created at or after this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().String("created-before", "", "Only stories created at or before this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().String("updated-after", "", "Only stories updated at or after this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().String("updated-before", "", "Only stories updated at or before this time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().String("sort", story.SortByCreated, "Sort key (created, updated, number, title, status)")
+	cmd.Flags().BoolP("reverse", "r", false, "Reverse the sort order")
+	cmd.Flags().IntP("limit", "l", 0, "Maximum number of stories to show (0 for all)")
+	cmd.Flags().Int("offset", 0, "Number of stories to skip")
+}
+
+var storyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stories",
+	Long: `List stories in a compact table, with optional filtering, sorting and paging.
+
+Examples:
+  tracer story list
+  tracer story list --status in-progress,blocked
+  tracer story list --tag auth --author john.doe
+  tracer story list --updated-after 2024-01-01 --sort updated
+  tracer story list --sort number --limit 10 --offset 10
+
+Sort keys:
+  created  Newest first (default)
+  updated  Most recently updated first
+  number   Lowest number first
+  title    Alphabetical
+  status   Alphabetical`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get commit hash from flag
-		hash, _ := cmd.Flags().GetString("hash")
-		if hash == "" {
-			return fmt.Errorf("commit hash is required")
+		q, err := storyQueryFromFlags(cmd)
+		if err != nil {
+			return err
 		}
 
-		// Get all stories
-		stories, err := story.ListStories()
+		stories, total, err := story.QueryStories(q)
 		if err != nil {
 			return fmt.Errorf("failed to list stories: %w", err)
 		}
 
-		// Filter stories modified after the given hash
-		var modifiedStories []*story.Story
+		if len(stories) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No stories found\n")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "NUMBER\tID\tSTATUS\tTITLE\tAUTHOR\tJIRA\tUPDATED\n")
 		for _, s := range stories {
-			for _, commit := range s.Commits {
-				if commit.Hash == hash {
-					modifiedStories = append(modifiedStories, s)
-					break
+			number := "-"
+			if s.Number > 0 {
+				number = fmt.Sprintf("%d", s.Number)
+			}
+			jiraKey := s.JiraKey
+			if jiraKey == "" {
+				jiraKey = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				number, shortID(s.ID), s.Status, s.Title, s.Author, jiraKey, s.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to write story list: %w", err)
+		}
+
+		if len(stories) < total {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nShowing %d-%d of %d stories\n", q.Offset+1, q.Offset+len(stories), total)
+		}
+
+		return nil
+	},
+}
+
+// printStoryDetails writes the fields of a story
+func printStoryDetails(out io.Writer, s *story.Story) {
+	fmt.Fprintf(out, "Story: %s\n\n", s.Title)
+	fmt.Fprintf(out, "  ID: %s\n", s.ID)
+	if s.Number > 0 {
+		fmt.Fprintf(out, "  Number: %d\n", s.Number)
+	}
+	if s.Description != "" {
+		fmt.Fprintf(out, "  Description: %s\n", s.Description)
+	}
+	fmt.Fprintf(out, "  Status: %s\n", s.Status)
+	fmt.Fprintf(out, "  Author: %s\n", s.Author)
+	if len(s.Tags) > 0 {
+		fmt.Fprintf(out, "  Tags: %s\n", strings.Join(s.Tags, ", "))
+	}
+	fmt.Fprintf(out, "  Created: %s\n", s.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(out, "  Updated: %s\n", s.UpdatedAt.Format(time.RFC3339))
+	if branchName := s.BranchName(); branchName != "" {
+		fmt.Fprintf(out, "  Branch: %s\n", branchName)
+	}
+	if s.JiraKey != "" {
+		fmt.Fprintf(out, "  Jira: %s\n", s.JiraKey)
+		if cfg, err := config.LoadConfig(); err == nil && cfg.JiraHost != "" {
+			fmt.Fprintf(out, "  Jira URL: %s\n", config.JiraIssueURL(cfg.JiraHost, s.JiraKey))
+		}
+	}
+}
+
+// printStoryActivity writes the status history, commits and files of a story
+func printStoryActivity(out io.Writer, s *story.Story) {
+	if len(s.Transitions) > 0 {
+		fmt.Fprintf(out, "\nStatus History:\n")
+		for _, t := range s.Transitions {
+			fmt.Fprintf(out, "  %s  %s -> %s by %s", t.At.Format(time.RFC3339), t.From, t.To, t.By)
+			if t.Reason != "" {
+				fmt.Fprintf(out, " (%s)", t.Reason)
+			}
+			fmt.Fprintf(out, "\n")
+		}
+	}
+
+	if len(s.Commits) > 0 {
+		fmt.Fprintf(out, "\nCommits:\n")
+		for _, commit := range s.Commits {
+			subject, _, _ := strings.Cut(commit.Message, "\n")
+			fmt.Fprintf(out, "  %s  %s  %s  %s\n", shortID(commit.Hash), commit.Timestamp.Format(time.RFC3339), strings.Join(commit.Authors(), ", "), subject)
+		}
+	}
+
+	if len(s.Files) > 0 {
+		fmt.Fprintf(out, "\nFiles:\n")
+		for _, file := range s.Files {
+			fmt.Fprintf(out, "  %s  %s\n", file.Status, file.Path)
+		}
+	}
+}
+
+var storyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a story in full",
+	Long: `Display every detail of a story, including its status history, commits,
+files and Jira link. Shows the current story when --id is not given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
+		if err != nil {
+			return err
+		}
+
+		printStoryDetails(cmd.OutOrStdout(), s)
+		printStoryActivity(cmd.OutOrStdout(), s)
+
+		return nil
+	},
+}
+
+// openEditor opens the given file in the user's editor and waits for it to close.
+// It can be replaced in tests.
+var openEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	//nolint:gosec // The editor is chosen by the user running the command
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	return editorCmd.Run()
+}
+
+// editStoryInEditor opens the story as YAML in the user's editor and returns the
+// validated result
+func editStoryInEditor(s *story.Story) (*story.Story, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal story: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "tracer-story-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := openEditor(tmpFile.Name()); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	editedData, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited story: %w", err)
+	}
+	if bytes.Equal(data, editedData) {
+		return nil, fmt.Errorf("no changes made")
+	}
+
+	edited, err := story.ParseYAML(editedData)
+	if err != nil {
+		return nil, err
+	}
+	if err := story.ValidateEdit(s, edited); err != nil {
+		return nil, fmt.Errorf("invalid edit: %w", err)
+	}
+	edited.Filename = s.Filename
+
+	return edited, nil
+}
+
+// applyStoryEditFlags updates the story from the edit command flags and reports
+// whether anything was requested
+func applyStoryEditFlags(cmd *cobra.Command, s *story.Story) (bool, error) {
+	flags := cmd.Flags()
+
+	if flags.Changed("title") {
+		s.Title, _ = flags.GetString("title")
+	}
+	if flags.Changed("description") {
+		s.Description, _ = flags.GetString("description")
+	}
+	if flags.Changed("number") {
+		number, _ := flags.GetInt("number")
+		if number <= 0 {
+			return true, fmt.Errorf("story number must be greater than 0")
+		}
+		s.Number = number
+	}
+	if flags.Changed("tags") {
+		tags, _ := flags.GetStringSlice("tags")
+		s.Tags = []string{}
+		s.AddTags(tags...)
+	}
+	if flags.Changed("add-tag") {
+		tags, _ := flags.GetStringSlice("add-tag")
+		s.AddTags(tags...)
+	}
+	if flags.Changed("remove-tag") {
+		tags, _ := flags.GetStringSlice("remove-tag")
+		s.RemoveTags(tags...)
+	}
+
+	for _, name := range []string{"title", "description", "number", "tags", "add-tag", "remove-tag"} {
+		if flags.Changed(name) {
+			return true, s.Validate()
+		}
+	}
+	return false, nil
+}
+
+// offerBranchRename renames the story branch when its title or number changed,
+// asking first unless --rename-branch was given
+func offerBranchRename(cmd *cobra.Command, oldBranch, newBranch string) error {
+	if oldBranch == "" || newBranch == "" || oldBranch == newBranch {
+		return nil
+	}
+
+	exists, err := utils.GitClient.BranchExists(oldBranch)
+	if err != nil || !exists {
+		return nil
+	}
+
+	rename, _ := cmd.Flags().GetBool("rename-branch")
+	if !cmd.Flags().Changed("rename-branch") {
+		fmt.Fprintf(cmd.OutOrStdout(), "Branch %s no longer matches the story. Rename it to %s? [y/N]: ", oldBranch, newBranch)
+		answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		rename = answer == "y" || answer == "yes"
+	}
+
+	if !rename {
+		fmt.Fprintf(cmd.OutOrStdout(), "Branch %s left unchanged\n", oldBranch)
+		return nil
+	}
+
+	if err := utils.GitClient.RenameBranch(oldBranch, newBranch); err != nil {
+		return fmt.Errorf("failed to rename branch %s to %s: %w", oldBranch, newBranch, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Renamed branch %s to %s\n", oldBranch, newBranch)
+
+	return nil
+}
+
+var storyEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit a story",
+	Long: `Update a story's title, description, tags or number.
+
+Examples:
+  tracer story edit --id 123 --title "New title"
+  tracer story edit --id 123 --add-tag api --remove-tag draft
+  tracer story edit --id 123 --number 124 --rename-branch
+  tracer story edit --id 123 --editor
+
+With --editor the story opens as YAML in $VISUAL or $EDITOR. The ID, author,
+creation time, status and history cannot be edited; use the lifecycle commands
+to change the status.
+
+When the title or number change, the story branch name changes too. tracer
+offers to rename the existing branch; --rename-branch answers up front.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
+		if err != nil {
+			return err
+		}
+		oldBranch := s.BranchName()
+
+		useEditor, _ := cmd.Flags().GetBool("editor")
+		if useEditor {
+			edited, err := editStoryInEditor(s)
+			if err != nil {
+				return err
+			}
+			// Merge with changes saved meanwhile, the edit may have taken a while
+			edited.UpdatedAt = time.Now()
+			if err := story.SaveMerged(s, edited); err != nil {
+				return err
+			}
+			s = edited
+		} else {
+			err := story.Update(s, func(s *story.Story) error {
+				changed, err := applyStoryEditFlags(cmd, s)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					return fmt.Errorf("nothing to edit. Use --title, --description, --number, --tags, --add-tag, --remove-tag or --editor")
 				}
+				s.UpdatedAt = time.Now()
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		}
 
-		if len(modifiedStories) == 0 {
-			fmt.Fprintf(cmd.OutOrStdout(), "No stories found modified after commit %s\n", hash)
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated story %s (%s)\n", s.ID, s.Title)
+
+		return offerBranchRename(cmd, oldBranch, s.BranchName())
+	},
+}
+
+var storyRenumberCmd = &cobra.Command{
+	Use:   "renumber",
+	Short: "Repair duplicate story numbers",
+	Long: `Find stories that share a number and give all but the oldest of each
+group the next free number. Branches named after the old numbers can be
+renamed along the way.
+
+Examples:
+  tracer story renumber --dry-run
+  tracer story renumber --rename-branch`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		stories, err := story.ListStories()
+		if err != nil {
+			return fmt.Errorf("failed to list stories: %w", err)
+		}
+
+		project, _ := utils.GetProjectName()
+		plan := story.PlanRenumbering(stories, project)
+		if len(plan) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No duplicate story numbers found\n")
+			return nil
+		}
+
+		for _, change := range plan {
+			fmt.Fprintf(cmd.OutOrStdout(), "Story %s (%s): %d -> %d\n", change.Story.ID, change.Story.Title, change.From, change.To)
+			if dryRun {
+				continue
+			}
+
+			oldBranch := change.Story.BranchName()
+			to := change.To
+			if err := story.Update(change.Story, func(s *story.Story) error {
+				s.Number = to
+				s.UpdatedAt = time.Now()
+				return nil
+			}); err != nil {
+				return fmt.Errorf("story %s: %w", change.Story.ID, err)
+			}
+			if err := offerBranchRename(cmd, oldBranch, change.Story.BranchName()); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nDry run, no stories changed\n")
+		}
+
+		return nil
+	},
+}
+
+var storySwitchCmd = &cobra.Command{
+	Use:   "switch <story>",
+	Short: "Switch the current story",
+	Long: `Make a story the current one and check out its branch.
+
+The current story is the one new commits are attached to. The story can be
+given by number, ID or unique ID prefix, branch name or linked Jira key.
+
+Example:
+  tracer story switch 123`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Find the story
+		s, err := story.Resolve(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load story: %w", err)
+		}
+
+		// Check out the story branch
+		branchName := s.BranchName()
+		if branchName != "" {
+			if err := utils.CreateBranch(branchName); err != nil {
+				return fmt.Errorf("failed to check out branch %s: %w", branchName, err)
+			}
+		}
+
+		// Remember the story as the current one
+		if err := story.SetCurrent(s); err != nil {
+			return fmt.Errorf("failed to set current story: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Switched to story %s (%s)\n", s.ID, s.Title)
+		if branchName != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Branch: %s\n", branchName)
+		}
+
+		return nil
+	},
+}
+
+var storyCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the current story",
+	Long:  `Display the story new commits are currently attached to.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := story.GetCurrent()
+		if err != nil {
+			return fmt.Errorf("failed to get current story: %w", err)
+		}
+
+		if s == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "No current story\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "\nTo pick one:\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "  tracer story switch <story-id|number>\n")
+			return nil
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Current Story:\n")
+		fmt.Fprintf(cmd.OutOrStdout(), "  ID: %s\n", s.ID)
+		if s.Number > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Number: %d\n", s.Number)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  Title: %s\n", s.Title)
+		fmt.Fprintf(cmd.OutOrStdout(), "  Status: %s\n", s.Status)
+		if s.JiraKey != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Jira: %s\n", s.JiraKey)
+		}
+		if branchName := s.BranchName(); branchName != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Branch: %s\n", branchName)
+		}
+
+		return nil
+	},
+}
+
+// resolveStoryFlag resolves the story referenced by the given flag, falling back
+// to the current story when the flag is not set
+func resolveStoryFlag(cmd *cobra.Command, flag string) (*story.Story, error) {
+	ref, _ := cmd.Flags().GetString(flag)
+
+	s, err := story.ResolveOrCurrent(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load story: %w", err)
+	}
+
+	return s, nil
+}
+
+// transitionAuthor returns the name recorded for a story status transition
+func transitionAuthor() string {
+	cfg, err := config.LoadConfig()
+	if err == nil && cfg.AuthorName != "" {
+		return cfg.AuthorName
+	}
+
+	author, err := utils.GitClient.GetAuthor()
+	if err == nil && strings.TrimSpace(author) != "" {
+		return strings.TrimSpace(author)
+	}
+
+	return "unknown"
+}
+
+// transitionStory moves the story given by the --id flag to the given status
+func transitionStory(cmd *cobra.Command, status string) error {
+	// Resolve the story
+	s, err := resolveStoryFlag(cmd, "id")
+	if err != nil {
+		return err
+	}
+
+	reason := ""
+	if cmd.Flags().Lookup("reason") != nil {
+		reason, _ = cmd.Flags().GetString("reason")
+	}
+
+	// Apply the transition, again on the latest version if the story changed meanwhile
+	var from string
+	if err := story.Update(s, func(s *story.Story) error {
+		from = s.Status
+		return s.TransitionTo(status, transitionAuthor(), reason)
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Story %s (%s) moved from %s to %s\n", s.ID, s.Title, from, s.Status)
+	if reason != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Reason: %s\n", reason)
+	}
+
+	return nil
+}
+
+var storyStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start working on a story",
+	Long: `Move a story to in-progress. Works for open stories, blocked stories
+and stories sent back from review.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionStory(cmd, story.StatusInProgress)
+	},
+}
+
+var storyBlockCmd = &cobra.Command{
+	Use:   "block",
+	Short: "Mark a story as blocked",
+	Long:  `Move an in-progress story to blocked, optionally recording why.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionStory(cmd, story.StatusBlocked)
+	},
+}
+
+var storyReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Send a story to review",
+	Long:  `Move an in-progress story to in-review.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionStory(cmd, story.StatusInReview)
+	},
+}
+
+var storyDoneCmd = &cobra.Command{
+	Use:   "done",
+	Short: "Mark a story as done",
+	Long:  `Move a story in review to done.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionStory(cmd, story.StatusDone)
+	},
+}
+
+var storyReopenCmd = &cobra.Command{
+	Use:   "reopen",
+	Short: "Reopen a finished story",
+	Long:  `Move a done story back to open.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionStory(cmd, story.StatusOpen)
+	},
+}
+
+var storyAfterHashCmd = &cobra.Command{
+	Use:   "after-hash",
+	Short: "Show stories with commits after a revision",
+	Long: `Display the stories that have commits landing after a revision, using git
+ancestry rather than dates: a commit is after a revision when it descends from it.
+
+--before selects the commits of the revision itself and its ancestors, and
+--range A..B the commits reachable from B but not from A, as in git log. The
+bounds can be combined; a commit must then satisfy all of them.
+
+Revisions can be commit hashes, branches or tags. Stories are matched by their
+recorded commits, run 'tracer story rebuild' first to pick up commits made
+outside of tracer.
+
+Examples:
+  tracer story after-hash --hash v1.4           # Stories that landed since v1.4
+  tracer story after-hash --range v1.3..v1.4    # Stories released in v1.4
+  tracer story after-hash --before v1.4         # Stories included in v1.4`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var selection story.CommitSelection
+		selection.After, _ = cmd.Flags().GetString("hash")
+		selection.Before, _ = cmd.Flags().GetString("before")
+		selection.Range, _ = cmd.Flags().GetString("range")
+		if selection.IsEmpty() {
+			return fmt.Errorf("a revision is required. Use --hash, --before or --range")
+		}
+
+		hashes, err := selection.Commits()
+		if err != nil {
+			return err
+		}
+
+		// Get all stories
+		stories, err := story.ListStories()
+		if err != nil {
+			return fmt.Errorf("failed to list stories: %w", err)
+		}
+
+		matches := story.StoriesWithCommits(stories, hashes)
+		description := describeSelection(selection)
+		if len(matches) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No stories found with commits %s\n", description)
 			return nil
 		}
 
 		// Display stories
-		fmt.Fprintf(cmd.OutOrStdout(), "Stories modified after commit %s:\n\n", hash)
-		for _, s := range modifiedStories {
+		fmt.Fprintf(cmd.OutOrStdout(), "Stories with commits %s:\n\n", description)
+		for _, match := range matches {
+			s := match.Story
 			fmt.Fprintf(cmd.OutOrStdout(), "ID: %s\n", s.ID)
 			fmt.Fprintf(cmd.OutOrStdout(), "Title: %s\n", s.Title)
 			if s.Description != "" {
@@ -167,6 +866,10 @@ var storyAfterHashCmd = &cobra.Command{
 			if len(s.Tags) > 0 {
 				fmt.Fprintf(cmd.OutOrStdout(), "Tags: %v\n", s.Tags)
 			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Commits:\n")
+			for _, commit := range match.Commits {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s %s\n", shortHash(commit.Hash), firstLine(commit.Message))
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "---\n")
 		}
 
@@ -174,6 +877,30 @@ var storyAfterHashCmd = &cobra.Command{
 	},
 }
 
+// describeSelection describes the bounds of a commit selection for messages
+func describeSelection(selection story.CommitSelection) string {
+	var parts []string
+	if selection.After != "" {
+		parts = append(parts, "after "+selection.After)
+	}
+	if selection.Before != "" {
+		parts = append(parts, "up to "+selection.Before)
+	}
+	if selection.Range != "" {
+		parts = append(parts, "in "+selection.Range)
+	}
+	return strings.Join(parts, " and ")
+}
+
+// shortHash returns the abbreviated form of a commit hash
+func shortHash(hash string) string {
+	hash = strings.TrimSpace(hash)
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
 var storyByCmd = &cobra.Command{
 	Use:   "by",
 	Short: "Show stories by author",
@@ -227,24 +954,21 @@ var storyByCmd = &cobra.Command{
 var storyFilesCmd = &cobra.Command{
 	Use:   "files",
 	Short: "Show files associated with a story",
-	Long:  `Display all files that have been modified as part of a story.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get story ID from flag
-		storyID, _ := cmd.Flags().GetString("id")
-		if storyID == "" {
-			return fmt.Errorf("story ID is required")
-		}
+	Long: `Display all files that have been modified as part of a story.
 
-		// Load the story
-		s, err := story.LoadStory(storyID)
+The files are read from the git history of the story, see 'tracer story
+rebuild', so commits made with plain git commit are included.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
 		if err != nil {
-			return fmt.Errorf("failed to load story: %w", err)
+			return err
 		}
 
-		// Get files
-		files := s.GetFiles()
+		// Get files, as changed by the commits git knows for the story
+		_, files := story.Timeline(s, storyBaseBranch())
 		if len(files) == 0 {
-			fmt.Fprintf(cmd.OutOrStdout(), "No files found for story %s\n", storyID)
+			fmt.Fprintf(cmd.OutOrStdout(), "No files found for story %s\n", s.ID)
 			return nil
 		}
 
@@ -252,7 +976,7 @@ var storyFilesCmd = &cobra.Command{
 		fmt.Fprintf(cmd.OutOrStdout(), "Files for story %s (%s):\n\n", s.ID, s.Title)
 		for _, file := range files {
 			fmt.Fprintf(cmd.OutOrStdout(), "Path: %s\n", file.Path)
-			fmt.Fprintf(cmd.OutOrStdout(), "Status: %s\n", file.Status)
+			printFileChange(cmd.OutOrStdout(), "", file)
 			fmt.Fprintf(cmd.OutOrStdout(), "Modified: %s\n", file.Timestamp.Format(time.RFC3339))
 			fmt.Fprintf(cmd.OutOrStdout(), "---\n")
 		}
@@ -266,22 +990,16 @@ var storyCommitsCmd = &cobra.Command{
 	Short: "Show commits associated with a story",
 	Long:  `Display all commits that are part of a story's development.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get story ID from flag
-		storyID, _ := cmd.Flags().GetString("id")
-		if storyID == "" {
-			return fmt.Errorf("story ID is required")
-		}
-
-		// Load the story
-		s, err := story.LoadStory(storyID)
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
 		if err != nil {
-			return fmt.Errorf("failed to load story: %w", err)
+			return err
 		}
 
 		// Get commits
 		commits := s.GetCommits()
 		if len(commits) == 0 {
-			fmt.Fprintf(cmd.OutOrStdout(), "No commits found for story %s\n", storyID)
+			fmt.Fprintf(cmd.OutOrStdout(), "No commits found for story %s\n", s.ID)
 			return nil
 		}
 
@@ -290,6 +1008,9 @@ var storyCommitsCmd = &cobra.Command{
 		for _, commit := range commits {
 			fmt.Fprintf(cmd.OutOrStdout(), "Hash: %s\n", commit.Hash)
 			fmt.Fprintf(cmd.OutOrStdout(), "Author: %s\n", commit.Author)
+			for _, coAuthor := range commit.CoAuthors {
+				fmt.Fprintf(cmd.OutOrStdout(), "Co-author: %s\n", coAuthor)
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Date: %s\n", commit.Timestamp.Format(time.RFC3339))
 			fmt.Fprintf(cmd.OutOrStdout(), "Message: %s\n", commit.Message)
 			fmt.Fprintf(cmd.OutOrStdout(), "---\n")
@@ -299,21 +1020,82 @@ var storyCommitsCmd = &cobra.Command{
 	},
 }
 
+var storyRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the commits and files of a story from git",
+	Long: `Replace the commits and files recorded in a story with its history in git.
+
+A commit belongs to the story when it is on the story branch but not on the
+configured base branch (git_branch), or when its message mentions the story ID,
+Jira key, project and number (e.g. tracer-42) or branch name. Every commit is
+recorded with its real hash, author and date, and every file it changed with
+its status (added, modified, deleted or renamed) and line counts.
+
+Examples:
+  tracer story rebuild
+  tracer story rebuild --id 42`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := resolveStoryFlag(cmd, "id")
+		if err != nil {
+			return err
+		}
+
+		dropped, err := story.Rebuild(s, storyBaseBranch())
+		if err != nil {
+			return fmt.Errorf("failed to rebuild story: %w", err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Rebuilt story %s (%s) from git: %d commit(s), %d file change(s)\n",
+			s.ID, s.Title, len(s.Commits), len(s.Files))
+		for _, commit := range dropped {
+			fmt.Fprintf(cmd.OutOrStdout(), "  Dropped %s, not found in git: %s\n", commit.Hash, firstLine(commit.Message))
+		}
+
+		return nil
+	},
+}
+
+// storyBaseBranch returns the branch story branches are created from
+func storyBaseBranch() string {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return config.DefaultGitBranch
+	}
+	return cfg.GitBranch
+}
+
+// firstLine returns the first line of a commit message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
+
+// printFileChange prints the status of a file change and, when known, the
+// commit that made it and its line counts
+func printFileChange(out io.Writer, indent string, file story.File) {
+	if file.OldPath != "" {
+		fmt.Fprintf(out, "%sStatus: %s (from %s)\n", indent, file.Status, file.OldPath)
+	} else {
+		fmt.Fprintf(out, "%sStatus: %s\n", indent, file.Status)
+	}
+	if file.Commit != "" {
+		fmt.Fprintf(out, "%sCommit: %s\n", indent, file.Commit)
+		fmt.Fprintf(out, "%sLines: +%d -%d\n", indent, file.Additions, file.Deletions)
+	}
+}
+
 var storyDiaryCmd = &cobra.Command{
 	Use:   "diary",
 	Short: "Show story development diary",
-	Long:  `Display a chronological diary of story development activities.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get story ID from flag
-		storyID, _ := cmd.Flags().GetString("id")
-		if storyID == "" {
-			return fmt.Errorf("story ID is required")
-		}
+	Long: `Display a chronological diary of story development activities.
 
-		// Load the story
-		s, err := story.LoadStory(storyID)
+The commits and file changes are read from the git history of the story, see
+'tracer story rebuild', so commits made with plain git commit are included.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
 		if err != nil {
-			return fmt.Errorf("failed to load story: %w", err)
+			return err
 		}
 
 		// Get time range from flags
@@ -346,14 +1128,16 @@ var storyDiaryCmd = &cobra.Command{
 			startTime.Format(time.RFC3339),
 			endTime.Format(time.RFC3339))
 
+		commits, files := story.Timeline(s, storyBaseBranch())
+
 		// Display commits in chronological order
-		if len(s.Commits) > 0 {
+		if len(commits) > 0 {
 			fmt.Fprintf(cmd.OutOrStdout(), "Commits:\n")
-			for _, commit := range s.Commits {
+			for _, commit := range commits {
 				if commit.Timestamp.After(startTime) && commit.Timestamp.Before(endTime) {
 					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", commit.Timestamp.Format(time.RFC3339))
 					fmt.Fprintf(cmd.OutOrStdout(), "  Hash: %s\n", commit.Hash)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Author: %s\n", commit.Author)
+					fmt.Fprintf(cmd.OutOrStdout(), "  Author: %s\n", strings.Join(commit.Authors(), ", "))
 					fmt.Fprintf(cmd.OutOrStdout(), "  Message: %s\n", commit.Message)
 					fmt.Fprintf(cmd.OutOrStdout(), "  ---\n")
 				}
@@ -361,13 +1145,13 @@ var storyDiaryCmd = &cobra.Command{
 		}
 
 		// Display file changes in chronological order
-		if len(s.Files) > 0 {
+		if len(files) > 0 {
 			fmt.Fprintf(cmd.OutOrStdout(), "\nFile Changes:\n")
-			for _, file := range s.Files {
+			for _, file := range files {
 				if file.Timestamp.After(startTime) && file.Timestamp.Before(endTime) {
 					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", file.Timestamp.Format(time.RFC3339))
 					fmt.Fprintf(cmd.OutOrStdout(), "  Path: %s\n", file.Path)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Status: %s\n", file.Status)
+					printFileChange(cmd.OutOrStdout(), "  ", file)
 					fmt.Fprintf(cmd.OutOrStdout(), "  ---\n")
 				}
 			}
@@ -378,92 +1162,142 @@ var storyDiaryCmd = &cobra.Command{
 }
 
 var storyDiffCmd = &cobra.Command{
-	Use:   "diff",
+	Use:   "diff [-- <path>...]",
 	Short: "Show story changes",
-	Long:  `Display the changes made as part of a story between two points.`,
+	Long: `Display the code changes made as part of a story.
+
+By default this is the combined diff from where the story branch left the
+configured base branch (git_branch) to the tip of the story branch. --from and
+--to take other revisions (commits, branches or tags).
+
+They also take times (RFC3339 or YYYY-MM-DD); the story commits made in that
+window are then shown one by one. The same happens when the story branch no
+longer exists, for instance after it was merged and deleted.
+
+Examples:
+  tracer story diff
+  tracer story diff --stat
+  tracer story diff --id 42 --name-status -- internal/
+  tracer story diff --from v1.4 --to HEAD
+  tracer story diff --from 2024-03-01 --to 2024-03-08`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get story ID from flag
-		storyID, _ := cmd.Flags().GetString("id")
-		if storyID == "" {
-			return fmt.Errorf("story ID is required")
+		// Resolve the story
+		s, err := resolveStoryFlag(cmd, "id")
+		if err != nil {
+			return err
+		}
+
+		opts := story.DiffOptions{Format: story.DiffPatch, Paths: args}
+		stat, _ := cmd.Flags().GetBool("stat")
+		nameStatus, _ := cmd.Flags().GetBool("name-status")
+		switch {
+		case stat && nameStatus:
+			return fmt.Errorf("--stat and --name-status cannot be combined")
+		case stat:
+			opts.Format = story.DiffStat
+		case nameStatus:
+			opts.Format = story.DiffNameStatus
 		}
 
-		// Get from and to points from flags
+		// Bounds are either revisions or times
 		from, _ := cmd.Flags().GetString("from")
 		to, _ := cmd.Flags().GetString("to")
-
-		// Load the story
-		s, err := story.LoadStory(storyID)
-		if err != nil {
-			return fmt.Errorf("failed to load story: %w", err)
+		if t, ok := timeBound(from, false); ok {
+			opts.Since = t
+		} else {
+			opts.From = from
 		}
-
-		// If no from/to points specified, use story creation and last update
-		if from == "" {
-			from = s.CreatedAt.Format(time.RFC3339)
+		if t, ok := timeBound(to, true); ok {
+			opts.Until = t
+		} else {
+			opts.To = to
 		}
-		if to == "" {
-			to = s.UpdatedAt.Format(time.RFC3339)
+
+		diff, err := story.Diff(s, storyBaseBranch(), opts)
+		if err != nil {
+			return fmt.Errorf("failed to diff story: %w", err)
 		}
 
 		// Display story info
-		fmt.Fprintf(cmd.OutOrStdout(), "Story Changes: %s (%s)\n\n", s.Title, s.ID)
-		fmt.Fprintf(cmd.OutOrStdout(), "Time Range: %s to %s\n\n", from, to)
-
-		// Display commits in the range
-		if len(s.Commits) > 0 {
-			fmt.Fprintf(cmd.OutOrStdout(), "Commits:\n")
-			for _, commit := range s.Commits {
-				commitTime := commit.Timestamp.Format(time.RFC3339)
-				if commitTime >= from && commitTime <= to {
-					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", commitTime)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Hash: %s\n", commit.Hash)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Author: %s\n", commit.Author)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Message: %s\n", commit.Message)
-					fmt.Fprintf(cmd.OutOrStdout(), "  ---\n")
-				}
-			}
+		fmt.Fprintf(cmd.OutOrStdout(), "Story Changes: %s (%s)\n", s.Title, s.ID)
+		if diff.From != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Range: %s..%s\n\n", diff.From, diff.To)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Commits: %d\n\n", len(diff.Commits))
 		}
 
-		// Display file changes in the range
-		if len(s.Files) > 0 {
-			fmt.Fprintf(cmd.OutOrStdout(), "\nFile Changes:\n")
-			for _, file := range s.Files {
-				fileTime := file.Timestamp.Format(time.RFC3339)
-				if fileTime >= from && fileTime <= to {
-					fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", fileTime)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Path: %s\n", file.Path)
-					fmt.Fprintf(cmd.OutOrStdout(), "  Status: %s\n", file.Status)
-					fmt.Fprintf(cmd.OutOrStdout(), "  ---\n")
-				}
-			}
+		if diff.Output == "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "No changes\n")
+			return nil
 		}
+		fmt.Fprint(cmd.OutOrStdout(), diff.Output)
 
 		return nil
 	},
 }
 
+// timeBound parses a diff bound given as a time, anything else is a revision.
+// A date given as the end bound includes the whole day.
+func timeBound(value string, end bool) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := parseTimeFlag(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if end && len(value) == len("2006-01-02") {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, true
+}
+
+// storyRefUsage describes the accepted forms of a story reference flag
+const storyRefUsage = "Story number, ID or ID prefix, branch name or Jira key (defaults to the current story)"
+
 func init() {
 	// Add flags to new command with better descriptions
 	storyNewCmd.Flags().StringP("title", "t", "", "Story title (e.g., 'Add user authentication')")
 	storyNewCmd.Flags().StringP("description", "d", "", "Story description (e.g., 'Implement OAuth2 authentication flow')")
 	storyNewCmd.Flags().StringSliceP("tags", "g", []string{}, "Story tags (comma-separated, e.g., 'auth,security')")
-	storyNewCmd.Flags().IntP("number", "n", 0, "Story number (must be > 0)")
-	if err := storyNewCmd.MarkFlagRequired("number"); err != nil {
-		panic(fmt.Sprintf("failed to mark number flag as required: %v", err))
+	storyNewCmd.Flags().IntP("number", "n", 0, "Story number (must be > 0 and unused, defaults to the next free number)")
+	storyNewCmd.Flags().StringP("jira", "j", "", "Jira key to link (e.g., 'PROJ-123'), also used as the story number")
+
+	// Add flags to list command
+	addStoryListFlags(storyListCmd)
+
+	// Add flags to edit command
+	storyEditCmd.Flags().StringP("id", "i", "", storyRefUsage)
+	storyEditCmd.Flags().StringP("title", "t", "", "New story title")
+	storyEditCmd.Flags().StringP("description", "d", "", "New story description")
+	storyEditCmd.Flags().IntP("number", "n", 0, "New story number (must be > 0)")
+	storyEditCmd.Flags().StringSliceP("tags", "g", []string{}, "Replace all tags (comma-separated)")
+	storyEditCmd.Flags().StringSlice("add-tag", []string{}, "Tags to add (comma-separated)")
+	storyEditCmd.Flags().StringSlice("remove-tag", []string{}, "Tags to remove (comma-separated)")
+	storyEditCmd.Flags().BoolP("editor", "e", false, "Edit the story as YAML in $EDITOR")
+	storyEditCmd.Flags().Bool("rename-branch", false, "Rename the story branch if its name changes, without asking")
+
+	// Add flags to renumber command
+	storyRenumberCmd.Flags().Bool("dry-run", false, "Only show the changes that would be made")
+	storyRenumberCmd.Flags().Bool("rename-branch", false, "Rename story branches to match the new numbers, without asking")
+
+	// Add flags to show command
+	storyShowCmd.Flags().StringP("id", "i", "", storyRefUsage)
+
+	// Add flags to lifecycle commands
+	for _, c := range []*cobra.Command{storyStartCmd, storyBlockCmd, storyReviewCmd, storyDoneCmd, storyReopenCmd} {
+		c.Flags().StringP("id", "i", "", storyRefUsage)
 	}
+	storyBlockCmd.Flags().StringP("reason", "r", "", "Why the story is blocked")
 
 	// Add flags to commits command
-	storyCommitsCmd.Flags().StringP("id", "i", "", "Story ID to show commits for")
-	if err := storyCommitsCmd.MarkFlagRequired("id"); err != nil {
-		panic(fmt.Sprintf("failed to mark id flag as required: %v", err))
-	}
+	storyCommitsCmd.Flags().StringP("id", "i", "", storyRefUsage)
 
 	// Add flags to after-hash command
-	storyAfterHashCmd.Flags().StringP("hash", "H", "", "Commit hash to filter stories from")
-	if err := storyAfterHashCmd.MarkFlagRequired("hash"); err != nil {
-		panic(fmt.Sprintf("failed to mark hash flag as required: %v", err))
-	}
+	storyAfterHashCmd.Flags().StringP("hash", "H", "", "Show stories with commits after this revision (its descendants)")
+	storyAfterHashCmd.Flags().StringP("before", "b", "", "Show stories with commits up to this revision (it and its ancestors)")
+	storyAfterHashCmd.Flags().StringP("range", "r", "", "Show stories with commits in this range (A..B)")
 
 	// Add flags to by command
 	storyByCmd.Flags().StringP("author", "a", "", "Author name to filter stories by")
@@ -472,31 +1306,39 @@ func init() {
 	}
 
 	// Add flags to files command
-	storyFilesCmd.Flags().StringP("id", "i", "", "Story ID to show files for")
-	if err := storyFilesCmd.MarkFlagRequired("id"); err != nil {
-		panic(fmt.Sprintf("failed to mark id flag as required: %v", err))
-	}
+	storyFilesCmd.Flags().StringP("id", "i", "", storyRefUsage)
 
 	// Add flags to diary command
-	storyDiaryCmd.Flags().StringP("id", "i", "", "Story ID to show diary for")
-	if err := storyDiaryCmd.MarkFlagRequired("id"); err != nil {
-		panic(fmt.Sprintf("failed to mark id flag as required: %v", err))
-	}
-	storyDiaryCmd.Flags().StringP("start", "s", "", "Start time (RFC3339 format)")
-	storyDiaryCmd.Flags().StringP("end", "e", "", "End time (RFC3339 format)")
+	storyDiaryCmd.Flags().StringP("id", "i", "", storyRefUsage)
+	storyDiaryCmd.Flags().StringP("since", "s", "", "Start time (RFC3339 format)")
+	storyDiaryCmd.Flags().StringP("until", "u", "", "End time (RFC3339 format)")
+
+	// Add flags to rebuild command
+	storyRebuildCmd.Flags().StringP("id", "i", "", storyRefUsage)
 
 	// Add flags to diff command
-	storyDiffCmd.Flags().StringP("id", "i", "", "Story ID to show diff for")
-	if err := storyDiffCmd.MarkFlagRequired("id"); err != nil {
-		panic(fmt.Sprintf("failed to mark id flag as required: %v", err))
-	}
-	storyDiffCmd.Flags().StringP("start", "s", "", "Start time (RFC3339 format)")
-	storyDiffCmd.Flags().StringP("end", "e", "", "End time (RFC3339 format)")
+	storyDiffCmd.Flags().StringP("id", "i", "", storyRefUsage)
+	storyDiffCmd.Flags().StringP("from", "f", "", "Revision or time (RFC3339 or YYYY-MM-DD) to diff from")
+	storyDiffCmd.Flags().StringP("to", "t", "", "Revision or time (RFC3339 or YYYY-MM-DD) to diff to")
+	storyDiffCmd.Flags().Bool("stat", false, "Show a diffstat instead of the patch")
+	storyDiffCmd.Flags().Bool("name-status", false, "Show only the names and statuses of changed files")
 
 	// Add commands in logical order
 	StoryCmd.AddCommand(storyNewCmd)       // Creation
+	StoryCmd.AddCommand(storyEditCmd)      // Creation
+	StoryCmd.AddCommand(storyRenumberCmd)  // Creation
+	StoryCmd.AddCommand(storySwitchCmd)    // Creation
+	StoryCmd.AddCommand(storyCurrentCmd)   // Creation
+	StoryCmd.AddCommand(storyListCmd)      // Browsing
+	StoryCmd.AddCommand(storyShowCmd)      // Browsing
+	StoryCmd.AddCommand(storyStartCmd)     // Lifecycle
+	StoryCmd.AddCommand(storyBlockCmd)     // Lifecycle
+	StoryCmd.AddCommand(storyReviewCmd)    // Lifecycle
+	StoryCmd.AddCommand(storyDoneCmd)      // Lifecycle
+	StoryCmd.AddCommand(storyReopenCmd)    // Lifecycle
 	StoryCmd.AddCommand(storyFilesCmd)     // Tracking
 	StoryCmd.AddCommand(storyCommitsCmd)   // Tracking
+	StoryCmd.AddCommand(storyRebuildCmd)   // Tracking
 	StoryCmd.AddCommand(storyDiaryCmd)     // History
 	StoryCmd.AddCommand(storyDiffCmd)      // History
 	StoryCmd.AddCommand(storyByCmd)        // Search/Filter
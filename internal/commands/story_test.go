@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -55,7 +56,7 @@ func TestStoryCommand(t *testing.T) {
 				"number": "123",
 			},
 			expectError: true,
-			errorMsg:    "failed to create story: title is required when creating a story with a number",
+			errorMsg:    "failed to create story: title is required",
 		},
 		{
 			name: "create story with number and title",
@@ -90,8 +91,24 @@ func TestStoryCommand(t *testing.T) {
 				"description": "Test Description",
 				"tags":        "tag1,tag2",
 			},
+			expectError: false,
+		},
+		{
+			name: "create story with Jira key",
+			flags: map[string]string{
+				"title": "Test Story",
+				"jira":  "PROJ-42",
+			},
+			expectError: false,
+		},
+		{
+			name: "create story with invalid Jira key",
+			flags: map[string]string{
+				"title": "Test Story",
+				"jira":  "PROJ",
+			},
 			expectError: true,
-			errorMsg:    "required flag(s) \"number\" not set",
+			errorMsg:    "invalid Jira key: PROJ",
 		},
 		{
 			name: "create story with invalid number",
@@ -159,9 +176,7 @@ func TestStoryCommand(t *testing.T) {
 			cmd.Flags().StringP("description", "d", "", "Story description")
 			cmd.Flags().StringSliceP("tags", "g", []string{}, "Story tags")
 			cmd.Flags().IntP("number", "n", 0, "Story number")
-			if err := cmd.MarkFlagRequired("number"); err != nil {
-				t.Fatalf("failed to mark number flag as required: %v", err)
-			}
+			cmd.Flags().StringP("jira", "j", "", "Jira key")
 
 			// Set flags
 			var err error
@@ -181,6 +196,10 @@ func TestStoryCommand(t *testing.T) {
 				err = cmd.Flags().Set("tags", tags)
 				require.NoError(t, err)
 			}
+			if jiraKey, ok := tt.flags["jira"]; ok {
+				err = cmd.Flags().Set("jira", jiraKey)
+				require.NoError(t, err)
+			}
 
 			// Execute command
 			rootCmd.SetArgs([]string{"story", "new"})
@@ -198,51 +217,131 @@ func TestStoryCommand(t *testing.T) {
 }
 
 func TestStoryAfterHashCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	// History: v1.3 <- aaa111 (story 1) <- v1.4 <- bbb222 (story 2)
+	mockGitClient.ParseRevisionFunc = func(rev string) (string, error) {
+		switch strings.TrimSuffix(rev, "^{commit}") {
+		case "v1.3":
+			return "tag13\n", nil
+		case "v1.4":
+			return "tag14\n", nil
+		}
+		return "", fmt.Errorf("unknown revision")
+	}
+	mockGitClient.RevListFunc = func(args ...string) ([]string, error) {
+		switch strings.Join(args, " ") {
+		case "--ancestry-path ^tag13 --all":
+			return []string{"bbb2220000000000000000000000000000000000", "tag14", "aaa1110000000000000000000000000000000000"}, nil
+		case "--ancestry-path ^tag14 --all":
+			return []string{"bbb2220000000000000000000000000000000000"}, nil
+		case "tag14":
+			return []string{"tag14", "aaa1110000000000000000000000000000000000", "tag13"}, nil
+		case "tag13..tag14":
+			return []string{"tag14", "aaa1110000000000000000000000000000000000"}, nil
+		}
+		return nil, fmt.Errorf("unexpected rev-list arguments: %v", args)
+	}
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 1)
+	require.NoError(t, err)
+	story1.Commits = []story.Commit{{Hash: "aaa111", Message: "feat: first"}}
+	require.NoError(t, story1.Save())
+	story2, err := story.NewStoryWithNumber("Story 2", "Description 2", "john.doe", 2)
+	require.NoError(t, err)
+	story2.Commits = []story.Commit{{Hash: "bbb2220000000000000000000000000000000000\n", Message: "fix: second"}}
+	require.NoError(t, story2.Save())
+
 	tests := []struct {
-		name    string
-		args    []string
-		wantErr bool
+		name          string
+		args          []string
+		expectError   string
+		expectStories []string
+		expectOutput  string
 	}{
 		{
-			name:    "valid_hash",
-			args:    []string{"--hash", "abc123"},
-			wantErr: false,
+			name:          "after a tag",
+			args:          []string{"--hash", "v1.4"},
+			expectStories: []string{"Story 2"},
+			expectOutput:  "Stories with commits after v1.4:",
+		},
+		{
+			name:          "after an older tag",
+			args:          []string{"--hash", "v1.3"},
+			expectStories: []string{"Story 1", "Story 2"},
+		},
+		{
+			name:          "before a tag",
+			args:          []string{"--before", "v1.4"},
+			expectStories: []string{"Story 1"},
 		},
 		{
-			name:    "invalid_hash",
-			args:    []string{"--hash", ""},
-			wantErr: true,
+			name:          "range",
+			args:          []string{"--range", "v1.3..v1.4"},
+			expectStories: []string{"Story 1"},
+			expectOutput:  "  aaa111 feat: first",
+		},
+		{
+			name:          "after and before combined",
+			args:          []string{"--hash", "v1.3", "--before", "v1.4"},
+			expectStories: []string{"Story 1"},
+			expectOutput:  "Stories with commits after v1.3 and up to v1.4:",
+		},
+		{
+			name:         "no stories",
+			args:         []string{"--hash", "v1.4", "--before", "v1.4"},
+			expectOutput: "No stories found with commits after v1.4 and up to v1.4",
+		},
+		{
+			name:        "missing revision",
+			args:        []string{},
+			expectError: "a revision is required",
+		},
+		{
+			name:        "unknown revision",
+			args:        []string{"--hash", "v9.9"},
+			expectError: "unknown revision: v9.9",
+		},
+		{
+			name:        "invalid range",
+			args:        []string{"--range", "v1.3...v1.4"},
+			expectError: "invalid range",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a new command for each test
 			cmd := &cobra.Command{
-				Use:   "story",
-				Short: "Manage stories",
-			}
-			afterHashCmd := &cobra.Command{
-				Use:   "after-hash",
-				Short: "Show stories after a specific commit hash",
-				RunE: func(cmd *cobra.Command, args []string) error {
-					hash, _ := cmd.Flags().GetString("hash")
-					if hash == "" {
-						return fmt.Errorf("hash is required")
-					}
-					return nil
-				},
+				Use:  "after-hash",
+				RunE: storyAfterHashCmd.RunE,
 			}
-			afterHashCmd.Flags().String("hash", "", "Commit hash")
-			cmd.AddCommand(afterHashCmd)
-
-			// Set the args
-			cmd.SetArgs(append([]string{"after-hash"}, tt.args...))
+			cmd.Flags().String("hash", "", "Revision")
+			cmd.Flags().String("before", "", "Revision")
+			cmd.Flags().String("range", "", "Range")
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
 
-			// Execute the command
 			err := cmd.Execute()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("TestStoryAfterHashCommand() error = %v, wantErr %v", err, tt.wantErr)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			output := buf.String()
+			for _, title := range []string{"Story 1", "Story 2"} {
+				if slices.Contains(tt.expectStories, title) {
+					assert.Contains(t, output, "Title: "+title)
+				} else {
+					assert.NotContains(t, output, "Title: "+title)
+				}
+			}
+			if tt.expectOutput != "" {
+				assert.Contains(t, output, tt.expectOutput)
 			}
 		})
 	}
@@ -484,6 +583,136 @@ func TestStoryDiaryCommand(t *testing.T) {
 }
 
 func TestStoryDiffCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 123)
+	require.NoError(t, err)
+	require.NoError(t, story1.Save())
+	branch := story1.BranchName()
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	branchExists := true
+	var diffCalls [][]string
+	mockGitClient.BranchExistsFunc = func(name string) (bool, error) {
+		return branchExists && name == branch, nil
+	}
+	mockGitClient.MergeBaseFunc = func(a, b string) (string, error) {
+		assert.Equal(t, config.DefaultGitBranch, a)
+		return "base123", nil
+	}
+	mockGitClient.ParseRevisionFunc = func(rev string) (string, error) {
+		return strings.TrimSuffix(rev, "^") + "-parent\n", nil
+	}
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		if args[0] != "--all" {
+			return nil, nil
+		}
+		return []utils.LogEntry{
+			{Hash: "bbb222", Timestamp: day.Add(36 * time.Hour), Message: "fix: later " + story1.ID},
+			{Hash: "aaa111", Timestamp: day.Add(10 * time.Hour), Message: "feat: first " + story1.ID},
+		}, nil
+	}
+	mockGitClient.DiffFunc = func(args ...string) (string, error) {
+		diffCalls = append(diffCalls, args)
+		return "diff --git a/main.go b/main.go\n+added line\n", nil
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		noBranch      bool
+		expectError   string
+		expectDiffs   [][]string
+		expectContent []string
+	}{
+		{
+			name:          "combined diff of the story branch",
+			args:          []string{"--id", "123"},
+			expectDiffs:   [][]string{{"base123", branch}},
+			expectContent: []string{"Range: base123.." + branch, "+added line"},
+		},
+		{
+			name:        "stat with path filters",
+			args:        []string{"--id", "123", "--stat", "--", "internal/", "README.md"},
+			expectDiffs: [][]string{{"--stat", "base123", branch, "--", "internal/", "README.md"}},
+		},
+		{
+			name:          "revision bounds",
+			args:          []string{"--id", "123", "--name-status", "--from", "v1.4", "--to", "HEAD"},
+			expectDiffs:   [][]string{{"--name-status", "v1.4", "HEAD"}},
+			expectContent: []string{"Range: v1.4..HEAD"},
+		},
+		{
+			name:          "time bounds diff the story commits in the window",
+			args:          []string{"--id", "123", "--from", "2024-03-01", "--to", "2024-03-01"},
+			expectDiffs:   [][]string{{"aaa111-parent", "aaa111"}},
+			expectContent: []string{"Commits: 1", "commit aaa111\nfeat: first"},
+		},
+		{
+			name:          "story commits one by one without a branch",
+			args:          []string{"--id", "123"},
+			noBranch:      true,
+			expectDiffs:   [][]string{{"aaa111-parent", "aaa111"}, {"bbb222-parent", "bbb222"}},
+			expectContent: []string{"Commits: 2", "commit aaa111", "commit bbb222"},
+		},
+		{
+			name:        "stat and name-status",
+			args:        []string{"--id", "123", "--stat", "--name-status"},
+			expectError: "--stat and --name-status cannot be combined",
+		},
+		{
+			name:        "revision and time bounds",
+			args:        []string{"--id", "123", "--from", "v1.4", "--to", "2024-03-01"},
+			expectError: "revision and time bounds cannot be combined",
+		},
+		{
+			name:        "invalid story ID",
+			args:        []string{"--id", "nonexistent"},
+			expectError: "no story found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			branchExists = !tt.noBranch
+			diffCalls = nil
+
+			cmd := &cobra.Command{
+				Use:  storyDiffCmd.Use,
+				Args: storyDiffCmd.Args,
+				RunE: storyDiffCmd.RunE,
+			}
+			cmd.Flags().StringP("id", "i", "", "Story ID")
+			cmd.Flags().String("from", "", "Revision or time to diff from")
+			cmd.Flags().String("to", "", "Revision or time to diff to")
+			cmd.Flags().Bool("stat", false, "Show a diffstat")
+			cmd.Flags().Bool("name-status", false, "Show names and statuses")
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+
+			require.NoError(t, err)
+			output := buf.String()
+			assert.Contains(t, output, "Story Changes: Story 1 ("+story1.ID+")")
+			assert.Equal(t, tt.expectDiffs, diffCalls)
+			for _, content := range tt.expectContent {
+				assert.Contains(t, output, content)
+			}
+		})
+	}
+}
+
+func TestStoryLifecycleCommands(t *testing.T) {
 	// Set up test repository
 	dir := setupTestRepo(t)
 
@@ -507,96 +736,666 @@ func TestStoryDiffCommand(t *testing.T) {
 	err = configureUser("john.doe")
 	require.NoError(t, err)
 
-	// Create test story with commits and files
-	now := time.Now()
 	story1, err := story.NewStory("Story 1", "Description 1", "john.doe")
 	require.NoError(t, err)
-	story1.CreatedAt = now.Add(-48 * time.Hour)
-	story1.UpdatedAt = now
-	story1.Commits = []story.Commit{
-		{Hash: "abc123", Message: "Initial commit", Author: "john.doe", Timestamp: now.Add(-24 * time.Hour)},
-		{Hash: "def456", Message: "Second commit", Author: "john.doe", Timestamp: now},
-	}
-	story1.Files = []story.File{
-		{Path: "file1.txt", Status: "added", Timestamp: now.Add(-24 * time.Hour)},
-		{Path: "file2.txt", Status: "modified", Timestamp: now},
-	}
 	err = story1.Save()
 	require.NoError(t, err)
 
+	newCmd := func(source *cobra.Command) *cobra.Command {
+		cmd := &cobra.Command{
+			Use:  source.Use,
+			RunE: source.RunE,
+		}
+		cmd.Flags().StringP("id", "i", "", "Story ID")
+		cmd.Flags().StringP("reason", "r", "", "Reason")
+		return cmd
+	}
+
 	tests := []struct {
-		name        string
-		storyID     string
-		from        string
-		to          string
-		expectError bool
-		expectCount int
+		name           string
+		cmd            *cobra.Command
+		args           []string
+		expectError    bool
+		errorMsg       string
+		expectedStatus string
 	}{
 		{
-			name:        "valid story with time range",
-			storyID:     story1.Filename,
-			from:        now.Add(-48 * time.Hour).Format(time.RFC3339),
-			to:          now.Format(time.RFC3339),
-			expectError: false,
-			expectCount: 25, // Header (3) + Commits section (1 + 2*8) + Files section (1 + 2*4)
+			name:        "done from open is refused",
+			cmd:         storyDoneCmd,
+			expectError: true,
+			errorMsg:    "cannot move story from open to done",
 		},
 		{
-			name:        "valid story without time range",
-			storyID:     story1.Filename,
-			expectError: false,
-			expectCount: 25, // Same as above
+			name:           "start",
+			cmd:            storyStartCmd,
+			expectedStatus: story.StatusInProgress,
 		},
 		{
-			name:        "invalid story ID",
-			storyID:     "nonexistent",
-			expectError: true,
-			expectCount: 0,
+			name:           "block with reason",
+			cmd:            storyBlockCmd,
+			args:           []string{"--reason", "waiting on API"},
+			expectedStatus: story.StatusBlocked,
 		},
 		{
-			name:        "empty story ID",
-			storyID:     "",
+			name:           "unblock",
+			cmd:            storyStartCmd,
+			expectedStatus: story.StatusInProgress,
+		},
+		{
+			name:           "review",
+			cmd:            storyReviewCmd,
+			expectedStatus: story.StatusInReview,
+		},
+		{
+			name:           "done",
+			cmd:            storyDoneCmd,
+			expectedStatus: story.StatusDone,
+		},
+		{
+			name:           "reopen",
+			cmd:            storyReopenCmd,
+			expectedStatus: story.StatusOpen,
+		},
+		{
+			name:        "missing story",
+			cmd:         storyStartCmd,
+			args:        []string{"--id", "nonexistent"},
 			expectError: true,
-			expectCount: 0,
+			errorMsg:    "failed to load story",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cmd := &cobra.Command{
-				Use:   "diff",
-				Short: "Show story changes",
-				RunE:  storyDiffCmd.RunE,
-			}
-
-			cmd.Flags().StringP("id", "i", "", "Story ID")
-			cmd.Flags().String("from", "", "Start point (RFC3339 format)")
-			cmd.Flags().String("to", "", "End point (RFC3339 format)")
-			if err := cmd.MarkFlagRequired("id"); err != nil {
-				t.Fatalf("failed to mark id flag as required: %v", err)
-			}
+			cmd := newCmd(tt.cmd)
 
 			var buf bytes.Buffer
 			cmd.SetOut(&buf)
 
-			args := []string{"--id", tt.storyID}
-			if tt.from != "" {
-				args = append(args, "--from", tt.from)
-			}
-			if tt.to != "" {
-				args = append(args, "--to", tt.to)
+			args := tt.args
+			if !strings.Contains(strings.Join(args, " "), "--id") {
+				args = append([]string{"--id", story1.Filename}, args...)
 			}
 			cmd.SetArgs(args)
 
 			err := cmd.Execute()
-
 			if tt.expectError {
 				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				output := buf.String()
-				lines := strings.Split(strings.TrimSpace(output), "\n")
-				require.Equal(t, tt.expectCount, len(lines))
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
 			}
+			require.NoError(t, err)
+			assert.Contains(t, buf.String(), "moved from")
+
+			loaded, err := story.LoadStory(story1.Filename)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, loaded.Status)
+			assert.Equal(t, "john.doe", loaded.Transitions[len(loaded.Transitions)-1].By)
 		})
 	}
+
+	loaded, err := story.LoadStory(story1.Filename)
+	require.NoError(t, err)
+	require.Len(t, loaded.Transitions, 6)
+	assert.Equal(t, "waiting on API", loaded.Transitions[1].Reason)
+}
+
+func TestStorySwitchAndCurrentCommands(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	// Record branch checkouts
+	var checkedOut string
+	mockGitClient.BranchExistsFunc = func(branchName string) (bool, error) {
+		return false, nil
+	}
+	mockGitClient.CreateBranchFunc = func(branchName string) error {
+		checkedOut = branchName
+		return nil
+	}
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 123)
+	require.NoError(t, err)
+	err = story1.Save()
+	require.NoError(t, err)
+
+	runCmd := func(source *cobra.Command, args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  source.Use,
+			Args: source.Args,
+			RunE: source.RunE,
+		}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	// No current story yet
+	output, err := runCmd(storyCurrentCmd)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No current story")
+
+	// Unknown story
+	_, err = runCmd(storySwitchCmd, "999")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no story found matching "999"`)
+
+	// Switch by number
+	checkedOut = ""
+	output, err = runCmd(storySwitchCmd, "123")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Switched to story "+story1.ID)
+	assert.Equal(t, "features/test-project-123-story-1", checkedOut)
+
+	output, err = runCmd(storyCurrentCmd)
+	require.NoError(t, err)
+	assert.Contains(t, output, "ID: "+story1.ID)
+	assert.Contains(t, output, "Number: 123")
+	assert.Contains(t, output, "Branch: features/test-project-123-story-1")
+
+	// Switch by ID
+	output, err = runCmd(storySwitchCmd, story1.ID)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Switched to story "+story1.ID)
+
+	// Checkout failures leave the current story untouched
+	require.NoError(t, story.ClearCurrent())
+	mockGitClient.CreateBranchFunc = func(branchName string) error {
+		return fmt.Errorf("local changes would be overwritten")
+	}
+	_, err = runCmd(storySwitchCmd, "123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to check out branch")
+	current, err := story.GetCurrent()
+	require.NoError(t, err)
+	assert.Nil(t, current)
+}
+
+func TestCommitAttachesToCurrentStoryWithoutJira(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	mockGitClient.GetCurrentHeadFunc = func() (string, error) {
+		return "abc123", nil
+	}
+	mockGitClient.GetAuthorFunc = func() (string, error) {
+		return "john.doe", nil
+	}
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		assert.Equal(t, []string{"-1", "abc123"}, args)
+		return []utils.LogEntry{{
+			Hash:      "abc123",
+			Author:    "john.doe",
+			Timestamp: time.Now(),
+			Message:   "feat: add feature",
+			Files:     []utils.FileChange{{Path: "main.go", Status: "A", Additions: 10}},
+		}}, nil
+	}
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 123)
+	require.NoError(t, err)
+	require.NoError(t, story1.Save())
+	require.NoError(t, story.SetCurrent(story1))
+
+	rootCmd := &cobra.Command{Use: "tracer"}
+	rootCmd.AddCommand(CommitCmd)
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"commit", "create", "--auto=false", "--type", "feat", "--message", "add feature"})
+	require.NoError(t, rootCmd.Execute())
+
+	loaded, err := story.LoadStory(story1.Filename)
+	require.NoError(t, err)
+	require.Len(t, loaded.Commits, 1)
+	assert.Equal(t, "abc123", loaded.Commits[0].Hash)
+	assert.Contains(t, loaded.Commits[0].Message, "add feature")
+	require.Len(t, loaded.Files, 1)
+	assert.Equal(t, "main.go", loaded.Files[0].Path)
+	assert.Equal(t, story.FileAdded, loaded.Files[0].Status)
+	assert.Equal(t, "abc123", loaded.Files[0].Commit)
+	assert.Equal(t, 10, loaded.Files[0].Additions)
+}
+
+func TestStoryListAndShowCommands(t *testing.T) {
+	tmpDir, _, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	now := time.Now()
+	story1 := &story.Story{
+		ID: "aaaa1111bbbb", Title: "Login page", Number: 1, Status: story.StatusInProgress,
+		Author: "john.doe", Tags: []string{"auth"}, JiraKey: "PROJ-1",
+		CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now,
+		Commits: []story.Commit{{Hash: "abc123def456", Message: "feat: add login\n\nbody", Author: "john.doe", Timestamp: now}},
+		Files:   []story.File{{Path: "login.go", Status: "M", Timestamp: now}},
+		Transitions: []story.Transition{
+			{From: story.StatusBlocked, To: story.StatusInProgress, By: "john.doe", At: now, Reason: "API ready"},
+		},
+	}
+	story2 := &story.Story{
+		ID: "cccc2222dddd", Title: "Logout", Number: 2, Status: story.StatusOpen,
+		Author: "jane.doe", CreatedAt: now.Add(-1 * time.Hour), UpdatedAt: now.Add(-1 * time.Hour),
+	}
+	require.NoError(t, story1.Save())
+	require.NoError(t, story2.Save())
+
+	runCmd := func(source *cobra.Command, args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  source.Use,
+			RunE: source.RunE,
+		}
+		if source == storyListCmd {
+			addStoryListFlags(cmd)
+		} else {
+			cmd.Flags().StringP("id", "i", "", "Story ID")
+		}
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	t.Run("list all", func(t *testing.T) {
+		output, err := runCmd(storyListCmd)
+		require.NoError(t, err)
+		assert.Contains(t, output, "NUMBER")
+		assert.Contains(t, output, "aaaa1111")
+		assert.NotContains(t, output, "aaaa1111bbbb")
+		assert.Contains(t, output, "PROJ-1")
+		assert.Less(t, strings.Index(output, "Logout"), strings.Index(output, "Login page"))
+	})
+
+	t.Run("list filtered by status", func(t *testing.T) {
+		output, err := runCmd(storyListCmd, "--status", "open")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Logout")
+		assert.NotContains(t, output, "Login page")
+	})
+
+	t.Run("list sorted and paged", func(t *testing.T) {
+		output, err := runCmd(storyListCmd, "--sort", "number", "--limit", "1")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Login page")
+		assert.NotContains(t, output, "Logout")
+		assert.Contains(t, output, "Showing 1-1 of 2 stories")
+	})
+
+	t.Run("list with no matches", func(t *testing.T) {
+		output, err := runCmd(storyListCmd, "--tag", "nothing")
+		require.NoError(t, err)
+		assert.Contains(t, output, "No stories found")
+	})
+
+	t.Run("list with invalid sort", func(t *testing.T) {
+		_, err := runCmd(storyListCmd, "--sort", "size")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid sort key: size")
+	})
+
+	t.Run("list with invalid date", func(t *testing.T) {
+		_, err := runCmd(storyListCmd, "--created-after", "yesterday")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid --created-after")
+	})
+
+	t.Run("show", func(t *testing.T) {
+		output, err := runCmd(storyShowCmd, "--id", "1")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Story: Login page")
+		assert.Contains(t, output, "ID: aaaa1111bbbb")
+		assert.Contains(t, output, "Tags: auth")
+		assert.Contains(t, output, "Jira: PROJ-1")
+		assert.Contains(t, output, "blocked -> in-progress by john.doe (API ready)")
+		assert.Contains(t, output, "abc123de")
+		assert.Contains(t, output, "feat: add login")
+		assert.NotContains(t, output, "body")
+		assert.Contains(t, output, "M  login.go")
+		assert.NotContains(t, output, "Jira URL")
+	})
+
+	t.Run("show with Jira host", func(t *testing.T) {
+		cfg, err := config.LoadConfig()
+		require.NoError(t, err)
+		cfg.JiraHost = "jira.example.com/"
+		require.NoError(t, config.SaveConfig(cfg))
+
+		output, err := runCmd(storyShowCmd, "--id", "1")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Jira URL: https://jira.example.com/browse/PROJ-1\n")
+	})
+
+	t.Run("show without story", func(t *testing.T) {
+		_, err := runCmd(storyShowCmd)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no story given and no current story")
+	})
+}
+
+func TestStoryEditCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	var renamed []string
+	mockGitClient.BranchExistsFunc = func(branchName string) (bool, error) {
+		return true, nil
+	}
+	mockGitClient.RenameBranchFunc = func(oldName, newName string) error {
+		renamed = append(renamed, oldName+" -> "+newName)
+		return nil
+	}
+
+	originalEditor := openEditor
+	defer func() {
+		openEditor = originalEditor
+	}()
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 1)
+	require.NoError(t, err)
+	story1.Tags = []string{"draft", "auth"}
+	require.NoError(t, story1.Save())
+
+	runEdit := func(input string, args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  "edit",
+			RunE: storyEditCmd.RunE,
+		}
+		cmd.Flags().StringP("id", "i", "", "Story ID")
+		cmd.Flags().StringP("title", "t", "", "Title")
+		cmd.Flags().StringP("description", "d", "", "Description")
+		cmd.Flags().IntP("number", "n", 0, "Number")
+		cmd.Flags().StringSliceP("tags", "g", []string{}, "Tags")
+		cmd.Flags().StringSlice("add-tag", []string{}, "Tags to add")
+		cmd.Flags().StringSlice("remove-tag", []string{}, "Tags to remove")
+		cmd.Flags().BoolP("editor", "e", false, "Editor")
+		cmd.Flags().Bool("rename-branch", false, "Rename branch")
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetArgs(append([]string{"--id", story1.ID}, args...))
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	load := func() *story.Story {
+		s, err := story.LoadStory(story1.Filename)
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("nothing to edit", func(t *testing.T) {
+		_, err := runEdit("")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nothing to edit")
+	})
+
+	t.Run("description and tags", func(t *testing.T) {
+		output, err := runEdit("", "--description", "New description", "--add-tag", "api", "--remove-tag", "draft")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Updated story")
+		s := load()
+		assert.Equal(t, "New description", s.Description)
+		assert.Equal(t, []string{"auth", "api"}, s.Tags)
+		assert.Empty(t, renamed)
+	})
+
+	t.Run("replace tags", func(t *testing.T) {
+		_, err := runEdit("", "--tags", "x,y")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"x", "y"}, load().Tags)
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		_, err := runEdit("", "--number", "0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "story number must be greater than 0")
+	})
+
+	t.Run("renumber declines branch rename", func(t *testing.T) {
+		renamed = nil
+		output, err := runEdit("n\n", "--number", "2")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Rename it to features/test-project-2-story-1? [y/N]")
+		assert.Contains(t, output, "left unchanged")
+		assert.Empty(t, renamed)
+		assert.Equal(t, 2, load().Number)
+	})
+
+	t.Run("renumber accepts branch rename", func(t *testing.T) {
+		renamed = nil
+		_, err := runEdit("y\n", "--number", "3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"features/test-project-2-story-1 -> features/test-project-3-story-1"}, renamed)
+	})
+
+	t.Run("retitle with rename flag", func(t *testing.T) {
+		renamed = nil
+		output, err := runEdit("", "--title", "Story One", "--rename-branch")
+		require.NoError(t, err)
+		assert.NotContains(t, output, "[y/N]")
+		assert.Equal(t, []string{"features/test-project-3-story-1 -> features/test-project-3-story-one"}, renamed)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		openEditor = func(path string) error {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			edited := strings.Replace(string(data), "title: Story One", "title: Edited In Editor", 1)
+			return os.WriteFile(path, []byte(edited), 0600)
+		}
+		_, err := runEdit("", "--editor", "--rename-branch=false")
+		require.NoError(t, err)
+		assert.Equal(t, "Edited In Editor", load().Title)
+	})
+
+	t.Run("editor rejects status change", func(t *testing.T) {
+		openEditor = func(path string) error {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			edited := strings.Replace(string(data), "status: open", "status: done", 1)
+			return os.WriteFile(path, []byte(edited), 0600)
+		}
+		_, err := runEdit("", "--editor")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field status cannot be edited")
+		assert.Equal(t, story.StatusOpen, load().Status)
+	})
+
+	t.Run("editor rejects unknown fields", func(t *testing.T) {
+		openEditor = func(path string) error {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			return os.WriteFile(path, append(data, []byte("priority: high\n")...), 0600)
+		}
+		_, err := runEdit("", "--editor")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field priority not found")
+	})
+
+	t.Run("editor without changes", func(t *testing.T) {
+		openEditor = func(path string) error {
+			return nil
+		}
+		_, err := runEdit("", "--editor")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no changes made")
+	})
+}
+
+func TestStoryNumbering(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+	require.NoError(t, configureProject("test-project"))
+	require.NoError(t, configureUser("john.doe"))
+
+	var renamed []string
+	mockGitClient.BranchExistsFunc = func(branchName string) (bool, error) {
+		return true, nil
+	}
+	mockGitClient.RenameBranchFunc = func(oldName, newName string) error {
+		renamed = append(renamed, oldName+" -> "+newName)
+		return nil
+	}
+
+	runNew := func(args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  "new",
+			RunE: storyNewCmd.RunE,
+		}
+		cmd.Flags().StringP("title", "t", "", "Story title")
+		cmd.Flags().StringP("description", "d", "", "Story description")
+		cmd.Flags().StringSliceP("tags", "g", []string{}, "Story tags")
+		cmd.Flags().IntP("number", "n", 0, "Story number")
+		cmd.Flags().StringP("jira", "j", "", "Jira key")
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	runRenumber := func(args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  "renumber",
+			RunE: storyRenumberCmd.RunE,
+		}
+		cmd.Flags().Bool("dry-run", false, "Dry run")
+		cmd.Flags().Bool("rename-branch", false, "Rename branch")
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	t.Run("allocates the next free number", func(t *testing.T) {
+		output, err := runNew("--title", "First")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Number: 1")
+
+		output, err = runNew("--title", "Second")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Number: 2")
+	})
+
+	t.Run("takes the number from the Jira key", func(t *testing.T) {
+		output, err := runNew("--title", "From Jira", "--jira", "PROJ-10")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Number: 10")
+		assert.Contains(t, output, "Jira: PROJ-10")
+
+		output, err = runNew("--title", "After Jira")
+		require.NoError(t, err)
+		assert.Contains(t, output, "Number: 11")
+	})
+
+	t.Run("rejects a number in use", func(t *testing.T) {
+		_, err := runNew("--title", "Duplicate", "--number", "2")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "story number 2 is already used by story")
+		assert.Contains(t, err.Error(), "Next free number is 12")
+	})
+
+	t.Run("renumber without collisions", func(t *testing.T) {
+		output, err := runRenumber()
+		require.NoError(t, err)
+		assert.Contains(t, output, "No duplicate story numbers found")
+	})
+
+	// Simulate a collision left behind by an older version of tracer
+	older, err := story.NewStoryWithNumber("Older Duplicate", "", "john.doe", 2)
+	require.NoError(t, err)
+	older.CreatedAt = time.Now().Add(time.Hour)
+	require.NoError(t, older.Save())
+
+	t.Run("renumber dry run", func(t *testing.T) {
+		output, err := runRenumber("--dry-run")
+		require.NoError(t, err)
+		assert.Contains(t, output, "2 -> 12")
+		assert.Contains(t, output, "Dry run, no stories changed")
+		s, err := story.LoadStory(older.Filename)
+		require.NoError(t, err)
+		assert.Equal(t, 2, s.Number)
+	})
+
+	t.Run("renumber", func(t *testing.T) {
+		renamed = nil
+		output, err := runRenumber("--rename-branch")
+		require.NoError(t, err)
+		assert.Contains(t, output, "2 -> 12")
+		assert.Equal(t, []string{"features/test-project-2-older-duplicate -> features/test-project-12-older-duplicate"}, renamed)
+		s, err := story.LoadStory(older.Filename)
+		require.NoError(t, err)
+		assert.Equal(t, 12, s.Number)
+	})
+}
+
+func TestStoryRebuildCommand(t *testing.T) {
+	tmpDir, mockGitClient, originalDir := setupTestEnvironment(t)
+	defer cleanupTestEnvironment(t, tmpDir, originalDir)
+
+	committed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	mockGitClient.BranchExistsFunc = func(branchName string) (bool, error) {
+		return true, nil
+	}
+	mockGitClient.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		if args[0] == "--all" {
+			return nil, nil
+		}
+		return []utils.LogEntry{{
+			Hash:      "abc123",
+			Author:    "jane.doe",
+			Timestamp: committed,
+			Message:   "feat: add feature\n\nMade with plain git commit",
+			Files: []utils.FileChange{
+				{Path: "main.go", Status: "A", Additions: 10},
+				{Path: "new.go", OldPath: "old.go", Status: "R", Additions: 1, Deletions: 2},
+			},
+		}}, nil
+	}
+
+	story1, err := story.NewStoryWithNumber("Story 1", "Description 1", "john.doe", 123)
+	require.NoError(t, err)
+	story1.Commits = []story.Commit{{Hash: "fff999", Message: "amended away"}}
+	require.NoError(t, story1.Save())
+
+	runCmd := func(source *cobra.Command, args ...string) (string, error) {
+		cmd := &cobra.Command{
+			Use:  source.Use,
+			RunE: source.RunE,
+		}
+		cmd.Flags().StringP("id", "i", "", storyRefUsage)
+		cmd.Flags().String("since", "", "Start time")
+		cmd.Flags().String("until", "", "End time")
+		var buf bytes.Buffer
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return buf.String(), err
+	}
+
+	output, err := runCmd(storyRebuildCmd, "--id", "123")
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 commit(s), 2 file change(s)")
+	assert.Contains(t, output, "Dropped fff999, not found in git: amended away")
+
+	loaded, err := story.LoadStory(story1.Filename)
+	require.NoError(t, err)
+	require.Len(t, loaded.Commits, 1)
+	assert.Equal(t, "abc123", loaded.Commits[0].Hash)
+	assert.Equal(t, "jane.doe", loaded.Commits[0].Author)
+	require.Len(t, loaded.Files, 2)
+	assert.Equal(t, story.FileRenamed, loaded.Files[1].Status)
+
+	// The views read the same history from git
+	output, err = runCmd(storyFilesCmd, "--id", "123")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Path: main.go\nStatus: added\nCommit: abc123\nLines: +10 -0\n")
+	assert.Contains(t, output, "Status: renamed (from old.go)")
+
+	output, err = runCmd(storyDiaryCmd, "--id", "123", "--since", committed.Add(-time.Minute).Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Contains(t, output, "Hash: abc123")
+	assert.Contains(t, output, "Path: new.go")
+	assert.Contains(t, output, "Lines: +1 -2")
 }
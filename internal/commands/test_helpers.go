@@ -70,10 +70,6 @@ func setupTestEnvironment(t *testing.T) (string, *utils.MockGit, string) {
 	err = os.Chdir(repoDir)
 	require.NoError(t, err)
 
-	// Initialize git repository
-	err = utils.RunGitInit()
-	require.NoError(t, err)
-
 	// Create config directory in temporary directory
 	configDir := filepath.Join(tmpDir, ".tracer")
 	err = os.MkdirAll(configDir, 0755)
@@ -104,6 +100,10 @@ func setupTestEnvironment(t *testing.T) (string, *utils.MockGit, string) {
 	// Set the mock git client as the global git client
 	utils.GitClient = mockGitClient
 
+	// Initialize git repository
+	err = utils.RunGitInit()
+	require.NoError(t, err)
+
 	return tmpDir, mockGitClient, originalDir
 }
 
@@ -4,7 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/helmedeiros/tracer-bullet/internal/diffplan"
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +19,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, DefaultGitBranch, cfg.GitBranch)
 	assert.Equal(t, DefaultGitRemote, cfg.GitRemote)
 	assert.Equal(t, DefaultStoryDir, cfg.StoryDir)
+	assert.Equal(t, DefaultStoryStore, cfg.StoryStore)
 	assert.Equal(t, DefaultPairFile, cfg.PairFile)
 }
 
@@ -152,6 +155,7 @@ func TestLoadConfig(t *testing.T) {
 				assert.Equal(t, DefaultGitBranch, cfg.GitBranch)
 				assert.Equal(t, DefaultGitRemote, cfg.GitRemote)
 				assert.Equal(t, DefaultStoryDir, cfg.StoryDir)
+				assert.Equal(t, DefaultStoryStore, cfg.StoryStore)
 				assert.Equal(t, DefaultPairFile, cfg.PairFile)
 			}
 		})
@@ -378,3 +382,133 @@ func TestRepositorySpecificConfig(t *testing.T) {
 	// Restore the real git client
 	utils.GitClient = utils.NewRealGit()
 }
+
+func TestMigrateYAML(t *testing.T) {
+	legacy := []byte("git_repo: my-project\ngit_branch: develop\nauthor_name: john.doe\n")
+
+	migrated, version, err := MigrateYAML(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigSchemaV1, version)
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal(migrated, &cfg))
+	assert.Equal(t, CurrentConfigSchemaVersion, cfg.SchemaVersion)
+	assert.Equal(t, "my-project", cfg.GitRepo)
+	assert.Equal(t, "develop", cfg.GitBranch)
+	assert.Equal(t, DefaultStoryStore, cfg.StoryStore)
+
+	// Current files are left untouched
+	again, version, err := MigrateYAML(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentConfigSchemaVersion, version)
+	assert.Equal(t, migrated, again)
+
+	_, _, err = MigrateYAML([]byte("schema_version: 99\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99")
+}
+
+func TestCommitConfig(t *testing.T) {
+	var empty CommitConfig
+	assert.Equal(t, DefaultCommitTypes, empty.AllowedTypes())
+	assert.Equal(t, []string{"feat", "fix", "docs", "style", "refactor", "test", "chore"}, empty.TypeNames())
+	assert.Equal(t, DefaultCommitMaxHeaderLength, empty.HeaderLimit())
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+commit:
+  types:
+    - name: perf
+      description: A performance improvement
+    - name: ci
+      description: Changes to the CI configuration
+  scopes: [api, core]
+  scopes_from_dirs: true
+  required_footers: [Signed-off-by]
+  require_story: true
+  max_header_length: 72
+`), &cfg))
+	assert.Equal(t, []string{"perf", "ci"}, cfg.Commit.TypeNames())
+	assert.Equal(t, []string{"api", "core"}, cfg.Commit.Scopes)
+	assert.True(t, cfg.Commit.ScopesFromDirs)
+	assert.Equal(t, []string{"Signed-off-by"}, cfg.Commit.RequiredFooters)
+	assert.True(t, cfg.Commit.RequireStory)
+	assert.Equal(t, 72, cfg.Commit.HeaderLimit())
+
+	// The section is left out of files that do not configure it
+	data, err := yaml.Marshal(DefaultConfig())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "commit:")
+}
+
+func TestChangelogConfig(t *testing.T) {
+	var empty ChangelogConfig
+	assert.Equal(t, DefaultChangelogFile, empty.OutputFile())
+	assert.Equal(t, DefaultChangelogSections, empty.TypeSections())
+	assert.Equal(t, DefaultChangelogBreakingTitle, empty.BreakingSectionTitle())
+
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+changelog:
+  file: docs/CHANGES.md
+  breaking_title: Breaking
+  sections:
+    - type: feat
+      title: New
+    - type: fix
+      title: Fixed
+`), &cfg))
+	assert.Equal(t, "docs/CHANGES.md", cfg.Changelog.OutputFile())
+	assert.Equal(t, "Breaking", cfg.Changelog.BreakingSectionTitle())
+	assert.Equal(t, []ChangelogSection{{Type: "feat", Title: "New"}, {Type: "fix", Title: "Fixed"}}, cfg.Changelog.TypeSections())
+
+	data, err := yaml.Marshal(DefaultConfig())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "changelog:")
+}
+
+func TestLLMConfig(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+llm:
+  provider: openai
+  endpoint: http://localhost:1234/v1/
+  model: qwen2.5-coder
+  temperature: 0
+  max_tokens: 300
+  timeout: 30s
+  api_key_env: LM_STUDIO_KEY
+`), &cfg))
+	assert.Equal(t, "openai", cfg.LLM.ProviderName())
+	assert.Equal(t, "http://localhost:1234/v1", cfg.LLM.BaseURL())
+	assert.Equal(t, "qwen2.5-coder", cfg.LLM.ModelName())
+	assert.Equal(t, 0.0, cfg.LLM.SamplingTemperature())
+	assert.Equal(t, 300, cfg.LLM.TokenLimit())
+	assert.Equal(t, 30*time.Second, cfg.LLM.RequestTimeout())
+	assert.Equal(t, "LM_STUDIO_KEY", cfg.LLM.APIKeyEnv)
+
+	data, err := yaml.Marshal(DefaultConfig())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "llm:")
+}
+
+func TestJiraIssueURL(t *testing.T) {
+	assert.Equal(t, "https://jira.example.com/browse/PROJ-7", JiraIssueURL("jira.example.com", "PROJ-7"))
+	assert.Equal(t, "https://jira.example.com/browse/PROJ-7", JiraIssueURL("https://jira.example.com/", "PROJ-7"))
+	assert.Equal(t, "http://localhost:8080/browse/PROJ-7", JiraIssueURL("http://localhost:8080", "PROJ-7"))
+}
+
+func TestPromptConfig(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+prompt:
+  context_tokens: 1500
+  exclude: ["*.snap", assets/]
+`), &cfg))
+	assert.Equal(t, diffplan.Options{Budget: 1500, Exclude: []string{"*.snap", "assets/"}}, cfg.Prompt.PlanOptions())
+	assert.Equal(t, diffplan.Options{}, PromptConfig{}.PlanOptions())
+
+	data, err := yaml.Marshal(DefaultConfig())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "prompt:")
+}
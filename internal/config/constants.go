@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/helmedeiros/tracer-bullet/internal/diffplan"
+	"github.com/helmedeiros/tracer-bullet/internal/llm"
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
 	"gopkg.in/yaml.v3"
 )
@@ -22,8 +25,19 @@ const (
 	DefaultStoryDir = "stories"
 	DefaultStoryExt = ".yaml"
 
+	// Story storage backends
+	StoryStoreYAML     = "yaml" // One YAML file per story in the stories directory
+	StoryStoreDB       = "db"   // All stories in a single database file
+	DefaultStoryStore  = StoryStoreYAML
+	DefaultStoryDBFile = "stories.db"
+
 	// Pair programming related constants
 	DefaultPairFile = "pair.json"
+	DefaultTeamFile = "team.yaml" // Team roster with the names and emails of teammates
+
+	// Mob programming related constants
+	DefaultMobFile        = "mob.json"          // Running mob session
+	DefaultMobJournalFile = "mob-journal.jsonl" // Rotations and commits of all mob sessions
 
 	// Jira related constants
 	DefaultJiraHost      = "" // Must be configured by user
@@ -31,29 +45,182 @@ const (
 	DefaultJiraIssueType = "Story"
 )
 
+// Configuration file schema versions
+const (
+	// ConfigSchemaV1 is the layout written before the configuration had a schema version
+	ConfigSchemaV1 = 1
+	// ConfigSchemaV2 records the schema_version and the story_store
+	ConfigSchemaV2 = 2
+
+	// CurrentConfigSchemaVersion is the version written by this version of tracer
+	CurrentConfigSchemaVersion = ConfigSchemaV2
+)
+
 // Config represents the application configuration
 type Config struct {
-	GitRepo     string `yaml:"git_repo"`
-	GitBranch   string `yaml:"git_branch"`
-	GitRemote   string `yaml:"git_remote"`
-	StoryDir    string `yaml:"story_dir"`
-	PairFile    string `yaml:"pair_file"`
-	AuthorName  string `yaml:"author_name"`
-	AuthorEmail string `yaml:"author_email"`
-	PairName    string `yaml:"pair_name"`
-	JiraHost    string `yaml:"jira_host"`
-	JiraToken   string `yaml:"jira_token"`
-	JiraProject string `yaml:"jira_project"`
-	JiraUser    string `yaml:"jira_user"`
+	SchemaVersion int             `yaml:"schema_version"`
+	GitRepo       string          `yaml:"git_repo"`
+	GitBranch     string          `yaml:"git_branch"`
+	GitRemote     string          `yaml:"git_remote"`
+	StoryDir      string          `yaml:"story_dir"`
+	StoryStore    string          `yaml:"story_store"`
+	PairFile      string          `yaml:"pair_file"`
+	TeamFile      string          `yaml:"team_file"`
+	AuthorName    string          `yaml:"author_name"`
+	AuthorEmail   string          `yaml:"author_email"`
+	PairName      string          `yaml:"pair_name"`
+	JiraHost      string          `yaml:"jira_host"`
+	JiraToken     string          `yaml:"jira_token"`
+	JiraProject   string          `yaml:"jira_project"`
+	JiraUser      string          `yaml:"jira_user"`
+	Commit        CommitConfig    `yaml:"commit,omitempty"`
+	Changelog     ChangelogConfig `yaml:"changelog,omitempty"`
+	LLM           llm.Config      `yaml:"llm,omitempty"`
+	Prompt        PromptConfig    `yaml:"prompt,omitempty"`
+}
+
+// JiraIssueURL returns the address of the Jira issue with the key on the
+// host. Hosts without a scheme are reached over https.
+func JiraIssueURL(host, key string) string {
+	host = strings.TrimSuffix(host, "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return fmt.Sprintf("%s/browse/%s", host, key)
+}
+
+// DefaultCommitMaxHeaderLength is the longest commit header allowed unless configured otherwise
+const DefaultCommitMaxHeaderLength = 100
+
+// CommitType is a type of commit, such as feat or fix, with what it is used for
+type CommitType struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// DefaultCommitTypes are the commit types allowed unless configured otherwise
+var DefaultCommitTypes = []CommitType{
+	{Name: "feat", Description: "A new feature"},
+	{Name: "fix", Description: "A bug fix"},
+	{Name: "docs", Description: "Documentation only changes"},
+	{Name: "style", Description: "Changes that don't affect code meaning"},
+	{Name: "refactor", Description: "Code changes (no fixes/features)"},
+	{Name: "test", Description: "Changes to tests"},
+	{Name: "chore", Description: "Changes to build process/tools"},
+}
+
+// CommitConfig is the commit message policy of a repository, applied when
+// creating, generating and linting commit messages
+type CommitConfig struct {
+	Types           []CommitType `yaml:"types,omitempty"`            // Allowed types, DefaultCommitTypes when empty
+	Scopes          []string     `yaml:"scopes,omitempty"`           // Allowed scopes, any scope when empty
+	ScopesFromDirs  bool         `yaml:"scopes_from_dirs,omitempty"` // Also allow the top-level directories of the repository as scopes
+	RequiredFooters []string     `yaml:"required_footers,omitempty"` // Footers every message must have, such as Signed-off-by
+	RequireStory    bool         `yaml:"require_story,omitempty"`    // Require a reference to a story
+	MaxHeaderLength int          `yaml:"max_header_length,omitempty"`
+}
+
+// AllowedTypes returns the configured commit types, or the default ones
+func (c CommitConfig) AllowedTypes() []CommitType {
+	if len(c.Types) == 0 {
+		return DefaultCommitTypes
+	}
+	return c.Types
+}
+
+// TypeNames returns the names of the allowed commit types
+func (c CommitConfig) TypeNames() []string {
+	types := c.AllowedTypes()
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// HeaderLimit returns the configured maximum header length, or the default one
+func (c CommitConfig) HeaderLimit() int {
+	if c.MaxHeaderLength <= 0 {
+		return DefaultCommitMaxHeaderLength
+	}
+	return c.MaxHeaderLength
+}
+
+// DefaultChangelogFile is the file changelogs are written to unless configured otherwise
+const DefaultChangelogFile = "CHANGELOG.md"
+
+// DefaultChangelogBreakingTitle is the title of the breaking changes section
+// unless configured otherwise
+const DefaultChangelogBreakingTitle = "BREAKING CHANGES"
+
+// ChangelogSection is a section of the changelog and the commit type it lists
+type ChangelogSection struct {
+	Type  string `yaml:"type"`
+	Title string `yaml:"title"`
+}
+
+// DefaultChangelogSections are the sections of the changelog unless configured otherwise
+var DefaultChangelogSections = []ChangelogSection{
+	{Type: "feat", Title: "Features"},
+	{Type: "fix", Title: "Bug Fixes"},
+	{Type: "refactor", Title: "Code Refactoring"},
+	{Type: "docs", Title: "Documentation"},
+}
+
+// PromptConfig is which of the changes are put in the prompt of a commit
+// message generator
+type PromptConfig struct {
+	ContextTokens int      `yaml:"context_tokens,omitempty"` // Tokens of changes put in the prompt, diffplan.DefaultBudget when 0
+	Exclude       []string `yaml:"exclude,omitempty"`        // Files left out besides lockfiles, vendored and generated code
+}
+
+// PlanOptions returns the options of the planner fitting the changes into the prompt
+func (c PromptConfig) PlanOptions() diffplan.Options {
+	return diffplan.Options{Budget: c.ContextTokens, Exclude: c.Exclude}
+}
+
+// ChangelogConfig is how changelogs are generated from the commit history
+type ChangelogConfig struct {
+	File          string             `yaml:"file,omitempty"`           // File the changelog is prepended to, DefaultChangelogFile when empty
+	Sections      []ChangelogSection `yaml:"sections,omitempty"`       // Sections in order, DefaultChangelogSections when empty. Other types are left out.
+	BreakingTitle string             `yaml:"breaking_title,omitempty"` // Title of the breaking changes section
+}
+
+// OutputFile returns the configured changelog file, or the default one
+func (c ChangelogConfig) OutputFile() string {
+	if c.File == "" {
+		return DefaultChangelogFile
+	}
+	return c.File
+}
+
+// TypeSections returns the configured sections, or the default ones
+func (c ChangelogConfig) TypeSections() []ChangelogSection {
+	if len(c.Sections) == 0 {
+		return DefaultChangelogSections
+	}
+	return c.Sections
+}
+
+// BreakingSectionTitle returns the configured title of the breaking changes
+// section, or the default one
+func (c ChangelogConfig) BreakingSectionTitle() string {
+	if c.BreakingTitle == "" {
+		return DefaultChangelogBreakingTitle
+	}
+	return c.BreakingTitle
 }
 
 // DefaultConfig returns a new Config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		GitBranch: DefaultGitBranch,
-		GitRemote: DefaultGitRemote,
-		StoryDir:  DefaultStoryDir,
-		PairFile:  DefaultPairFile,
+		SchemaVersion: CurrentConfigSchemaVersion,
+		GitBranch:     DefaultGitBranch,
+		GitRemote:     DefaultGitRemote,
+		StoryDir:      DefaultStoryDir,
+		StoryStore:    DefaultStoryStore,
+		PairFile:      DefaultPairFile,
+		TeamFile:      DefaultTeamFile,
 	}
 }
 
@@ -68,9 +235,15 @@ func setDefaultValues(cfg *Config) {
 	if cfg.StoryDir == "" {
 		cfg.StoryDir = DefaultStoryDir
 	}
+	if cfg.StoryStore == "" {
+		cfg.StoryStore = DefaultStoryStore
+	}
 	if cfg.PairFile == "" {
 		cfg.PairFile = DefaultPairFile
 	}
+	if cfg.TeamFile == "" {
+		cfg.TeamFile = DefaultTeamFile
+	}
 }
 
 // loadConfigFromFile loads and unmarshals config from the given file path
@@ -84,11 +257,79 @@ func loadConfigFromFile(configFile string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	if cfg.SchemaVersion > CurrentConfigSchemaVersion {
+		return nil, fmt.Errorf("config %s has schema version %d, this version of tracer supports up to %d. Please upgrade tracer",
+			configFile, cfg.SchemaVersion, CurrentConfigSchemaVersion)
+	}
 
 	setDefaultValues(&cfg)
 	return &cfg, nil
 }
 
+// MigrateYAML rewrites a configuration file to the current schema version. It
+// returns the rewritten file and the version the file was in; files that are
+// already current are returned unchanged.
+func MigrateYAML(data []byte) ([]byte, int, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	version := cfg.SchemaVersion
+	if version == 0 {
+		version = ConfigSchemaV1
+	}
+	if version > CurrentConfigSchemaVersion {
+		return nil, 0, fmt.Errorf("config has schema version %d, this version of tracer supports up to %d. Please upgrade tracer",
+			version, CurrentConfigSchemaVersion)
+	}
+	if version == CurrentConfigSchemaVersion {
+		return data, version, nil
+	}
+
+	setDefaultValues(&cfg)
+	cfg.SchemaVersion = CurrentConfigSchemaVersion
+	migrated, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return migrated, version, nil
+}
+
+// ConfigFiles returns the paths of the repository and global configuration
+// files that exist
+func ConfigFiles() ([]string, error) {
+	var paths []string
+
+	if repoConfigDir, err := utils.GetRepoConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(repoConfigDir, DefaultConfigFile))
+	}
+
+	globalConfigDir, err := utils.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, filepath.Join(globalConfigDir, DefaultConfigFile))
+
+	var existing []string
+	for _, path := range paths {
+		if utils.FileExists(path) && !containsPath(existing, path) {
+			existing = append(existing, path)
+		}
+	}
+	return existing, nil
+}
+
+// containsPath reports whether the path is already in the list
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadConfig loads the configuration from the config file
 func LoadConfig() (*Config, error) {
 	// Try to get repository-specific config first
@@ -124,6 +365,8 @@ func LoadConfig() (*Config, error) {
 
 // SaveConfig saves the configuration to the config file
 func SaveConfig(cfg *Config) error {
+	cfg.SchemaVersion = CurrentConfigSchemaVersion
+
 	// Try to save to repository-specific config first
 	repoConfigDir, err := utils.GetRepoConfigDir()
 	if err == nil {
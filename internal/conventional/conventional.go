@@ -0,0 +1,161 @@
+// Package conventional parses and lints commit messages written in the
+// conventional commits format: <type>(<scope>)!: <description>, followed by
+// an optional body and footers.
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BreakingChange is the footer token that marks a breaking change
+const BreakingChange = "BREAKING CHANGE"
+
+// Footer is a footer of a commit message, such as "Refs: PROJ-12" or a git trailer
+type Footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+// Message is a parsed commit message
+type Message struct {
+	Header      string   `json:"header"`
+	Type        string   `json:"type"`
+	Scope       string   `json:"scope,omitempty"`
+	Breaking    bool     `json:"breaking"`
+	Description string   `json:"description"`
+	Body        string   `json:"body,omitempty"`
+	Footers     []Footer `json:"footers,omitempty"`
+}
+
+// headerPattern matches a conventional commit header
+var headerPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*)(?:\(([^()]*)\))?(!)?:(?: (.*))?$`)
+
+// footerPattern matches the first line of a footer: "Token: value" or "Token #value".
+// Tokens use - for spaces, except for BREAKING CHANGE.
+var footerPattern = regexp.MustCompile(`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*)(?:: | #)(.*)$`)
+
+// generatedPrefixes start the headers of messages written by git itself
+var generatedPrefixes = []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "}
+
+// Clean returns the part of a commit message file that git keeps: the lines
+// above the scissors line of verbose commits, without comments and without
+// leading or trailing blank lines
+func Clean(message string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "#") {
+			if strings.Contains(line, ">8") {
+				break
+			}
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Header returns the first line of a cleaned commit message
+func Header(message string) string {
+	header, _, _ := strings.Cut(Clean(message), "\n")
+	return header
+}
+
+// IsGenerated reports whether the message was written by git, as for merges,
+// reverts and fixups, rather than by the author
+func IsGenerated(message string) bool {
+	header := Header(message)
+	for _, prefix := range generatedPrefixes {
+		if strings.HasPrefix(header, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a commit message. Comments and the verbose diff git adds for
+// the editor are ignored.
+func Parse(message string) (*Message, error) {
+	lines := strings.Split(Clean(message), "\n")
+	header := lines[0]
+	if header == "" {
+		return nil, fmt.Errorf("commit message is empty")
+	}
+
+	match := headerPattern.FindStringSubmatch(header)
+	if match == nil {
+		return nil, fmt.Errorf("header %q does not follow the format <type>(<scope>): <description>", header)
+	}
+
+	msg := &Message{
+		Header:      header,
+		Type:        match[1],
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: strings.TrimSpace(match[4]),
+	}
+
+	// Split the rest of the message into paragraphs
+	var paragraphs [][]string
+	var current []string
+	for _, line := range lines[1:] {
+		if line == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, current)
+	}
+
+	// Footers make up the last paragraph, which starts with a footer line
+	if n := len(paragraphs); n > 0 && footerPattern.MatchString(paragraphs[n-1][0]) {
+		msg.Footers = parseFooters(paragraphs[n-1])
+		paragraphs = paragraphs[:n-1]
+	}
+
+	body := make([]string, 0, len(paragraphs))
+	for _, p := range paragraphs {
+		body = append(body, strings.Join(p, "\n"))
+	}
+	msg.Body = strings.Join(body, "\n\n")
+
+	for _, f := range msg.Footers {
+		if f.Token == BreakingChange || f.Token == "BREAKING-CHANGE" {
+			msg.Breaking = true
+		}
+	}
+
+	return msg, nil
+}
+
+// parseFooters parses the footer lines of a message. Lines that do not start
+// a new footer continue the value of the previous one.
+func parseFooters(lines []string) []Footer {
+	var footers []Footer
+	for _, line := range lines {
+		if match := footerPattern.FindStringSubmatch(line); match != nil {
+			footers = append(footers, Footer{Token: match[1], Value: match[2]})
+			continue
+		}
+		last := &footers[len(footers)-1]
+		last.Value += "\n" + line
+	}
+	return footers
+}
+
+// Footer returns the value of the first footer with the token, compared
+// case-insensitively, and whether there is one
+func (m *Message) Footer(token string) (string, bool) {
+	for _, f := range m.Footers {
+		if strings.EqualFold(f.Token, token) {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,122 @@
+package conventional
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected *Message
+	}{
+		{
+			name:    "header only",
+			message: "feat: add export\n",
+			expected: &Message{
+				Header: "feat: add export", Type: "feat", Description: "add export",
+			},
+		},
+		{
+			name:    "scope and breaking mark",
+			message: "fix(api)!: drop the v1 endpoints",
+			expected: &Message{
+				Header: "fix(api)!: drop the v1 endpoints", Type: "fix", Scope: "api", Breaking: true,
+				Description: "drop the v1 endpoints",
+			},
+		},
+		{
+			name:    "body and footers",
+			message: "feat(story): add export\n\nFirst paragraph\nwraps.\n\nSecond paragraph.\n\nRefs #12\nBREAKING CHANGE: the export format\n  is now YAML\nStory: PROJ-7\n",
+			expected: &Message{
+				Header: "feat(story): add export", Type: "feat", Scope: "story", Breaking: true,
+				Description: "add export",
+				Body:        "First paragraph\nwraps.\n\nSecond paragraph.",
+				Footers: []Footer{
+					{Token: "Refs", Value: "12"},
+					{Token: "BREAKING CHANGE", Value: "the export format\n  is now YAML"},
+					{Token: "Story", Value: "PROJ-7"},
+				},
+			},
+		},
+		{
+			name:    "comments and verbose diff",
+			message: "chore: tidy\n\n# Please enter the commit message\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n",
+			expected: &Message{
+				Header: "chore: tidy", Type: "chore", Description: "tidy",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse(tt.message)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse("# only comments\n\n")
+	assert.EqualError(t, err, "commit message is empty")
+
+	_, err = Parse("added export")
+	assert.EqualError(t, err, `header "added export" does not follow the format <type>(<scope>): <description>`)
+
+	_, err = Parse("feat:add export")
+	assert.Error(t, err)
+}
+
+func TestLint(t *testing.T) {
+	rules := Rules{
+		Types:           []string{"feat", "fix", "docs", "style", "refactor", "test", "chore"},
+		Scopes:          []string{"api", "story"},
+		MaxHeaderLength: 30,
+		RequireStory:    true,
+	}
+
+	rulesOf := func(problems []Problem) []string {
+		var result []string
+		for _, p := range problems {
+			result = append(result, p.Rule)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		expected []string
+	}{
+		{name: "valid", message: "feat(api): add export\n\nStory: PROJ-7"},
+		{name: "jira key in the header", message: "fix: PROJ-7 handle timeouts"},
+		{name: "merge commit", message: "Merge branch 'main' into feature/login"},
+		{name: "invalid header", message: "added export", expected: []string{RuleHeaderFormat}},
+		{name: "unknown type and scope", message: "feature(ui): add export\n\nRefs: PROJ-7", expected: []string{RuleTypeEnum, RuleScopeEnum}},
+		{name: "long header without story", message: "feat: add the export of stories to YAML files", expected: []string{RuleHeaderMaxLength, RuleStoryReference}},
+		{name: "empty description", message: "feat:  \n\nStory: PROJ-7", expected: []string{RuleDescriptionEmpty}},
+		{name: "body without blank line", message: "feat: add export\nmore detail\n\nStory: PROJ-7", expected: []string{RuleBodyLeadingBlank}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := Lint(tt.message, rules)
+			assert.Equal(t, tt.expected, rulesOf(problems))
+		})
+	}
+
+	// Any type and scope when none are configured
+	_, problems := Lint("build(ci): cache modules", Rules{})
+	assert.Empty(t, problems)
+
+	// Required footers
+	footers := Rules{RequiredFooters: []string{"Signed-off-by"}}
+	_, problems = Lint("build: cache modules\n\nsigned-off-by: John <john@example.com>", footers)
+	assert.Empty(t, problems)
+	_, problems = Lint("build: cache modules", footers)
+	assert.Equal(t, []string{RuleFooterRequired}, rulesOf(problems))
+}
@@ -0,0 +1,100 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Lint rules, as reported in problems
+const (
+	RuleHeaderFormat     = "header-format"
+	RuleTypeEnum         = "type-enum"
+	RuleScopeEnum        = "scope-enum"
+	RuleDescriptionEmpty = "description-empty"
+	RuleHeaderMaxLength  = "header-max-length"
+	RuleBodyLeadingBlank = "body-leading-blank"
+	RuleFooterRequired   = "footer-required"
+	RuleStoryReference   = "story-reference"
+)
+
+// storyFooters are the footers that reference a story
+var storyFooters = []string{"Story", "Refs", "Jira"}
+
+// jiraKeyPattern matches a Jira issue key such as PROJ-12
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[0-9]+\b`)
+
+// Rules configures Lint
+type Rules struct {
+	Types           []string // Allowed types, any type when empty
+	Scopes          []string // Allowed scopes, any scope when empty
+	MaxHeaderLength int      // Longest header allowed, no limit when 0
+	RequiredFooters []string // Footers every message must have
+	RequireStory    bool     // Require a Story, Refs or Jira footer or a Jira key
+}
+
+// Problem is a rule a commit message breaks
+type Problem struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Rule, p.Message)
+}
+
+// Lint checks a commit message against the rules. It returns the parsed
+// message, nil when the header cannot be parsed, and the problems found.
+// Messages written by git, such as merges, are not checked.
+func Lint(message string, rules Rules) (*Message, []Problem) {
+	if IsGenerated(message) {
+		return nil, nil
+	}
+
+	msg, err := Parse(message)
+	if err != nil {
+		return nil, []Problem{{Rule: RuleHeaderFormat, Message: err.Error()}}
+	}
+
+	var problems []Problem
+	add := func(rule, format string, args ...any) {
+		problems = append(problems, Problem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(rules.Types) > 0 && !slices.Contains(rules.Types, msg.Type) {
+		add(RuleTypeEnum, "type %q is not allowed. Must be one of: %s", msg.Type, strings.Join(rules.Types, ", "))
+	}
+	if msg.Scope != "" && len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, msg.Scope) {
+		add(RuleScopeEnum, "scope %q is not allowed. Must be one of: %s", msg.Scope, strings.Join(rules.Scopes, ", "))
+	}
+	if msg.Description == "" {
+		add(RuleDescriptionEmpty, "description is empty")
+	}
+	if rules.MaxHeaderLength > 0 && len([]rune(msg.Header)) > rules.MaxHeaderLength {
+		add(RuleHeaderMaxLength, "header is %d characters long, the limit is %d", len([]rune(msg.Header)), rules.MaxHeaderLength)
+	}
+	if _, rest, ok := strings.Cut(Clean(message), "\n"); ok && !strings.HasPrefix(rest, "\n") {
+		add(RuleBodyLeadingBlank, "body must be separated from the header by a blank line")
+	}
+	for _, token := range rules.RequiredFooters {
+		if _, ok := msg.Footer(token); !ok {
+			add(RuleFooterRequired, "footer %q is missing", token)
+		}
+	}
+	if rules.RequireStory && !hasStoryReference(msg) {
+		add(RuleStoryReference, "no story reference. Add a footer such as \"Story: PROJ-12\"")
+	}
+
+	return msg, problems
+}
+
+// hasStoryReference reports whether the message references a story
+func hasStoryReference(msg *Message) bool {
+	for _, token := range storyFooters {
+		if value, ok := msg.Footer(token); ok && strings.TrimSpace(value) != "" {
+			return true
+		}
+	}
+	return jiraKeyPattern.MatchString(msg.Header) || jiraKeyPattern.MatchString(msg.Body)
+}
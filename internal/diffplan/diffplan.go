@@ -0,0 +1,353 @@
+// Package diffplan fits the changes of a commit into the context of a
+// language model. Files are ranked by significance and share a token budget:
+// large diffs are truncated to their first hunks, files the budget cannot
+// cover are summarized by their stats, and binaries, lockfiles, vendored and
+// generated code are left out.
+package diffplan
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// CharsPerToken is how many characters of code a token stands for on
+// average. Tokens are estimated from it, without a tokenizer.
+const CharsPerToken = 4
+
+// DefaultBudget is the number of tokens of changes put in a prompt unless
+// configured otherwise
+const DefaultBudget = 3000
+
+// minFileTokens is the smallest share of the budget worth giving a file.
+// Files that would get less are summarized by their stats.
+const minFileTokens = 48
+
+// DefaultExclude are the files always left out: dependencies, lockfiles and
+// generated code. A pattern ending in / matches a directory at any depth, a
+// pattern with a / matches the path, and others match the file name.
+var DefaultExclude = []string{
+	"vendor/", "node_modules/", "dist/",
+	"go.sum", "package-lock.json", "pnpm-lock.yaml", "*.lock",
+	"*.min.js", "*.min.css", "*.map",
+	"*.pb.go", "*_generated.go", "*.gen.go",
+}
+
+// Statuses of a file in a plan
+const (
+	StatusFull      = "full"      // The whole diff is in the prompt
+	StatusTruncated = "truncated" // The first hunks are in the prompt
+	StatusSummary   = "summary"   // Only the stats are in the prompt, for lack of budget
+	StatusSkipped   = "skipped"   // Left out, see the reason
+)
+
+// Reasons a file is skipped
+const (
+	ReasonBinary    = "binary"
+	ReasonExcluded  = "excluded"
+	ReasonGenerated = "generated"
+	ReasonEmpty     = "no changes"
+)
+
+// Change is the change of one file
+type Change struct {
+	Path string
+	Diff string // Unified diff of the file, or the content of a new file
+	New  bool   // Diff is the content of a file git does not track yet
+}
+
+// Stats counts the changes of a file
+type Stats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Hunks   int `json:"hunks"`
+}
+
+// File is a changed file as planned for the prompt
+type File struct {
+	Path    string `json:"path"`
+	New     bool   `json:"new,omitempty"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"` // Why the file is skipped
+	Stats   Stats  `json:"stats"`
+	Text    string `json:"-"`                 // The hunks put in the prompt
+	Omitted int    `json:"omitted,omitempty"` // Lines of the diff left out
+}
+
+// Plan is the files of a change set, most significant first, and what of
+// them fits the budget
+type Plan struct {
+	Budget int    `json:"budget"`
+	Tokens int    `json:"tokens"` // Estimated tokens of the text put in the prompt
+	Files  []File `json:"files"`
+}
+
+// Options configure the planner
+type Options struct {
+	Budget  int      // Tokens of changes, DefaultBudget when 0
+	Exclude []string // Patterns of files left out besides DefaultExclude
+}
+
+// EstimateTokens estimates the number of tokens of a text
+func EstimateTokens(text string) int {
+	return (len(text) + CharsPerToken - 1) / CharsPerToken
+}
+
+// New plans which of the changes go in the prompt, and how much of each
+func New(changes []Change, opts Options) *Plan {
+	budget := opts.Budget
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+	exclude := append(slices.Clone(DefaultExclude), opts.Exclude...)
+
+	plan := &Plan{Budget: budget}
+	var hunks [][]string
+	index := make(map[string]int)
+	for _, c := range changes {
+		f, h := parse(c)
+		// Several diffs of a file, such as staged and unstaged ones, are
+		// parsed on their own and their hunks merged
+		if i, ok := index[c.Path]; ok {
+			merge(&plan.Files[i], f)
+			hunks[i] = append(hunks[i], h...)
+			continue
+		}
+		index[c.Path] = len(plan.Files)
+		plan.Files = append(plan.Files, f)
+		hunks = append(hunks, h)
+	}
+
+	for i := range plan.Files {
+		f := &plan.Files[i]
+		switch {
+		case f.Reason != "":
+		case Excluded(f.Path, exclude):
+			f.Reason = ReasonExcluded
+		case isGenerated(hunks[i]):
+			f.Reason = ReasonGenerated
+		case len(hunks[i]) == 0:
+			f.Reason = ReasonEmpty
+		}
+		if f.Reason != "" {
+			f.Status = StatusSkipped
+		}
+	}
+
+	order := make([]int, len(plan.Files))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return compareSignificance(plan.Files[a], plan.Files[b])
+	})
+
+	// The most significant files get a share of the budget, as many as can
+	// get a useful one
+	var shown []int
+	for _, i := range order {
+		f := &plan.Files[i]
+		if f.Status == StatusSkipped {
+			continue
+		}
+		if (len(shown)+1)*minFileTokens > budget {
+			f.Status = StatusSummary
+			f.Omitted = lineCount(hunks[i])
+			continue
+		}
+		shown = append(shown, i)
+	}
+
+	// Smaller diffs are fitted first, so the budget they leave goes to the
+	// larger ones
+	need := func(i int) int { return EstimateTokens(strings.Join(hunks[i], "\n")) }
+	slices.SortStableFunc(shown, func(a, b int) int { return need(a) - need(b) })
+	remaining := budget
+	for n, i := range shown {
+		share := remaining / (len(shown) - n)
+		f := &plan.Files[i]
+		f.Text, f.Omitted = fit(hunks[i], share)
+		f.Status = StatusFull
+		if f.Omitted > 0 {
+			f.Status = StatusTruncated
+		}
+		used := EstimateTokens(f.Text)
+		remaining -= used
+		plan.Tokens += used
+	}
+
+	files := make([]File, 0, len(order))
+	for _, i := range order {
+		files = append(files, plan.Files[i])
+	}
+	plan.Files = files
+	return plan
+}
+
+// merge adds another diff of the same file to f
+func merge(f *File, other File) {
+	f.New = f.New || other.New
+	if f.Reason == "" {
+		f.Reason = other.Reason
+	}
+	f.Stats.Added += other.Stats.Added
+	f.Stats.Removed += other.Stats.Removed
+	f.Stats.Hunks += other.Stats.Hunks
+}
+
+// parse splits the change into hunks, leaving out the git headers, and
+// counts its stats
+func parse(c Change) (File, []string) {
+	f := File{Path: c.Path, New: c.New}
+	if strings.ContainsRune(c.Diff, 0) {
+		f.Reason = ReasonBinary
+		return f, nil
+	}
+
+	var lines []string
+	if c.New {
+		content := strings.TrimSuffix(c.Diff, "\n")
+		if content != "" {
+			lines = strings.Split(content, "\n")
+			for i, line := range lines {
+				lines[i] = "+" + line
+			}
+			lines = append([]string{fmt.Sprintf("@@ -0,0 +1,%d @@", len(lines))}, lines...)
+		}
+	} else {
+		lines = strings.Split(strings.TrimSuffix(c.Diff, "\n"), "\n")
+	}
+
+	var hunks []string
+	var hunk []string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			f.Reason = ReasonBinary
+			return f, nil
+		case strings.HasPrefix(line, "@@"):
+			if len(hunk) > 0 {
+				hunks = append(hunks, strings.Join(hunk, "\n"))
+			}
+			hunk = []string{line}
+			f.Stats.Hunks++
+		case hunk == nil:
+			// Headers before the first hunk: diff --git, index, --- and +++
+		default:
+			hunk = append(hunk, line)
+			if strings.HasPrefix(line, "+") {
+				f.Stats.Added++
+			} else if strings.HasPrefix(line, "-") {
+				f.Stats.Removed++
+			}
+		}
+	}
+	if len(hunk) > 0 {
+		hunks = append(hunks, strings.Join(hunk, "\n"))
+	}
+	return f, hunks
+}
+
+// fit returns the hunks that fit the tokens, whole hunks first, and the
+// number of lines left out. When not even the first hunk fits, its first
+// lines are kept.
+func fit(hunks []string, tokens int) (string, int) {
+	var b strings.Builder
+	for i, hunk := range hunks {
+		if EstimateTokens(b.String()+hunk+"\n") <= tokens {
+			b.WriteString(hunk + "\n")
+			continue
+		}
+		if i == 0 {
+			for _, line := range strings.Split(hunk, "\n") {
+				if b.Len() > 0 && EstimateTokens(b.String()+line+"\n") > tokens {
+					break
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+		shown := strings.Count(b.String(), "\n")
+		omitted := lineCount(hunks) - shown
+		b.WriteString(fmt.Sprintf("... %d more lines not shown\n", omitted))
+		return b.String(), omitted
+	}
+	return b.String(), 0
+}
+
+// lineCount returns the number of lines of the hunks
+func lineCount(hunks []string) int {
+	n := 0
+	for _, hunk := range hunks {
+		n += strings.Count(hunk, "\n") + 1
+	}
+	return n
+}
+
+// Excluded reports whether the path matches one of the patterns
+func Excluded(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			if strings.HasPrefix(file, dir+"/") || strings.Contains(file, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+		name := path.Base(file)
+		if strings.Contains(pattern, "/") {
+			name = file
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isGenerated reports whether the diff adds the marker of generated Go code
+// and other tools that follow its convention
+func isGenerated(hunks []string) bool {
+	for _, hunk := range hunks {
+		for _, line := range strings.SplitN(hunk, "\n", 12) {
+			if strings.Contains(line, "Code generated") && strings.Contains(line, "DO NOT EDIT") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// weight ranks kinds of files: code over tests over documentation and data
+func weight(file string) int {
+	name := strings.ToLower(path.Base(file))
+	switch {
+	case strings.Contains(name, "_test.") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		strings.HasPrefix(file, "test/") || strings.HasPrefix(file, "tests/") || strings.Contains(file, "/testdata/"):
+		return 2
+	case strings.HasPrefix(file, "docs/"):
+		return 1
+	}
+	switch path.Ext(name) {
+	case ".md", ".txt", ".rst", ".adoc", ".json", ".yaml", ".yml", ".toml", ".ini", ".xml", ".csv", ".svg":
+		return 1
+	}
+	return 3
+}
+
+// compareSignificance orders files most significant first: by kind, then by
+// the number of changed lines, then by path. Skipped files come last.
+func compareSignificance(a, b File) int {
+	if (a.Status == StatusSkipped) != (b.Status == StatusSkipped) {
+		if a.Status == StatusSkipped {
+			return 1
+		}
+		return -1
+	}
+	if wa, wb := weight(a.Path), weight(b.Path); wa != wb {
+		return wb - wa
+	}
+	if ca, cb := a.Stats.Added+a.Stats.Removed, b.Stats.Added+b.Stats.Removed; ca != cb {
+		return cb - ca
+	}
+	return strings.Compare(a.Path, b.Path)
+}
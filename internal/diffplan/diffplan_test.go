@@ -0,0 +1,136 @@
+package diffplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hunk returns a hunk adding n lines
+func hunk(start, n int) string {
+	lines := []string{fmt.Sprintf("@@ -%d,0 +%d,%d @@", start, start, n)}
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("+\tline %d of a fairly long hunk", start+i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestParse(t *testing.T) {
+	plan := New([]Change{
+		{Path: "main.go", Diff: "diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2\n@@ -9 +9,2 @@\n+// b\n+var b = 1\n"},
+		{Path: "notes.txt", Diff: "one\ntwo\n", New: true},
+		{Path: "logo.png", Diff: "Binary files /dev/null and b/logo.png differ"},
+		{Path: "icon.ico", Diff: "\x00\x01", New: true},
+		{Path: "web/node_modules/x/index.js", Diff: hunk(1, 2)},
+		{Path: "go.sum", Diff: hunk(1, 2)},
+		{Path: "api/types.go", Diff: "@@ -0,0 +1,2 @@\n+// Code generated by protoc. DO NOT EDIT.\n+package api"},
+		{Path: "script.sh", Diff: "old mode 100644\nnew mode 100755"},
+	}, Options{})
+
+	byPath := map[string]File{}
+	for _, f := range plan.Files {
+		byPath[f.Path] = f
+	}
+	assert.Equal(t, Stats{Added: 3, Removed: 1, Hunks: 2}, byPath["main.go"].Stats)
+	assert.Equal(t, StatusFull, byPath["main.go"].Status)
+	assert.Equal(t, "@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2\n@@ -9 +9,2 @@\n+// b\n+var b = 1\n", byPath["main.go"].Text)
+	assert.Equal(t, "@@ -0,0 +1,2 @@\n+one\n+two\n", byPath["notes.txt"].Text)
+	assert.True(t, byPath["notes.txt"].New)
+
+	for path, reason := range map[string]string{
+		"logo.png":                    ReasonBinary,
+		"icon.ico":                    ReasonBinary,
+		"web/node_modules/x/index.js": ReasonExcluded,
+		"go.sum":                      ReasonExcluded,
+		"api/types.go":                ReasonGenerated,
+		"script.sh":                   ReasonEmpty,
+	} {
+		assert.Equal(t, StatusSkipped, byPath[path].Status, path)
+		assert.Equal(t, reason, byPath[path].Reason, path)
+		assert.Empty(t, byPath[path].Text, path)
+	}
+
+	// Code before documentation, skipped files last
+	assert.Equal(t, "main.go", plan.Files[0].Path)
+	assert.Equal(t, "notes.txt", plan.Files[1].Path)
+	assert.Equal(t, plan.Tokens, EstimateTokens(byPath["main.go"].Text)+EstimateTokens(byPath["notes.txt"].Text))
+}
+
+func TestParseMergesDiffsOfAFile(t *testing.T) {
+	staged := "diff --git a/main.go b/main.go\nindex 1..2 100644\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-var a = 1\n+var a = 2\n"
+	unstaged := "diff --git a/main.go b/main.go\nindex 2..3 100644\n--- a/main.go\n+++ b/main.go\n@@ -9 +9,2 @@\n+// b\n+var b = 1\n"
+	plan := New([]Change{{Path: "main.go", Diff: staged}, {Path: "main.go", Diff: unstaged}}, Options{})
+
+	require.Len(t, plan.Files, 1)
+	f := plan.Files[0]
+	assert.Equal(t, Stats{Added: 3, Removed: 1, Hunks: 2}, f.Stats)
+	assert.Equal(t, "@@ -1 +1 @@\n-var a = 1\n+var a = 2\n@@ -9 +9,2 @@\n+// b\n+var b = 1\n", f.Text)
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := append(slices.Clone(DefaultExclude), "docs/generated/*", "*.snap")
+	for path, want := range map[string]bool{
+		"vendor/github.com/x/y.go":  true,
+		"cmd/vendor/z.go":           true,
+		"vendors.go":                false,
+		"web/yarn.lock":             true,
+		"Cargo.lock":                true,
+		"internal/api/api.pb.go":    true,
+		"docs/generated/index.html": true,
+		"docs/guide.md":             false,
+		"ui/__snapshots__/a.snap":   true,
+		"internal/diffplan/plan.go": false,
+	} {
+		assert.Equal(t, want, Excluded(path, patterns), path)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	big := hunk(1, 40) + "\n" + hunk(100, 40) + "\n" + hunk(200, 40)
+	changes := []Change{
+		{Path: "README.md", Diff: hunk(1, 3)},
+		{Path: "internal/big.go", Diff: big},
+		{Path: "internal/small.go", Diff: hunk(1, 2)},
+		{Path: "internal/small_test.go", Diff: hunk(1, 4)},
+	}
+
+	// Everything fits a large budget
+	plan := New(changes, Options{Budget: 100000})
+	for _, f := range plan.Files {
+		assert.Equal(t, StatusFull, f.Status, f.Path)
+	}
+	assert.Equal(t, []string{"internal/big.go", "internal/small.go", "internal/small_test.go", "README.md"}, paths(plan))
+
+	// A smaller budget truncates the large diff and keeps the small ones whole
+	plan = New(changes, Options{Budget: 800})
+	require.Equal(t, "internal/big.go", plan.Files[0].Path)
+	assert.Equal(t, StatusTruncated, plan.Files[0].Status)
+	assert.Positive(t, plan.Files[0].Omitted)
+	assert.True(t, strings.HasPrefix(plan.Files[0].Text, hunk(1, 40)+"\n"), "whole hunks are kept first")
+	assert.Contains(t, plan.Files[0].Text, fmt.Sprintf("... %d more lines not shown\n", plan.Files[0].Omitted))
+	for _, f := range plan.Files[1:] {
+		assert.Equal(t, StatusFull, f.Status, f.Path)
+	}
+	assert.LessOrEqual(t, plan.Tokens, 800+20)
+
+	// A tiny budget shows the most significant files and summarizes the rest
+	plan = New(changes, Options{Budget: 100})
+	assert.Equal(t, StatusTruncated, plan.Files[0].Status)
+	assert.Equal(t, StatusFull, plan.Files[1].Status)
+	assert.Equal(t, StatusSummary, plan.Files[2].Status)
+	assert.Equal(t, StatusSummary, plan.Files[3].Status)
+	assert.Empty(t, plan.Files[3].Text)
+	assert.Equal(t, 4, plan.Files[3].Omitted, "the header and three lines")
+}
+
+func paths(plan *Plan) []string {
+	var names []string
+	for _, f := range plan.Files {
+		names = append(names, f.Path)
+	}
+	return names
+}
@@ -0,0 +1,202 @@
+// Package hooks installs the git hooks that let tracer follow commits and
+// branch switches made with plain git.
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// Git hooks managed by tracer
+const (
+	PrepareCommitMsg = "prepare-commit-msg"
+	CommitMsg        = "commit-msg"
+	PostCommit       = "post-commit"
+	PostCheckout     = "post-checkout"
+)
+
+// Names lists the hooks managed by tracer in the order git runs them
+var Names = []string{PrepareCommitMsg, CommitMsg, PostCommit, PostCheckout}
+
+// marker identifies hook scripts written by tracer
+const marker = "# Managed by tracer, remove with 'tracer hooks uninstall'"
+
+// chainedSuffix is appended to hooks that existed before tracer was installed.
+// The tracer hook runs them first, with the same arguments.
+const chainedSuffix = ".tracer-chained"
+
+// hookPerm is the mode of hook scripts, which git only runs when executable
+const hookPerm = 0755
+
+// Hook states reported by Check
+const (
+	StateNotInstalled = "not installed"
+	StateInstalled    = "installed"
+	StateChained      = "installed, chaining the previous hook"
+	StateForeign      = "not installed, another hook is in place"
+)
+
+// Status describes a hook in the hooks directory
+type Status struct {
+	Name  string
+	State string
+}
+
+// script returns the hook script that runs the chained hook, if any, and then tracer
+func script(name, tracerPath string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+chained="$(dirname "$0")/%s%s"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+tracer=%s
+if ! command -v "$tracer" >/dev/null 2>&1; then
+	exit 0
+fi
+exec "$tracer" hooks run %s "$@"
+`, marker, name, chainedSuffix, shellQuote(tracerPath), name)
+}
+
+// shellQuote quotes a string for the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isManaged reports whether the hook at path was written by tracer
+func isManaged(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(data, []byte(marker)), nil
+}
+
+// exists reports whether a file exists at path
+func exists(path string) (bool, error) {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// status returns the state of one hook
+func status(dir, name string) (Status, error) {
+	path := filepath.Join(dir, name)
+	found, err := exists(path)
+	if err != nil {
+		return Status{}, err
+	}
+	if !found {
+		return Status{Name: name, State: StateNotInstalled}, nil
+	}
+
+	managed, err := isManaged(path)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to read hook %s: %w", name, err)
+	}
+	if !managed {
+		return Status{Name: name, State: StateForeign}, nil
+	}
+
+	chained, err := exists(path + chainedSuffix)
+	if err != nil {
+		return Status{}, err
+	}
+	if chained {
+		return Status{Name: name, State: StateChained}, nil
+	}
+	return Status{Name: name, State: StateInstalled}, nil
+}
+
+// Check returns the state of every hook managed by tracer in dir
+func Check(dir string) ([]Status, error) {
+	result := make([]Status, 0, len(Names))
+	for _, name := range Names {
+		s, err := status(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// Install writes the tracer hooks to dir, running tracerPath. Hooks already in
+// place are kept and chained, hooks installed earlier by tracer are rewritten.
+func Install(dir, tracerPath string) ([]Status, error) {
+	if err := utils.EnsureDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	// Check every hook first, so that a conflict leaves nothing half installed
+	foreign := make(map[string]bool)
+	for _, name := range Names {
+		current, err := status(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if current.State != StateForeign {
+			continue
+		}
+		chained := filepath.Join(dir, name+chainedSuffix)
+		taken, err := exists(chained)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, fmt.Errorf("cannot chain hook %s: %s already exists. Merge the two hooks and try again", name, chained)
+		}
+		foreign[name] = true
+	}
+
+	for _, name := range Names {
+		path := filepath.Join(dir, name)
+		if foreign[name] {
+			if err := os.Rename(path, path+chainedSuffix); err != nil {
+				return nil, fmt.Errorf("failed to keep existing hook %s: %w", name, err)
+			}
+		}
+
+		if err := utils.WriteFileAtomic(path, []byte(script(name, tracerPath)), hookPerm); err != nil {
+			return nil, fmt.Errorf("failed to write hook %s: %w", name, err)
+		}
+	}
+
+	return Check(dir)
+}
+
+// Uninstall removes the tracer hooks from dir and puts chained hooks back in
+// place. Hooks not written by tracer are left alone.
+func Uninstall(dir string) ([]Status, error) {
+	for _, name := range Names {
+		current, err := status(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if current.State != StateInstalled && current.State != StateChained {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove hook %s: %w", name, err)
+		}
+		if current.State == StateChained {
+			if err := os.Rename(path+chainedSuffix, path); err != nil {
+				return nil, fmt.Errorf("failed to restore hook %s: %w", name, err)
+			}
+		}
+	}
+
+	return Check(dir)
+}
@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// states returns the state of every hook by name
+func states(statuses []Status) map[string]string {
+	result := make(map[string]string)
+	for _, s := range statuses {
+		result[s.Name] = s.State
+	}
+	return result
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	// An existing hook that must keep running
+	existing := "#!/bin/sh\necho existing >> \"$(dirname \"$0\")/calls\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, CommitMsg), []byte(existing), 0755))
+
+	statuses, err := Check(dir)
+	require.NoError(t, err)
+	assert.Equal(t, StateForeign, states(statuses)[CommitMsg])
+	assert.Equal(t, StateNotInstalled, states(statuses)[PostCommit])
+
+	// A fake tracer binary recording its arguments
+	tracer := filepath.Join(t.TempDir(), "tracer's bin")
+	script := "#!/bin/sh\necho \"tracer $*\" >> \"" + filepath.Join(dir, "calls") + "\"\n"
+	require.NoError(t, os.WriteFile(tracer, []byte(script), 0755))
+
+	statuses, err = Install(dir, tracer)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		PrepareCommitMsg: StateInstalled,
+		CommitMsg:        StateChained,
+		PostCommit:       StateInstalled,
+		PostCheckout:     StateInstalled,
+	}, states(statuses))
+
+	chained, err := os.ReadFile(filepath.Join(dir, CommitMsg+chainedSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(chained))
+
+	// Installing again rewrites the tracer hooks without chaining them
+	statuses, err = Install(dir, tracer)
+	require.NoError(t, err)
+	assert.Equal(t, StateChained, states(statuses)[CommitMsg])
+	assert.Equal(t, StateInstalled, states(statuses)[PostCommit])
+
+	// The hook runs the chained hook, then tracer with the same arguments
+	out, err := exec.Command(filepath.Join(dir, CommitMsg), ".git/COMMIT_EDITMSG").CombinedOutput()
+	require.NoError(t, err, string(out))
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	require.NoError(t, err)
+	assert.Equal(t, "existing\ntracer hooks run commit-msg .git/COMMIT_EDITMSG\n", string(calls))
+
+	// Uninstalling puts the previous hook back
+	statuses, err = Uninstall(dir)
+	require.NoError(t, err)
+	assert.Equal(t, StateForeign, states(statuses)[CommitMsg])
+	assert.Equal(t, StateNotInstalled, states(statuses)[PostCommit])
+
+	restored, err := os.ReadFile(filepath.Join(dir, CommitMsg))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(restored))
+	_, err = os.Stat(filepath.Join(dir, PostCommit))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestHookFailuresStopGit(t *testing.T) {
+	dir := t.TempDir()
+
+	// A failing chained hook stops the tracer hook and keeps its exit code
+	require.NoError(t, os.WriteFile(filepath.Join(dir, CommitMsg), []byte("#!/bin/sh\nexit 3\n"), 0755))
+	_, err := Install(dir, "/bin/true")
+	require.NoError(t, err)
+
+	err = exec.Command(filepath.Join(dir, CommitMsg), "msg").Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+
+	// A missing tracer binary never breaks git
+	_, err = Uninstall(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, CommitMsg)))
+	_, err = Install(dir, filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.NoError(t, exec.Command(filepath.Join(dir, PostCommit)).Run())
+}
+
+func TestInstallRefusesToOverwriteChainedHook(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PostCommit), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PostCommit+chainedSuffix), []byte("#!/bin/sh\n"), 0755))
+
+	_, err := Install(dir, "tracer")
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "cannot chain hook post-commit"))
+
+	// Nothing was installed
+	statuses, err := Check(dir)
+	require.NoError(t, err)
+	assert.Equal(t, StateNotInstalled, states(statuses)[CommitMsg])
+}
@@ -0,0 +1,167 @@
+// Package llm talks to the language models that write commit messages: a
+// local Ollama server, any OpenAI-compatible chat completions endpoint such as
+// vLLM, the llama.cpp server or LM Studio, or an offline stub.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderOllama = "ollama" // Ollama's generate API
+	ProviderOpenAI = "openai" // OpenAI-compatible chat completions
+	ProviderStub   = "stub"   // Deterministic offline messages, for tests and air-gapped machines
+)
+
+// Defaults used for settings that are not configured
+const (
+	DefaultProvider       = ProviderOllama
+	DefaultOllamaEndpoint = "http://localhost:11434"
+	DefaultOpenAIEndpoint = "http://localhost:8080/v1"
+	DefaultModel          = "llama3"
+	DefaultTemperature    = 0.7
+	DefaultMaxTokens      = 500
+	DefaultTimeout        = 2 * time.Minute
+	DefaultAPIKeyEnv      = "TRACER_LLM_API_KEY"
+)
+
+// Provider generates text from a prompt
+type Provider interface {
+	// Name returns the name of the provider, such as ollama
+	Name() string
+	// Generate returns the model's completion of the prompt
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// Config selects and configures the provider. It is the llm section of the
+// tracer configuration.
+type Config struct {
+	Provider    string        `yaml:"provider,omitempty"`    // ollama, openai or stub, DefaultProvider when empty
+	Endpoint    string        `yaml:"endpoint,omitempty"`    // Base URL of the server, the provider's default when empty
+	Model       string        `yaml:"model,omitempty"`       // DefaultModel when empty
+	Temperature *float64      `yaml:"temperature,omitempty"` // DefaultTemperature when not set
+	MaxTokens   int           `yaml:"max_tokens,omitempty"`  // Longest completion, DefaultMaxTokens when 0
+	Timeout     time.Duration `yaml:"timeout,omitempty"`     // Such as 30s, DefaultTimeout when 0
+	APIKeyEnv   string        `yaml:"api_key_env,omitempty"` // Environment variable holding the API key, DefaultAPIKeyEnv when empty
+}
+
+// ProviderName returns the configured provider, or the default one
+func (c Config) ProviderName() string {
+	if c.Provider == "" {
+		return DefaultProvider
+	}
+	return strings.ToLower(c.Provider)
+}
+
+// BaseURL returns the configured endpoint, or the default one of the provider
+func (c Config) BaseURL() string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	if c.ProviderName() == ProviderOpenAI {
+		return DefaultOpenAIEndpoint
+	}
+	return DefaultOllamaEndpoint
+}
+
+// ModelName returns the configured model, or the default one
+func (c Config) ModelName() string {
+	if c.Model == "" {
+		return DefaultModel
+	}
+	return c.Model
+}
+
+// SamplingTemperature returns the configured temperature, or the default one
+func (c Config) SamplingTemperature() float64 {
+	if c.Temperature == nil {
+		return DefaultTemperature
+	}
+	return *c.Temperature
+}
+
+// TokenLimit returns the configured maximum completion length, or the default one
+func (c Config) TokenLimit() int {
+	if c.MaxTokens <= 0 {
+		return DefaultMaxTokens
+	}
+	return c.MaxTokens
+}
+
+// RequestTimeout returns the configured request timeout, or the default one
+func (c Config) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+// APIKey returns the API key from the configured environment variable, empty
+// when it is not set
+func (c Config) APIKey() string {
+	name := c.APIKeyEnv
+	if name == "" {
+		name = DefaultAPIKeyEnv
+	}
+	return os.Getenv(name)
+}
+
+// New returns the configured provider
+func New(cfg Config) (Provider, error) {
+	switch cfg.ProviderName() {
+	case ProviderStub:
+		return NewStub(""), nil
+	case ProviderOllama, ProviderOpenAI:
+	default:
+		return nil, fmt.Errorf("invalid llm provider: %s. Must be one of: %s, %s, %s", cfg.Provider, ProviderOllama, ProviderOpenAI, ProviderStub)
+	}
+
+	if _, err := url.ParseRequestURI(cfg.BaseURL()); err != nil {
+		return nil, fmt.Errorf("invalid llm endpoint: %w", err)
+	}
+	if cfg.ProviderName() == ProviderOpenAI {
+		return NewOpenAI(cfg), nil
+	}
+	return NewOllama(cfg), nil
+}
+
+// postJSON posts the request body as JSON and decodes the JSON response. The
+// provider name is used in errors.
+func postJSON(ctx context.Context, client *http.Client, name, endpoint, apiKey string, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create %s API request: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s API: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API returned status code %d", name, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s API response: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var empty Config
+	assert.Equal(t, ProviderOllama, empty.ProviderName())
+	assert.Equal(t, DefaultOllamaEndpoint, empty.BaseURL())
+	assert.Equal(t, DefaultModel, empty.ModelName())
+	assert.Equal(t, DefaultTemperature, empty.SamplingTemperature())
+	assert.Equal(t, DefaultMaxTokens, empty.TokenLimit())
+	assert.Equal(t, DefaultTimeout, empty.RequestTimeout())
+	assert.Equal(t, DefaultOpenAIEndpoint, Config{Provider: "OpenAI"}.BaseURL())
+
+	t.Setenv("TEST_LLM_KEY", "secret")
+	assert.Equal(t, "secret", Config{APIKeyEnv: "TEST_LLM_KEY"}.APIKey())
+
+	for name, want := range map[string]string{"": ProviderOllama, "openai": ProviderOpenAI, "stub": ProviderStub} {
+		p, err := New(Config{Provider: name})
+		require.NoError(t, err)
+		assert.Equal(t, want, p.Name())
+	}
+
+	_, err := New(Config{Provider: "gpt"})
+	assert.ErrorContains(t, err, "invalid llm provider: gpt")
+	_, err = New(Config{Endpoint: "localhost"})
+	assert.ErrorContains(t, err, "invalid llm endpoint")
+}
+
+func TestOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/generate", r.URL.Path)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "codellama", body["model"])
+		assert.Equal(t, "prompt", body["prompt"])
+		assert.Equal(t, false, body["stream"])
+		assert.Equal(t, map[string]any{"temperature": 0.0, "num_predict": float64(200)}, body["options"])
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"response": "  feat: add login\n"})
+	}))
+	defer server.Close()
+
+	temperature := 0.0
+	p := NewOllama(Config{Endpoint: server.URL + "/", Model: "codellama", Temperature: &temperature, MaxTokens: 200})
+	message, err := p.Generate(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "feat: add login", message)
+}
+
+func TestOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, DefaultModel, body["model"])
+		assert.Equal(t, []any{map[string]any{"role": "user", "content": "prompt"}}, body["messages"])
+		assert.Equal(t, DefaultTemperature, body["temperature"])
+		assert.Equal(t, float64(DefaultMaxTokens), body["max_tokens"])
+
+		if r.URL.Query().Get("empty") != "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"choices": []any{}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": "fix: handle timeouts"}}},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv(DefaultAPIKeyEnv, "secret")
+	p := NewOpenAI(Config{Provider: ProviderOpenAI, Endpoint: server.URL + "/v1"})
+	message, err := p.Generate(context.Background(), "prompt")
+	require.NoError(t, err)
+	assert.Equal(t, "fix: handle timeouts", message)
+}
+
+func TestProviderErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow/api/generate":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing/api/generate":
+			_, _ = w.Write([]byte(`{"done": true}`))
+		case "/v1/chat/completions":
+			_, _ = w.Write([]byte(`{"choices": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := NewOllama(Config{Endpoint: server.URL}).Generate(context.Background(), "prompt")
+	assert.ErrorContains(t, err, "ollama API returned status code 404")
+
+	_, err = NewOllama(Config{Endpoint: server.URL + "/missing"}).Generate(context.Background(), "prompt")
+	assert.ErrorContains(t, err, "missing response field in ollama API response")
+
+	_, err = NewOllama(Config{Endpoint: server.URL + "/slow", Timeout: 50 * time.Millisecond}).Generate(context.Background(), "prompt")
+	assert.ErrorContains(t, err, "failed to call ollama API")
+
+	_, err = NewOpenAI(Config{Endpoint: server.URL + "/v1"}).Generate(context.Background(), "prompt")
+	assert.ErrorContains(t, err, "missing choices in openai API response")
+}
+
+func TestStub(t *testing.T) {
+	ctx := context.Background()
+
+	message, err := NewStub("feat: fixed").Generate(ctx, "anything")
+	require.NoError(t, err)
+	assert.Equal(t, "feat: fixed", message)
+
+	stub := NewStub("")
+	message, err = stub.Generate(ctx, "no files")
+	require.NoError(t, err)
+	assert.Equal(t, "chore: update files", message)
+
+	message, err = stub.Generate(ctx, "File: internal/api/user.go\n@@ -1 +1 @@\n")
+	require.NoError(t, err)
+	assert.Equal(t, "chore: update user.go", message)
+
+	prompt := "File: a.go\n+x\nFile: b/c.go\n+y\nFile: a.go\n"
+	message, err = stub.Generate(ctx, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, "chore: update 2 files\n\n- Update a.go\n- Update b/c.go", message)
+	again, err := stub.Generate(ctx, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, message, again)
+
+	message, err = stub.Generate(ctx, "New file: docs/guide.md\nHello\nFile: main.go\n+x\n")
+	require.NoError(t, err)
+	assert.Equal(t, "chore: update 2 files\n\n- Update docs/guide.md\n- Update main.go", message)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = stub.Generate(cancelled, prompt)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Ollama generates with Ollama's generate API
+type Ollama struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewOllama returns a provider for the Ollama server of the config
+func NewOllama(cfg Config) *Ollama {
+	return &Ollama{cfg: cfg, client: &http.Client{Timeout: cfg.RequestTimeout()}}
+}
+
+// Name returns ollama
+func (o *Ollama) Name() string {
+	return ProviderOllama
+}
+
+// Generate returns the model's completion of the prompt
+func (o *Ollama) Generate(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model":  o.cfg.ModelName(),
+		"prompt": prompt,
+		"stream": false,
+		"options": map[string]any{
+			"temperature": o.cfg.SamplingTemperature(),
+			"num_predict": o.cfg.TokenLimit(),
+		},
+	}
+
+	var result struct {
+		Response *string `json:"response"`
+	}
+	if err := postJSON(ctx, o.client, o.Name(), o.cfg.BaseURL()+"/api/generate", o.cfg.APIKey(), body, &result); err != nil {
+		return "", err
+	}
+	if result.Response == nil {
+		return "", fmt.Errorf("missing response field in %s API response", o.Name())
+	}
+	return strings.TrimSpace(*result.Response), nil
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAI generates with an OpenAI-compatible chat completions API, as served
+// by vLLM, the llama.cpp server or LM Studio
+type OpenAI struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewOpenAI returns a provider for the chat completions endpoint of the config
+func NewOpenAI(cfg Config) *OpenAI {
+	return &OpenAI{cfg: cfg, client: &http.Client{Timeout: cfg.RequestTimeout()}}
+}
+
+// Name returns openai
+func (o *OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+// Generate returns the model's completion of the prompt, sent as a user message
+func (o *OpenAI) Generate(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model": o.cfg.ModelName(),
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": o.cfg.SamplingTemperature(),
+		"max_tokens":  o.cfg.TokenLimit(),
+		"stream":      false,
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, o.client, o.Name(), o.cfg.BaseURL()+"/chat/completions", o.cfg.APIKey(), body, &result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("missing choices in %s API response", o.Name())
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
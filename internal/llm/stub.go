@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Stub writes commit messages without a model, for tests and machines
+// without one. The same prompt always gets the same message.
+type Stub struct {
+	message string
+}
+
+// NewStub returns a stub that answers with the message, or, when it is empty,
+// with a chore commit listing the files the prompt shows
+func NewStub(message string) *Stub {
+	return &Stub{message: message}
+}
+
+// Name returns stub
+func (s *Stub) Name() string {
+	return ProviderStub
+}
+
+// Generate returns the stub's message for the prompt
+func (s *Stub) Generate(ctx context.Context, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if s.message != "" {
+		return s.message, nil
+	}
+
+	var files []string
+	for _, line := range strings.Split(prompt, "\n") {
+		file, ok := strings.CutPrefix(line, "File: ")
+		if !ok {
+			file, ok = strings.CutPrefix(line, "New file: ")
+		}
+		if file = strings.TrimSpace(file); ok && file != "" && !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+
+	switch {
+	case len(files) == 0:
+		return "chore: update files", nil
+	case len(files) == 1:
+		return fmt.Sprintf("chore: update %s", path.Base(files[0])), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "chore: update %d files\n\n", len(files))
+	for _, file := range files {
+		fmt.Fprintf(&b, "- Update %s\n", file)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
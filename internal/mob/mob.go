@@ -0,0 +1,353 @@
+// Package mob keeps the state of mob programming sessions: the participants,
+// who is driving, and a journal of rotations and commits.
+package mob
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// Journal event kinds
+const (
+	EventStart  = "start"
+	EventRotate = "rotate"
+	EventCommit = "commit"
+	EventStop   = "stop"
+)
+
+// Session is a running mob session
+type Session struct {
+	Participants []string      `json:"participants"` // In driving order
+	Driver       int           `json:"driver"`       // Index of the driver in Participants
+	Interval     time.Duration `json:"interval,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	RotatedAt    time.Time     `json:"rotated_at"`
+}
+
+// Event is an entry of the mob journal
+type Event struct {
+	At           time.Time `json:"at"`
+	Kind         string    `json:"kind"`
+	Driver       string    `json:"driver,omitempty"`
+	Participants []string  `json:"participants,omitempty"`
+	Commit       string    `json:"commit,omitempty"`
+}
+
+// DriverName returns the participant at the keyboard
+func (s *Session) DriverName() string {
+	return s.Participants[s.Driver]
+}
+
+// NextDriverName returns the participant who drives after the current driver
+func (s *Session) NextDriverName() string {
+	return s.Participants[(s.Driver+1)%len(s.Participants)]
+}
+
+// NextRotation returns when the driver should rotate, or the zero time
+// when the session has no rotation interval
+func (s *Session) NextRotation() time.Time {
+	if s.Interval <= 0 {
+		return time.Time{}
+	}
+	return s.RotatedAt.Add(s.Interval)
+}
+
+// sessionPath returns the path of the file holding the running session
+func sessionPath() (string, error) {
+	dir, err := utils.GetRepoConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.DefaultMobFile), nil
+}
+
+// journalPath returns the path of the mob journal
+func journalPath() (string, error) {
+	dir, err := utils.GetRepoConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.DefaultMobJournalFile), nil
+}
+
+// lock takes the lock guarding the session and the journal. Commands changing
+// them hold it from reading them until they are written.
+func lock() (func(), error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	return utils.LockFile(path + ".lock")
+}
+
+// Current returns the running session, or nil if there is none
+func Current() (*Session, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mob session: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse mob session %s: %w", path, err)
+	}
+	if len(s.Participants) == 0 || s.Driver < 0 || s.Driver >= len(s.Participants) {
+		return nil, fmt.Errorf("mob session %s is invalid", path)
+	}
+	return &s, nil
+}
+
+// save writes the session
+func save(s *Session) error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mob session: %w", err)
+	}
+	if err := utils.WriteFileAtomic(path, data, utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write mob session: %w", err)
+	}
+	return nil
+}
+
+// Start starts a session with the participants, the first one driving. With
+// an interval, the driver is due to rotate every interval.
+func Start(participants []string, interval time.Duration, now time.Time) (*Session, error) {
+	var names []string
+	for _, p := range participants {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if slices.Contains(names, p) {
+			return nil, fmt.Errorf("%s is listed twice", p)
+		}
+		names = append(names, p)
+	}
+	if len(names) < 2 {
+		return nil, fmt.Errorf("a mob needs at least two participants")
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("invalid rotation interval: %s", interval)
+	}
+
+	unlock, err := lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	current, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, fmt.Errorf("a mob session with %s is already running. Stop it first with 'tracer mob stop'", strings.Join(current.Participants, ", "))
+	}
+
+	s := &Session{Participants: names, Interval: interval, StartedAt: now, RotatedAt: now}
+	if err := save(s); err != nil {
+		return nil, err
+	}
+	if err := appendEvent(Event{At: now, Kind: EventStart, Driver: s.DriverName(), Participants: names}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Next hands the keyboard to the next participant
+func Next(now time.Time) (*Session, error) {
+	unlock, err := lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	s, err := running()
+	if err != nil {
+		return nil, err
+	}
+
+	s.Driver = (s.Driver + 1) % len(s.Participants)
+	s.RotatedAt = now
+	if err := save(s); err != nil {
+		return nil, err
+	}
+	if err := appendEvent(Event{At: now, Kind: EventRotate, Driver: s.DriverName()}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Stop ends the session and returns it
+func Stop(now time.Time) (*Session, error) {
+	unlock, err := lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	s, err := running()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("failed to end mob session: %w", err)
+	}
+	if err := appendEvent(Event{At: now, Kind: EventStop, Driver: s.DriverName(), Participants: s.Participants}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordCommit journals a commit made by the current driver. It does nothing
+// outside a session or when the commit is already journaled.
+func RecordCommit(hash string, now time.Time) error {
+	hash = strings.TrimSpace(hash)
+	unlock, err := lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := Current()
+	if err != nil || s == nil || hash == "" {
+		return err
+	}
+
+	events, err := Journal()
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if e.Kind == EventCommit && e.Commit == hash {
+			return nil
+		}
+	}
+
+	return appendEvent(Event{At: now, Kind: EventCommit, Driver: s.DriverName(), Commit: hash})
+}
+
+// running returns the running session, or an error if there is none
+func running() (*Session, error) {
+	s, err := Current()
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("no mob session is running. Start one with 'tracer mob start <participant>...'")
+	}
+	return s, nil
+}
+
+// appendEvent adds an event to the journal. The journal is replaced
+// atomically, so readers never see a partly written entry.
+func appendEvent(e Event) error {
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mob journal entry: %w", err)
+	}
+
+	journal, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read mob journal: %w", err)
+	}
+	if len(journal) > 0 && journal[len(journal)-1] != '\n' {
+		journal = append(journal, '\n')
+	}
+	journal = append(journal, data...)
+	if err := utils.WriteFileAtomic(path, append(journal, '\n'), utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write mob journal: %w", err)
+	}
+	return nil
+}
+
+// Journal returns the events of all sessions, oldest first
+func Journal() ([]Event, error) {
+	path, err := journalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mob journal: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse mob journal %s line %d: %w", path, line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mob journal: %w", err)
+	}
+	return events, nil
+}
+
+// SessionEvents returns the events of the session started last, oldest first
+func SessionEvents(events []Event) []Event {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Kind == EventStart {
+			return events[i:]
+		}
+	}
+	return events
+}
+
+// CommitsByDriver returns the journaled commits grouped by who drove them,
+// and the drivers in the order they first committed
+func CommitsByDriver(events []Event) (map[string][]string, []string) {
+	commits := make(map[string][]string)
+	var drivers []string
+	for _, e := range events {
+		if e.Kind != EventCommit {
+			continue
+		}
+		if _, ok := commits[e.Driver]; !ok {
+			drivers = append(drivers, e.Driver)
+		}
+		commits[e.Driver] = append(commits[e.Driver], e.Commit)
+	}
+	return commits, drivers
+}
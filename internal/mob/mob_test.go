@@ -0,0 +1,130 @@
+package mob
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRepo(t *testing.T) {
+	repoDir := t.TempDir()
+	origGitClient := utils.GitClient
+	t.Cleanup(func() { utils.GitClient = origGitClient })
+
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	mockGit.GetGitRootFunc = func() (string, error) {
+		return repoDir, nil
+	}
+	utils.GitClient = mockGit
+}
+
+func TestSession(t *testing.T) {
+	setupRepo(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	s, err := Current()
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	_, err = Start([]string{"alice"}, 0, start)
+	assert.EqualError(t, err, "a mob needs at least two participants")
+	_, err = Start([]string{"alice", "bob", "alice"}, 0, start)
+	assert.EqualError(t, err, "alice is listed twice")
+	_, err = Next(start)
+	assert.Error(t, err)
+
+	s, err = Start([]string{"alice", " bob ", "carol"}, 10*time.Minute, start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, s.Participants)
+	assert.Equal(t, "alice", s.DriverName())
+	assert.Equal(t, "bob", s.NextDriverName())
+	assert.Equal(t, start.Add(10*time.Minute), s.NextRotation())
+
+	_, err = Start([]string{"dave", "erin"}, 0, start)
+	assert.Error(t, err, "only one session runs at a time")
+
+	require.NoError(t, RecordCommit("aaa111", start.Add(time.Minute)))
+	require.NoError(t, RecordCommit("aaa111", start.Add(2*time.Minute)), "a journaled commit is skipped")
+
+	s, err = Next(start.Add(10 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "bob", s.DriverName())
+	assert.Equal(t, start.Add(20*time.Minute), s.NextRotation())
+	require.NoError(t, RecordCommit("bbb222", start.Add(12*time.Minute)))
+
+	s, err = Next(start.Add(20 * time.Minute))
+	require.NoError(t, err)
+	s, err = Next(start.Add(30 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", s.DriverName(), "rotation wraps around")
+	require.NoError(t, RecordCommit("ccc333", start.Add(31*time.Minute)))
+
+	current, err := Current()
+	require.NoError(t, err)
+	assert.Equal(t, s, current)
+
+	_, err = Stop(start.Add(40 * time.Minute))
+	require.NoError(t, err)
+	current, err = Current()
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	// Commits outside a session are not journaled
+	require.NoError(t, RecordCommit("ddd444", start.Add(41*time.Minute)))
+
+	events, err := Journal()
+	require.NoError(t, err)
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Equal(t, []string{EventStart, EventCommit, EventRotate, EventCommit, EventRotate, EventRotate, EventCommit, EventStop}, kinds)
+
+	commits, drivers := CommitsByDriver(events)
+	assert.Equal(t, []string{"alice", "bob"}, drivers)
+	assert.Equal(t, []string{"aaa111", "ccc333"}, commits["alice"])
+	assert.Equal(t, []string{"bbb222"}, commits["bob"])
+}
+
+func TestSessionEvents(t *testing.T) {
+	events := []Event{
+		{Kind: EventStart}, {Kind: EventCommit, Commit: "a"}, {Kind: EventStop},
+		{Kind: EventStart}, {Kind: EventCommit, Commit: "b"},
+	}
+	assert.Equal(t, events[3:], SessionEvents(events))
+	assert.Empty(t, SessionEvents(nil))
+}
+
+func TestRecordCommitConcurrently(t *testing.T) {
+	setupRepo(t)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	_, err := Start([]string{"alice", "bob"}, 0, start)
+	require.NoError(t, err)
+
+	const commits = 8
+	var wg sync.WaitGroup
+	errs := make([]error, commits)
+	for i := range commits {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each commit is recorded twice, as both the hook and the command record it
+			errs[i] = RecordCommit(fmt.Sprintf("commit%d", i%(commits/2)), start)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	events, err := Journal()
+	require.NoError(t, err)
+	byDriver, drivers := CommitsByDriver(events)
+	assert.Equal(t, []string{"alice"}, drivers)
+	assert.Len(t, byDriver["alice"], commits/2)
+}
@@ -0,0 +1,304 @@
+// Package pair keeps the history of pair programming sessions and reports who
+// paired with whom and for how long.
+package pair
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// Session is a pair programming session
+type Session struct {
+	Participants []string  `json:"participants"`
+	Project      string    `json:"project,omitempty"`
+	Story        string    `json:"story,omitempty"` // ID of the current story when the session started
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at,omitempty"` // Zero while the session is active
+	Commits      []string  `json:"commits,omitempty"`
+}
+
+// History is the list of sessions, oldest first
+type History struct {
+	Sessions []Session `json:"sessions"`
+	path     string
+}
+
+// Active reports whether the session has not ended
+func (s *Session) Active() bool {
+	return s.EndedAt.IsZero()
+}
+
+// Duration returns how long the session lasted, up to now while it is active
+func (s *Session) Duration(now time.Time) time.Duration {
+	if s.Active() {
+		return now.Sub(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// Load reads the history from the file in the repository configuration
+// directory. A missing history is empty.
+func Load(file string) (*History, error) {
+	path, err := historyPath(file)
+	if err != nil {
+		return nil, err
+	}
+	return load(path)
+}
+
+// Update changes the history in the file while holding its lock, so that
+// concurrent commands do not lose each other's changes. The history is only
+// written when change modified it.
+func Update(file string, change func(h *History) error) error {
+	path, err := historyPath(file)
+	if err != nil {
+		return err
+	}
+	unlock, err := utils.LockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	h, err := load(path)
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pair history: %w", err)
+	}
+	if err := change(h); err != nil {
+		return err
+	}
+	after, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pair history: %w", err)
+	}
+	if string(before) == string(after) {
+		return nil
+	}
+	return h.Save()
+}
+
+// historyPath returns the path of the history file in the repository
+// configuration directory
+func historyPath(file string) (string, error) {
+	dir, err := utils.GetRepoConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// load reads the history from path. A missing history is empty.
+func load(path string) (*History, error) {
+	h := &History{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read pair history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse pair history %s: %w", h.path, err)
+	}
+	return h, nil
+}
+
+// Save writes the history to the file it was loaded from. Use Update to
+// change a history other commands may change too.
+func (h *History) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pair history: %w", err)
+	}
+	if err := utils.WriteFileAtomic(h.path, data, utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write pair history: %w", err)
+	}
+	return nil
+}
+
+// Active returns the session that has not ended, or nil
+func (h *History) Active() *Session {
+	for i := len(h.Sessions) - 1; i >= 0; i-- {
+		if h.Sessions[i].Active() {
+			return &h.Sessions[i]
+		}
+	}
+	return nil
+}
+
+// Start ends the active session, if any, and starts a new one
+func (h *History) Start(participants []string, project, storyID string, now time.Time) *Session {
+	h.End(now)
+	h.Sessions = append(h.Sessions, Session{
+		Participants: participants,
+		Project:      project,
+		Story:        storyID,
+		StartedAt:    now,
+	})
+	return &h.Sessions[len(h.Sessions)-1]
+}
+
+// End ends the active session and returns it, or nil when there is none
+func (h *History) End(now time.Time) *Session {
+	s := h.Active()
+	if s != nil {
+		s.EndedAt = now
+	}
+	return s
+}
+
+// RecordCommit adds a commit to the active session. It reports whether the
+// commit was added, which it is not outside a session or when already there.
+func (h *History) RecordCommit(hash string) bool {
+	s := h.Active()
+	hash = strings.TrimSpace(hash)
+	if s == nil || hash == "" || slices.Contains(s.Commits, hash) {
+		return false
+	}
+	s.Commits = append(s.Commits, hash)
+	return true
+}
+
+// Pair is two people who paired, in alphabetical order
+type Pair struct {
+	A, B string
+}
+
+func (p Pair) String() string {
+	return p.A + " & " + p.B
+}
+
+// newPair returns the pair of two people, in alphabetical order
+func newPair(a, b string) Pair {
+	if strings.ToLower(b) < strings.ToLower(a) {
+		a, b = b, a
+	}
+	return Pair{A: a, B: b}
+}
+
+// PersonStats is the pairing time of a person
+type PersonStats struct {
+	Name     string
+	Sessions int
+	Time     time.Duration
+}
+
+// PairStats is how often and how long two people paired
+type PairStats struct {
+	Pair
+	Sessions int
+	Time     time.Duration
+	Last     time.Time // When they last paired, zero when they never did
+}
+
+// Stats summarizes the sessions
+type Stats struct {
+	People []PersonStats // Most time paired first
+	Pairs  []PairStats   // Most sessions first
+}
+
+// Stats summarizes the sessions, counting active ones up to now
+func (h *History) Stats(now time.Time) Stats {
+	people := make(map[string]*PersonStats)
+	pairs := make(map[Pair]*PairStats)
+
+	for _, s := range h.Sessions {
+		d := s.Duration(now)
+		for _, name := range s.Participants {
+			if people[name] == nil {
+				people[name] = &PersonStats{Name: name}
+			}
+			people[name].Sessions++
+			people[name].Time += d
+		}
+		for i, a := range s.Participants {
+			for _, b := range s.Participants[i+1:] {
+				p := newPair(a, b)
+				if pairs[p] == nil {
+					pairs[p] = &PairStats{Pair: p}
+				}
+				pairs[p].Sessions++
+				pairs[p].Time += d
+				if s.StartedAt.After(pairs[p].Last) {
+					pairs[p].Last = s.StartedAt
+				}
+			}
+		}
+	}
+
+	var stats Stats
+	for _, p := range people {
+		stats.People = append(stats.People, *p)
+	}
+	sort.Slice(stats.People, func(i, j int) bool {
+		if stats.People[i].Time != stats.People[j].Time {
+			return stats.People[i].Time > stats.People[j].Time
+		}
+		return stats.People[i].Name < stats.People[j].Name
+	})
+
+	for _, p := range pairs {
+		stats.Pairs = append(stats.Pairs, *p)
+	}
+	sort.Slice(stats.Pairs, func(i, j int) bool {
+		if stats.Pairs[i].Sessions != stats.Pairs[j].Sessions {
+			return stats.Pairs[i].Sessions > stats.Pairs[j].Sessions
+		}
+		return stats.Pairs[i].String() < stats.Pairs[j].String()
+	})
+	return stats
+}
+
+// Stale returns the pairs of the people that have not paired since the
+// given time, including those who never did, least recent first
+func (st Stats) Stale(people []string, since time.Time) []PairStats {
+	known := make(map[Pair]PairStats)
+	for _, p := range st.Pairs {
+		known[p.Pair] = p
+	}
+
+	var stale []PairStats
+	for i, a := range people {
+		for _, b := range people[i+1:] {
+			if strings.EqualFold(a, b) {
+				continue
+			}
+			p, ok := known[newPair(a, b)]
+			if !ok {
+				p = PairStats{Pair: newPair(a, b)}
+			}
+			if p.Last.Before(since) {
+				stale = append(stale, p)
+			}
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		if !stale[i].Last.Equal(stale[j].Last) {
+			return stale[i].Last.Before(stale[j].Last)
+		}
+		return stale[i].String() < stale[j].String()
+	})
+	return stale
+}
+
+// Names returns the people in the stats
+func (st Stats) Names() []string {
+	names := make([]string, 0, len(st.People))
+	for _, p := range st.People {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
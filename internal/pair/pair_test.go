@@ -0,0 +1,130 @@
+package pair
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	repoDir := t.TempDir()
+	origGitClient := utils.GitClient
+	defer func() { utils.GitClient = origGitClient }()
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	mockGit.GetGitRootFunc = func() (string, error) {
+		return repoDir, nil
+	}
+	utils.GitClient = mockGit
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	h, err := Load("pair.json")
+	require.NoError(t, err)
+	assert.Empty(t, h.Sessions)
+	assert.Nil(t, h.Active())
+	assert.False(t, h.RecordCommit("abc123"), "no commits outside a session")
+
+	h.Start([]string{"john", "jane"}, "proj", "proj-7", start)
+	assert.True(t, h.RecordCommit("abc123"))
+	assert.False(t, h.RecordCommit("abc123"))
+	require.NoError(t, h.Save())
+
+	h, err = Load("pair.json")
+	require.NoError(t, err)
+	active := h.Active()
+	require.NotNil(t, active)
+	assert.Equal(t, []string{"abc123"}, active.Commits)
+	assert.Equal(t, time.Hour, active.Duration(start.Add(time.Hour)))
+
+	// Starting again ends the session left running
+	h.Start([]string{"john", "sam"}, "proj", "", start.Add(2*time.Hour))
+	assert.Equal(t, start.Add(2*time.Hour), h.Sessions[0].EndedAt)
+	ended := h.End(start.Add(3 * time.Hour))
+	require.NotNil(t, ended)
+	assert.Equal(t, time.Hour, ended.Duration(start.AddDate(0, 1, 0)))
+	assert.Nil(t, h.End(start.Add(4*time.Hour)))
+}
+
+func TestStats(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	h := &History{Sessions: []Session{
+		{Participants: []string{"john", "jane"}, StartedAt: day, EndedAt: day.Add(2 * time.Hour)},
+		{Participants: []string{"jane", "john"}, StartedAt: day.AddDate(0, 0, 1), EndedAt: day.AddDate(0, 0, 1).Add(time.Hour)},
+		{Participants: []string{"sam", "john"}, StartedAt: day.AddDate(0, 0, 20)},
+	}}
+	now := day.AddDate(0, 0, 20).Add(30 * time.Minute)
+
+	stats := h.Stats(now)
+	assert.Equal(t, []PersonStats{
+		{Name: "john", Sessions: 3, Time: 3*time.Hour + 30*time.Minute},
+		{Name: "jane", Sessions: 2, Time: 3 * time.Hour},
+		{Name: "sam", Sessions: 1, Time: 30 * time.Minute},
+	}, stats.People)
+	assert.Equal(t, []PairStats{
+		{Pair: Pair{A: "jane", B: "john"}, Sessions: 2, Time: 3 * time.Hour, Last: day.AddDate(0, 0, 1)},
+		{Pair: Pair{A: "john", B: "sam"}, Sessions: 1, Time: 30 * time.Minute, Last: day.AddDate(0, 0, 20)},
+	}, stats.Pairs)
+
+	stale := stats.Stale([]string{"jane", "john", "sam", "lee"}, now.AddDate(0, 0, -14))
+	var names []string
+	for _, p := range stale {
+		names = append(names, p.String())
+	}
+	assert.Equal(t, []string{"jane & lee", "jane & sam", "john & lee", "lee & sam", "jane & john"}, names)
+	assert.Equal(t, []string{"jane", "john", "sam"}, stats.Names())
+}
+
+func TestUpdateConcurrently(t *testing.T) {
+	repoDir := t.TempDir()
+	origGitClient := utils.GitClient
+	defer func() { utils.GitClient = origGitClient }()
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	mockGit.GetGitRootFunc = func() (string, error) {
+		return repoDir, nil
+	}
+	utils.GitClient = mockGit
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, Update("pair.json", func(h *History) error {
+		h.Start([]string{"john", "jane"}, "proj", "", start)
+		return nil
+	}))
+
+	const commits = 8
+	var wg sync.WaitGroup
+	errs := make([]error, commits)
+	for i := range commits {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = Update("pair.json", func(h *History) error {
+				h.RecordCommit(fmt.Sprintf("commit%d", i))
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	h, err := Load("pair.json")
+	require.NoError(t, err)
+	require.NotNil(t, h.Active())
+	assert.Len(t, h.Active().Commits, commits)
+
+	failed := fmt.Errorf("failed")
+	err = Update("pair.json", func(h *History) error {
+		h.End(start.Add(time.Hour))
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	h, err = Load("pair.json")
+	require.NoError(t, err)
+	assert.NotNil(t, h.Active(), "a failed update is not saved")
+}
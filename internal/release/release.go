@@ -0,0 +1,213 @@
+// Package release computes semantic versions from tags and the conventional
+// commits made since the last release.
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/conventional"
+)
+
+// DefaultPrefix is put before the version numbers of new tags when no
+// earlier tag shows otherwise
+const DefaultPrefix = "v"
+
+// versionPattern matches a version tag such as v1.2.3 or 1.2.3-rc.1
+var versionPattern = regexp.MustCompile(`^([A-Za-z-]*?)(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Version is a semantic version
+type Version struct {
+	Prefix string // Put before the numbers in tags, such as v
+	Major  int
+	Minor  int
+	Patch  int
+	Pre    string // Pre-release, such as rc.1, empty for releases
+}
+
+// ParseVersion parses a version tag. Build metadata is dropped.
+func ParseVersion(tag string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(tag))
+	if m == nil {
+		return Version{}, fmt.Errorf("%q is not a semantic version", tag)
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	return Version{Prefix: m[1], Major: major, Minor: minor, Patch: patch, Pre: m[5]}, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// IsPrerelease reports whether the version is a pre-release
+func (v Version) IsPrerelease() bool {
+	return v.Pre != ""
+}
+
+// Compare returns -1, 0 or 1 as v precedes, equals or follows o in semantic
+// version precedence. Prefixes are ignored.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Pre, "."), strings.Split(o.Pre, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(a) - len(b))
+}
+
+// compareIdentifier compares pre-release identifiers: numbers numerically and
+// before words, words lexically
+func compareIdentifier(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(x - y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Bump is the part of the version a release increments
+type Bump int
+
+// Bumps, from none to the largest
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+var bumpNames = []string{"none", "patch", "minor", "major"}
+
+func (b Bump) String() string {
+	return bumpNames[b]
+}
+
+// ParseBump parses patch, minor or major
+func ParseBump(name string) (Bump, error) {
+	for i, n := range bumpNames[1:] {
+		if strings.EqualFold(name, n) {
+			return Bump(i + 1), nil
+		}
+	}
+	return BumpNone, fmt.Errorf("invalid bump: %s. Must be one of: patch, minor, major", name)
+}
+
+// BumpOf returns the bump a commit message calls for: major for breaking
+// changes, minor for features and patch for fixes
+func BumpOf(message string) Bump {
+	msg, err := conventional.Parse(message)
+	if err != nil {
+		return BumpNone
+	}
+	switch {
+	case msg.Breaking:
+		return BumpMajor
+	case msg.Type == "feat":
+		return BumpMinor
+	case msg.Type == "fix":
+		return BumpPatch
+	}
+	return BumpNone
+}
+
+// Apply returns the release after v with the bump
+func (v Version) Apply(b Bump) Version {
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch b {
+	case BumpMajor:
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+	case BumpMinor:
+		next.Minor, next.Patch = v.Minor+1, 0
+	case BumpPatch:
+		next.Patch = v.Patch + 1
+	}
+	return next
+}
+
+// Versions parses the tags that are semantic versions, leaving out the others
+func Versions(tags []string) []Version {
+	var versions []Version
+	for _, tag := range tags {
+		if v, err := ParseVersion(tag); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// LatestRelease returns the highest version that is not a pre-release
+func LatestRelease(versions []Version) (Version, bool) {
+	var latest Version
+	found := false
+	for _, v := range versions {
+		if !v.IsPrerelease() && (!found || v.Compare(latest) > 0) {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// Next returns the version after current with the bump. With a channel, such
+// as rc or beta, it is the next pre-release of that version on the channel,
+// numbered after the existing ones.
+func Next(current Version, bump Bump, channel string, existing []Version) Version {
+	next := current.Apply(bump)
+	if channel == "" {
+		return next
+	}
+
+	number := 0
+	for _, v := range existing {
+		if v.Major != next.Major || v.Minor != next.Minor || v.Patch != next.Patch {
+			continue
+		}
+		name, n, ok := strings.Cut(v.Pre, ".")
+		if !ok || name != channel {
+			continue
+		}
+		if i, err := strconv.Atoi(n); err == nil && i > number {
+			number = i
+		}
+	}
+	next.Pre = fmt.Sprintf("%s.%d", channel, number+1)
+	return next
+}
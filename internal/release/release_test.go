@@ -0,0 +1,72 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("v1.2.3-rc.1+build.5")
+	require.NoError(t, err)
+	assert.Equal(t, Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"}, v)
+	assert.Equal(t, "v1.2.3-rc.1", v.String())
+	assert.True(t, v.IsPrerelease())
+
+	v, err = ParseVersion("2.0.10")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.10", v.String())
+
+	for _, tag := range []string{"latest", "v1.2", "v01.2.3"} {
+		_, err := ParseVersion(tag)
+		assert.Error(t, err, tag)
+	}
+	assert.Len(t, Versions([]string{"v1.0.0", "nightly", "v1.1.0-beta.2"}), 2)
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "v2.0.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i-1])
+		b, _ := ParseVersion(ordered[i])
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+	}
+
+	latest, found := LatestRelease(Versions([]string{"v1.2.0", "v1.10.0", "v2.0.0-rc.1"}))
+	assert.True(t, found)
+	assert.Equal(t, "v1.10.0", latest.String())
+	_, found = LatestRelease(Versions([]string{"v1.0.0-rc.1"}))
+	assert.False(t, found)
+}
+
+func TestBump(t *testing.T) {
+	assert.Equal(t, BumpMajor, BumpOf("feat(api)!: drop v1"))
+	assert.Equal(t, BumpMajor, BumpOf("fix: rename flag\n\nBREAKING CHANGE: --old is gone"))
+	assert.Equal(t, BumpMinor, BumpOf("feat: add login"))
+	assert.Equal(t, BumpPatch, BumpOf("fix: handle timeouts"))
+	assert.Equal(t, BumpNone, BumpOf("docs: update readme"))
+	assert.Equal(t, BumpNone, BumpOf("Update readme"))
+
+	b, err := ParseBump("Minor")
+	require.NoError(t, err)
+	assert.Equal(t, BumpMinor, b)
+	_, err = ParseBump("none")
+	assert.Error(t, err)
+
+	v := Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}
+	assert.Equal(t, "v2.0.0", v.Apply(BumpMajor).String())
+	assert.Equal(t, "v1.3.0", v.Apply(BumpMinor).String())
+	assert.Equal(t, "v1.2.4", v.Apply(BumpPatch).String())
+}
+
+func TestNext(t *testing.T) {
+	current := Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}
+	existing := Versions([]string{"v1.2.3", "v1.3.0-rc.1", "v1.3.0-rc.2", "v1.3.0-beta.1", "v2.0.0-rc.4"})
+
+	assert.Equal(t, "v1.3.0", Next(current, BumpMinor, "", existing).String())
+	assert.Equal(t, "v1.3.0-rc.3", Next(current, BumpMinor, "rc", existing).String())
+	assert.Equal(t, "v1.3.0-beta.2", Next(current, BumpMinor, "beta", existing).String())
+	assert.Equal(t, "v1.2.4-rc.1", Next(current, BumpPatch, "rc", existing).String())
+}
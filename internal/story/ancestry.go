@@ -0,0 +1,149 @@
+package story
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// CommitSelection selects commits by their place in the git history rather
+// than by their dates, so rebased and cherry-picked work is placed correctly.
+// Every bound that is set must hold.
+type CommitSelection struct {
+	After  string // Only descendants of this revision, not the revision itself
+	Before string // Only this revision and its ancestors
+	Range  string // Only the commits of this range, as in git log A..B
+}
+
+// IsEmpty reports whether no bound is set
+func (c CommitSelection) IsEmpty() bool {
+	return c.After == "" && c.Before == "" && c.Range == ""
+}
+
+// resolveRevision returns the commit hash of a revision
+func resolveRevision(rev string) (string, error) {
+	hash, err := utils.GitClient.ParseRevision(rev + "^{commit}")
+	hash = strings.TrimSpace(hash)
+	if err != nil || hash == "" {
+		return "", fmt.Errorf("unknown revision: %s", rev)
+	}
+	return hash, nil
+}
+
+// Commits returns the full hashes of the selected commits that are reachable
+// from any branch or tag
+func (c CommitSelection) Commits() (map[string]bool, error) {
+	if c.IsEmpty() {
+		return nil, fmt.Errorf("no commits selected. Give a revision to select commits after, before or a range")
+	}
+
+	var sets []map[string]bool
+	add := func(args ...string) error {
+		hashes, err := utils.GitClient.RevList(args...)
+		if err != nil {
+			return err
+		}
+		set := make(map[string]bool, len(hashes))
+		for _, hash := range hashes {
+			set[hash] = true
+		}
+		sets = append(sets, set)
+		return nil
+	}
+
+	if c.After != "" {
+		hash, err := resolveRevision(c.After)
+		if err != nil {
+			return nil, err
+		}
+		// Descendants of the revision on any branch or tag
+		if err := add("--ancestry-path", "^"+hash, "--all"); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Before != "" {
+		hash, err := resolveRevision(c.Before)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(hash); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Range != "" {
+		from, to, ok := strings.Cut(c.Range, "..")
+		if !ok || strings.HasPrefix(to, ".") || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid range: %s. Use A..B", c.Range)
+		}
+		fromHash, err := resolveRevision(from)
+		if err != nil {
+			return nil, err
+		}
+		toHash, err := resolveRevision(to)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fromHash + ".." + toHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the commits selected by every bound
+	result := sets[0]
+	for _, set := range sets[1:] {
+		for hash := range result {
+			if !set[hash] {
+				delete(result, hash)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// StoryCommits is a story along with some of its commits
+type StoryCommits struct {
+	Story   *Story
+	Commits []Commit
+}
+
+// StoriesWithCommits returns the stories that have recorded commits among the
+// given full hashes, with those commits. Recorded hashes may be abbreviated.
+func StoriesWithCommits(stories []*Story, hashes map[string]bool) []StoryCommits {
+	var result []StoryCommits
+	for _, s := range stories {
+		var matched []Commit
+		for _, commit := range s.Commits {
+			if containsHash(hashes, strings.TrimSpace(commit.Hash)) {
+				matched = append(matched, commit)
+			}
+		}
+		if len(matched) > 0 {
+			result = append(result, StoryCommits{Story: s, Commits: matched})
+		}
+	}
+	return result
+}
+
+// containsHash reports whether the set holds the hash or, for abbreviated
+// hashes, a hash starting with it
+func containsHash(hashes map[string]bool, hash string) bool {
+	if hash == "" {
+		return false
+	}
+	if hashes[hash] {
+		return true
+	}
+	if len(hash) >= 40 {
+		return false
+	}
+	for full := range hashes {
+		if strings.HasPrefix(full, hash) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package story
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSelection(t *testing.T) {
+	dir := t.TempDir()
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	originalGitClient := utils.GitClient
+	utils.GitClient = utils.NewRealGit()
+	defer func() {
+		utils.GitClient = originalGitClient
+	}()
+
+	git := func(args ...string) string {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(message string) string {
+		git("commit", "--allow-empty", "-q", "-m", message)
+		return git("rev-parse", "HEAD")
+	}
+
+	// init <- first (v1.0) <- second <- third (main)
+	//             ^-- side
+	git("init", "-q", "-b", "main")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test")
+	initial := commit("init")
+	first := commit("first")
+	git("tag", "-a", "v1.0", "-m", "v1.0")
+	second := commit("second")
+	third := commit("third")
+	git("checkout", "-q", "-b", "side", first)
+	side := commit("side")
+	git("checkout", "-q", "main")
+
+	tests := []struct {
+		name      string
+		selection CommitSelection
+		expected  []string
+		errorMsg  string
+	}{
+		{name: "after a tag", selection: CommitSelection{After: "v1.0"}, expected: []string{second, third, side}},
+		{name: "after a commit", selection: CommitSelection{After: second}, expected: []string{third}},
+		{name: "before a tag", selection: CommitSelection{Before: "v1.0"}, expected: []string{initial, first}},
+		{name: "range", selection: CommitSelection{Range: "v1.0..main"}, expected: []string{second, third}},
+		{name: "after and before", selection: CommitSelection{After: "v1.0", Before: "main~1"}, expected: []string{second}},
+		{name: "unknown revision", selection: CommitSelection{After: "v9.9"}, errorMsg: "unknown revision: v9.9"},
+		{name: "invalid range", selection: CommitSelection{Range: "v1.0"}, errorMsg: "invalid range: v1.0. Use A..B"},
+		{name: "nothing selected", selection: CommitSelection{}, errorMsg: "no commits selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, err := tt.selection.Commits()
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for hash := range hashes {
+				got = append(got, hash)
+			}
+			assert.ElementsMatch(t, tt.expected, got)
+		})
+	}
+
+	t.Run("stories with commits", func(t *testing.T) {
+		stories := []*Story{
+			{ID: "s1", Commits: []Commit{{Hash: first[:7]}}},
+			{ID: "s2", Commits: []Commit{{Hash: second + "\n"}, {Hash: third}}},
+			{ID: "s3"},
+		}
+
+		hashes, err := CommitSelection{After: "v1.0"}.Commits()
+		require.NoError(t, err)
+
+		matches := StoriesWithCommits(stories, hashes)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "s2", matches[0].Story.ID)
+		assert.Len(t, matches[0].Commits, 2)
+
+		hashes, err = CommitSelection{Before: "v1.0"}.Commits()
+		require.NoError(t, err)
+
+		matches = StoriesWithCommits(stories, hashes)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "s1", matches[0].Story.ID)
+	})
+}
@@ -0,0 +1,83 @@
+package story
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// currentStoryFile is the name of the file, inside the stories directory, that
+// holds the filename of the story currently being worked on
+const currentStoryFile = "current"
+
+// currentStoryPath returns the path of the current story pointer file
+func currentStoryPath() (string, error) {
+	storiesDir, err := GetStoriesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storiesDir, currentStoryFile), nil
+}
+
+// SetCurrent marks the given story as the one currently being worked on
+func SetCurrent(s *Story) error {
+	if s.Filename == "" {
+		s.Filename = fmt.Sprintf("%s.yaml", s.ID)
+	}
+
+	path, err := currentStoryPath()
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(path, []byte(s.Filename+"\n"), utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write current story: %w", err)
+	}
+
+	return nil
+}
+
+// GetCurrent returns the story currently being worked on, or nil if none is set
+func GetCurrent() (*Story, error) {
+	path, err := currentStoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read current story: %w", err)
+	}
+
+	filename := strings.TrimSpace(string(data))
+	if filename == "" {
+		return nil, nil
+	}
+
+	s, err := LoadStory(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current story %s: %w", filename, err)
+	}
+
+	return s, nil
+}
+
+// ClearCurrent removes the current story pointer
+func ClearCurrent() error {
+	path, err := currentStoryPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear current story: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package story
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentStory(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	// No current story yet
+	current, err := GetCurrent()
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	s, err := NewStoryWithNumber("Test Story", "Test Description", "john.doe", 42)
+	require.NoError(t, err)
+	require.NoError(t, s.Save())
+
+	// Set and read back
+	require.NoError(t, SetCurrent(s))
+	current, err = GetCurrent()
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, s.ID, current.ID)
+
+	// Clear
+	require.NoError(t, ClearCurrent())
+	current, err = GetCurrent()
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	// Clearing twice is not an error
+	require.NoError(t, ClearCurrent())
+}
+
+func TestCurrentStoryMissingFile(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	// Point at a story that was never saved
+	require.NoError(t, SetCurrent(&Story{ID: "missing"}))
+
+	_, err = GetCurrent()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load current story missing.yaml")
+}
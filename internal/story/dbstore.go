@@ -0,0 +1,298 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// dbFormatVersion is the version of the database file layout
+const dbFormatVersion = 1
+
+// dbFile is the layout of the database file
+type dbFile struct {
+	Version int               `json:"version"`
+	Stories map[string]*Story `json:"stories"`
+}
+
+// DBStore keeps all stories in a single database file. The file is parsed
+// once and kept in memory until another process changes it, so listing and
+// querying thousands of stories does not touch the disk.
+type DBStore struct {
+	path string
+
+	mu      sync.Mutex
+	stories map[string]*Story
+	sorted  []*Story // Newest first
+	modTime time.Time
+	size    int64
+}
+
+// NewDBStore returns a store that keeps its stories in the database file at path
+func NewDBStore(path string) *DBStore {
+	return &DBStore{path: path}
+}
+
+// load reads the database file unless the cached copy is still current.
+// The caller must hold mu.
+func (d *DBStore) load() error {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read story database: %w", err)
+		}
+		d.index(make(map[string]*Story))
+		d.modTime, d.size = time.Time{}, 0
+		return nil
+	}
+
+	if d.stories != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to read story database: %w", err)
+	}
+
+	var file dbFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to unmarshal story database: %w", err)
+	}
+	if file.Version > dbFormatVersion {
+		return fmt.Errorf("story database %s has version %d, this version of tracer supports up to %d",
+			d.path, file.Version, dbFormatVersion)
+	}
+	if file.Stories == nil {
+		file.Stories = make(map[string]*Story)
+	}
+
+	d.index(file.Stories)
+	d.modTime, d.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// index replaces the cached stories. The caller must hold mu.
+func (d *DBStore) index(stories map[string]*Story) {
+	d.stories = stories
+	d.sorted = make([]*Story, 0, len(stories))
+	for id, s := range stories {
+		s.ID = id
+		s.Filename = id + config.DefaultStoryExt
+		d.sorted = append(d.sorted, s)
+	}
+	sort.Slice(d.sorted, func(i, j int) bool {
+		return d.sorted[i].CreatedAt.After(d.sorted[j].CreatedAt)
+	})
+}
+
+// write replaces the database file with the given stories. The file is replaced
+// atomically, so readers never see a partial database. The caller must hold mu
+// and the lock on the stories directory.
+func (d *DBStore) write(stories map[string]*Story) error {
+	data, err := json.Marshal(dbFile{Version: dbFormatVersion, Stories: stories})
+	if err != nil {
+		return fmt.Errorf("failed to marshal story database: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(d.path, data, utils.DefaultFilePerm); err != nil {
+		return fmt.Errorf("failed to write story database: %w", err)
+	}
+
+	// Force the next load to read the file back, the cache holds caller owned stories
+	d.stories = nil
+	return nil
+}
+
+// clone returns a copy of the story that callers may modify freely
+func clone(s *Story) (*Story, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var c Story
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	c.Filename = s.Filename
+	return &c, nil
+}
+
+// cloneAll copies the given stories
+func cloneAll(stories []*Story) ([]*Story, error) {
+	result := make([]*Story, 0, len(stories))
+	for _, s := range stories {
+		c, err := clone(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy story %s: %w", s.ID, err)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// Get returns the story with the given ID
+func (d *DBStore) Get(id string) (*Story, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	s, ok := d.stories[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return clone(s)
+}
+
+// List returns all stories, newest first
+func (d *DBStore) List() ([]*Story, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	return cloneAll(d.sorted)
+}
+
+// Query applies the query to the cached stories and copies only the result
+func (d *DBStore) Query(q Query) ([]*Story, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.load(); err != nil {
+		return nil, 0, err
+	}
+
+	stories := make([]*Story, len(d.sorted))
+	copy(stories, d.sorted)
+
+	result, total := q.Apply(stories)
+	result, err := cloneAll(result)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// Save adds or replaces the story in the database while holding the lock on
+// the directory of the database file
+func (d *DBStore) Save(s *Story) error {
+	return d.SaveChecked(s, nil)
+}
+
+// SaveChecked adds or replaces the story in the database once check accepts
+// it, holding the lock from the check until the database is written
+func (d *DBStore) SaveChecked(s *Story, check func(stored []*Story) error) error {
+	if s.Filename == "" {
+		s.Filename = s.ID + config.DefaultStoryExt
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unlock, err := lockStories(filepath.Dir(d.path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Read the file under the lock, the cache only notices changes at the
+	// resolution of file modification times
+	d.stories = nil
+	if err := d.load(); err != nil {
+		return err
+	}
+	if err := checkRevision(s, d.stories[s.ID]); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(d.sorted); err != nil {
+			return err
+		}
+	}
+
+	stories := make(map[string]*Story, len(d.stories)+1)
+	for id, existing := range d.stories {
+		stories[id] = existing
+	}
+	stories[s.ID] = s
+
+	s.SchemaVersion = CurrentSchemaVersion
+	s.Revision++
+	if err := d.write(stories); err != nil {
+		s.Revision--
+		return err
+	}
+	return nil
+}
+
+// Delete removes the story from the database
+func (d *DBStore) Delete(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unlock, err := lockStories(filepath.Dir(d.path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Read the file under the lock, as Save does
+	d.stories = nil
+	if err := d.load(); err != nil {
+		return err
+	}
+	if _, ok := d.stories[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	stories := make(map[string]*Story, len(d.stories))
+	for storyID, existing := range d.stories {
+		if storyID != id {
+			stories[storyID] = existing
+		}
+	}
+
+	return d.write(stories)
+}
+
+// Watch polls the database file for stories that are saved or deleted
+func (d *DBStore) Watch(ctx context.Context) (<-chan Event, error) {
+	return pollChanges(ctx, d.versions, d.Get)
+}
+
+// versions returns the encoded form of every story, which changes on every save
+func (d *DBStore) versions() (map[string]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string, len(d.stories))
+	for id, s := range d.stories {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		versions[id] = string(data)
+	}
+	return versions, nil
+}
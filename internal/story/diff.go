@@ -0,0 +1,181 @@
+package story
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// Diff output formats
+const (
+	DiffPatch      = "patch"
+	DiffStat       = "stat"
+	DiffNameStatus = "name-status"
+)
+
+// emptyTree is the git hash of the empty tree, diffed against root commits
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// DiffOptions selects the changes shown by Diff
+type DiffOptions struct {
+	From   string    // Revision to diff from, defaults to where the story branched off
+	To     string    // Revision to diff to, defaults to the tip of the story branch
+	Since  time.Time // Only commits made at or after this time
+	Until  time.Time // Only commits made at or before this time
+	Format string    // DiffPatch, DiffStat or DiffNameStatus
+	Paths  []string  // Only changes to these paths
+}
+
+// StoryDiff holds the changes made by a story. They are either the combined
+// diff between two revisions, or the diffs of the story commits one by one.
+type StoryDiff struct {
+	From    string   // Start of the combined diff, empty for commit diffs
+	To      string   // End of the combined diff, empty for commit diffs
+	Commits []Commit // Commits diffed one by one, oldest first
+	Output  string
+}
+
+// formatArgs returns the git diff arguments of a diff output format
+func formatArgs(format string) ([]string, error) {
+	switch format {
+	case "", DiffPatch:
+		return nil, nil
+	case DiffStat:
+		return []string{"--stat"}, nil
+	case DiffNameStatus:
+		return []string{"--name-status"}, nil
+	default:
+		return nil, fmt.Errorf("invalid diff format: %s. Must be one of: %s, %s, %s", format, DiffPatch, DiffStat, DiffNameStatus)
+	}
+}
+
+// Diff returns the changes made by the story.
+//
+// Without time bounds the result is a single combined diff: from the merge
+// base of the story branch and baseBranch to the tip of the story branch,
+// unless other revisions are given. With time bounds, or when the story
+// branch no longer exists and no revision is given, the story commits found
+// by History are diffed one by one instead.
+func Diff(s *Story, baseBranch string, opts DiffOptions) (*StoryDiff, error) {
+	timeBounds := !opts.Since.IsZero() || !opts.Until.IsZero()
+	if timeBounds && (opts.From != "" || opts.To != "") {
+		return nil, fmt.Errorf("revision and time bounds cannot be combined")
+	}
+
+	args, err := formatArgs(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := s.BranchName()
+	branchExists := false
+	if branch != "" {
+		branchExists, err = utils.GitClient.BranchExists(branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check story branch: %w", err)
+		}
+	}
+
+	if !timeBounds && (branchExists || opts.From != "" || opts.To != "") {
+		return diffRange(s, branch, branchExists, baseBranch, opts, args)
+	}
+	return diffCommits(s, baseBranch, opts, args)
+}
+
+// diffRange returns the combined diff between two revisions. Without a story
+// branch they default to the first and last commit of the story history.
+func diffRange(s *Story, branch string, branchExists bool, baseBranch string, opts DiffOptions, args []string) (*StoryDiff, error) {
+	from, to := opts.From, opts.To
+	if branchExists {
+		if to == "" {
+			to = branch
+		}
+		if from == "" {
+			base, err := utils.GitClient.MergeBase(baseBranch, to)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find where story %s branched off %s: %w", s.ID, baseBranch, err)
+			}
+			from = base
+		}
+	} else if from == "" || to == "" {
+		commits, _, err := History(s, baseBranch)
+		if err != nil {
+			return nil, err
+		}
+		if len(commits) == 0 {
+			return nil, fmt.Errorf("story %s has no branch and git knows none of its commits", s.ID)
+		}
+		if from == "" {
+			from = parentOf(commits[0].Hash)
+		}
+		if to == "" {
+			to = commits[len(commits)-1].Hash
+		}
+	}
+
+	output, err := utils.GitClient.Diff(diffArgs(args, from, to, opts.Paths)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoryDiff{From: from, To: to, Output: output}, nil
+}
+
+// parentOf returns the first parent of a commit, or the empty tree for root commits
+func parentOf(hash string) string {
+	parent, err := utils.GitClient.ParseRevision(hash + "^")
+	if err != nil || strings.TrimSpace(parent) == "" {
+		return emptyTree
+	}
+	return strings.TrimSpace(parent)
+}
+
+// diffCommits returns the diffs of the story commits made within the time bounds
+func diffCommits(s *Story, baseBranch string, opts DiffOptions, args []string) (*StoryDiff, error) {
+	commits, _, err := History(s, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StoryDiff{}
+	var output strings.Builder
+	for _, commit := range commits {
+		if !opts.Since.IsZero() && commit.Timestamp.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && commit.Timestamp.After(opts.Until) {
+			continue
+		}
+
+		patch, err := utils.GitClient.Diff(diffArgs(args, parentOf(commit.Hash), commit.Hash, opts.Paths)...)
+		if err != nil {
+			return nil, err
+		}
+		if patch == "" {
+			continue
+		}
+
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		fmt.Fprintf(&output, "commit %s\n%s\n\n%s", commit.Hash, subject, patch)
+		if !strings.HasSuffix(patch, "\n") {
+			output.WriteString("\n")
+		}
+		result.Commits = append(result.Commits, commit)
+	}
+
+	result.Output = output.String()
+	return result, nil
+}
+
+// diffArgs builds the git diff arguments for a diff between two revisions
+func diffArgs(format []string, from, to string, paths []string) []string {
+	args := append([]string{}, format...)
+	args = append(args, from, to)
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+	return args
+}
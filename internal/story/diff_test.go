@@ -0,0 +1,76 @@
+package story
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	s := &Story{ID: "abc111", Title: "First Story", Number: 7}
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var diffs [][]string
+	mockGit := utils.GitClient.(*utils.MockGit)
+	mockGit.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		return []utils.LogEntry{
+			{Hash: "ccc333", Timestamp: base.Add(2 * time.Hour), Message: "fix: abc111"},
+			{Hash: "aaa111", Timestamp: base, Message: "feat: abc111"},
+		}, nil
+	}
+	mockGit.ParseRevisionFunc = func(rev string) (string, error) {
+		if rev == "aaa111^" {
+			return "", assert.AnError // Root commit
+		}
+		return "parent\n", nil
+	}
+	mockGit.DiffFunc = func(args ...string) (string, error) {
+		diffs = append(diffs, args)
+		return "patch", nil
+	}
+
+	t.Run("range of the story commits without a branch", func(t *testing.T) {
+		diffs = nil
+		result, err := Diff(s, "main", DiffOptions{To: "HEAD", Format: DiffStat})
+		require.NoError(t, err)
+		assert.Equal(t, emptyTree, result.From)
+		assert.Equal(t, "HEAD", result.To)
+		assert.Equal(t, [][]string{{"--stat", emptyTree, "HEAD"}}, diffs)
+	})
+
+	t.Run("commits one by one", func(t *testing.T) {
+		diffs = nil
+		result, err := Diff(s, "main", DiffOptions{Since: base.Add(time.Hour), Paths: []string{"main.go"}})
+		require.NoError(t, err)
+		require.Len(t, result.Commits, 1)
+		assert.Equal(t, "ccc333", result.Commits[0].Hash)
+		assert.Equal(t, "commit ccc333\nfix: abc111\n\npatch\n", result.Output)
+		assert.Equal(t, [][]string{{"parent", "ccc333", "--", "main.go"}}, diffs)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		_, err := Diff(s, "main", DiffOptions{Format: "words"})
+		assert.EqualError(t, err, "invalid diff format: words. Must be one of: patch, stat, name-status")
+	})
+}
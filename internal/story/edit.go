@@ -0,0 +1,108 @@
+package story
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validate checks that the story fields hold acceptable values
+func (s *Story) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("story ID cannot be empty")
+	}
+	if strings.TrimSpace(s.Title) == "" && s.Number > 0 {
+		return fmt.Errorf("title is required when the story has a number")
+	}
+	if s.Number < 0 {
+		return fmt.Errorf("number cannot be negative")
+	}
+	if s.Status != "" && !IsValidStatus(s.Status) {
+		return fmt.Errorf("invalid status: %s", s.Status)
+	}
+	for _, tag := range s.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tags cannot be empty")
+		}
+	}
+	return nil
+}
+
+// ValidateEdit checks that an edited story only changes fields users may edit.
+// The ID, author, project, creation time, status, revision and recorded history are owned by tracer.
+func ValidateEdit(original, edited *Story) error {
+	if err := edited.Validate(); err != nil {
+		return err
+	}
+
+	immutable := []struct {
+		field   string
+		changed bool
+	}{
+		{"id", original.ID != edited.ID},
+		{"author", original.Author != edited.Author},
+		{"project", original.Project != edited.Project},
+		{"created_at", !original.CreatedAt.Equal(edited.CreatedAt)},
+		{"status", original.Status != edited.Status},
+		{"revision", original.Revision != edited.Revision},
+		{"transitions", !yamlEqual(original.Transitions, edited.Transitions)},
+		{"commits", !yamlEqual(original.Commits, edited.Commits)},
+		{"files", !yamlEqual(original.Files, edited.Files)},
+	}
+
+	for _, f := range immutable {
+		if f.changed {
+			if f.field == "status" {
+				return fmt.Errorf("field %s cannot be edited. Use 'tracer story start|block|review|done|reopen' instead", f.field)
+			}
+			return fmt.Errorf("field %s cannot be edited", f.field)
+		}
+	}
+
+	return nil
+}
+
+// ParseYAML decodes a story from YAML, rejecting unknown fields. Stories in an
+// older schema version are upgraded.
+func ParseYAML(data []byte) (*Story, error) {
+	s, _, err := decodeStoryYAML(data, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse story: %w", err)
+	}
+
+	return s, nil
+}
+
+// AddTags adds the given tags to the story, skipping those it already has
+func (s *Story) AddTags(tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !containsString(s.Tags, tag) {
+			s.Tags = append(s.Tags, tag)
+		}
+	}
+}
+
+// RemoveTags removes the given tags from the story
+func (s *Story) RemoveTags(tags ...string) {
+	kept := make([]string, 0, len(s.Tags))
+	for _, tag := range s.Tags {
+		if !containsString(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	s.Tags = kept
+}
+
+// yamlEqual compares two slices by their YAML form, so that values that went
+// through a YAML round trip (such as times) compare equal to the originals
+func yamlEqual[T any](a, b []T) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	dataA, errA := yaml.Marshal(a)
+	dataB, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
@@ -0,0 +1,88 @@
+package story
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateEdit(t *testing.T) {
+	now := time.Now()
+	original := &Story{
+		ID:        "story1",
+		Title:     "Story 1",
+		Status:    StatusInProgress,
+		Author:    "john.doe",
+		Number:    1,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Commits:   []Commit{{Hash: "abc123", Message: "feat: add", Timestamp: now}},
+		Filename:  "story1.yaml",
+	}
+
+	tests := []struct {
+		name     string
+		edit     func(s *Story)
+		errorMsg string
+	}{
+		{name: "title", edit: func(s *Story) { s.Title = "New title" }},
+		{name: "description", edit: func(s *Story) { s.Description = "New description" }},
+		{name: "tags", edit: func(s *Story) { s.Tags = []string{"a", "b"} }},
+		{name: "number", edit: func(s *Story) { s.Number = 2 }},
+		{name: "id", edit: func(s *Story) { s.ID = "story2" }, errorMsg: "field id cannot be edited"},
+		{name: "author", edit: func(s *Story) { s.Author = "jane.doe" }, errorMsg: "field author cannot be edited"},
+		{name: "created", edit: func(s *Story) { s.CreatedAt = now.Add(time.Hour) }, errorMsg: "field created_at cannot be edited"},
+		{name: "status", edit: func(s *Story) { s.Status = StatusDone }, errorMsg: "field status cannot be edited. Use 'tracer story"},
+		{name: "commits", edit: func(s *Story) { s.Commits = nil }, errorMsg: "field commits cannot be edited"},
+		{name: "negative number", edit: func(s *Story) { s.Number = -1 }, errorMsg: "number cannot be negative"},
+		{name: "empty title with number", edit: func(s *Story) { s.Title = " " }, errorMsg: "title is required"},
+		{name: "empty tag", edit: func(s *Story) { s.Tags = []string{""} }, errorMsg: "tags cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Round trip through YAML like the editor does
+			data, err := yaml.Marshal(original)
+			require.NoError(t, err)
+			edited, err := ParseYAML(data)
+			require.NoError(t, err)
+
+			tt.edit(edited)
+
+			err = ValidateEdit(original, edited)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	s, err := ParseYAML([]byte("id: story1\ntitle: Story 1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "story1", s.ID)
+	assert.Equal(t, "Story 1", s.Title)
+
+	_, err = ParseYAML([]byte("id: story1\ntitel: Story 1\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field titel not found")
+
+	_, err = ParseYAML([]byte("id: [story1\n"))
+	require.Error(t, err)
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	s := &Story{Tags: []string{"auth"}}
+
+	s.AddTags("api", "auth", " ", "db")
+	assert.Equal(t, []string{"auth", "api", "db"}, s.Tags)
+
+	s.RemoveTags("auth", "missing")
+	assert.Equal(t, []string{"api", "db"}, s.Tags)
+}
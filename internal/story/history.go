@@ -0,0 +1,273 @@
+package story
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// File change statuses
+const (
+	FileAdded    = "added"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+	FileRenamed  = "renamed"
+)
+
+// fileStatuses maps git status letters to file change statuses. Copies are
+// reported as added files that keep the path they were copied from.
+var fileStatuses = map[string]string{
+	"A": FileAdded,
+	"C": FileAdded,
+	"M": FileModified,
+	"T": FileModified,
+	"D": FileDeleted,
+	"R": FileRenamed,
+}
+
+// references returns the strings that mark a commit message as belonging to
+// the story: its ID, Jira key, project qualified number and branch name
+func (s *Story) references() []string {
+	var refs []string
+	if s.ID != "" {
+		refs = append(refs, s.ID)
+	}
+	if s.JiraKey != "" {
+		refs = append(refs, s.JiraKey)
+	}
+	project, _ := utils.GetProjectName()
+	project = projectOf(s, project)
+	if s.Number > 0 && project != "" {
+		refs = append(refs, fmt.Sprintf("%s-%d", project, s.Number))
+	}
+	if branch := s.BranchName(); branch != "" {
+		refs = append(refs, branch)
+	}
+	return refs
+}
+
+// referencePattern matches messages that contain one of the references as a whole word
+func referencePattern(refs []string) *regexp.Regexp {
+	quoted := make([]string, len(refs))
+	for i, ref := range refs {
+		quoted[i] = regexp.QuoteMeta(ref)
+	}
+	return regexp.MustCompile(`(^|[^\w-])(` + strings.Join(quoted, "|") + `)($|[^\w-])`)
+}
+
+// History reads the commits of the story from git, oldest first, along with
+// the files they changed. A commit belongs to the story when it is on the
+// story branch but not on baseBranch, or when its message references the story.
+func History(s *Story, baseBranch string) ([]Commit, []File, error) {
+	seen := make(map[string]bool)
+	var entries []utils.LogEntry
+	collect := func(found []utils.LogEntry, keep func(utils.LogEntry) bool) {
+		for _, entry := range found {
+			if !seen[entry.Hash] && keep(entry) {
+				seen[entry.Hash] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	// Commits on the story branch
+	branch := s.BranchName()
+	if branch != "" {
+		exists, err := utils.GitClient.BranchExists(branch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to check story branch: %w", err)
+		}
+		if exists {
+			revision := branch
+			if baseBranch != "" && baseBranch != branch {
+				if baseExists, err := utils.GitClient.BranchExists(baseBranch); err == nil && baseExists {
+					revision = baseBranch + ".." + branch
+				}
+			}
+			found, err := utils.GitClient.Log(revision)
+			if err != nil {
+				return nil, nil, err
+			}
+			collect(found, func(utils.LogEntry) bool { return true })
+		}
+	}
+
+	// Commits anywhere that reference the story. git only finds candidates,
+	// the pattern makes sure a reference is not part of a longer word.
+	if refs := s.references(); len(refs) > 0 {
+		args := []string{"--all", "--fixed-strings"}
+		for _, ref := range refs {
+			args = append(args, "--grep="+ref)
+		}
+		found, err := utils.GitClient.Log(args...)
+		if err != nil {
+			return nil, nil, err
+		}
+		pattern := referencePattern(refs)
+		collect(found, func(entry utils.LogEntry) bool { return pattern.MatchString(entry.Message) })
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	var commits []Commit
+	var files []File
+	for _, entry := range entries {
+		commit, changes := CommitFromLog(entry)
+		commits = append(commits, commit)
+		files = append(files, changes...)
+	}
+
+	return commits, files, nil
+}
+
+// filesOf returns the file changes of a commit read from git
+func filesOf(entry utils.LogEntry) []File {
+	files := make([]File, 0, len(entry.Files))
+	for _, change := range entry.Files {
+		status, ok := fileStatuses[change.Status]
+		if !ok {
+			status = FileModified
+		}
+		files = append(files, File{
+			Path:      change.Path,
+			OldPath:   change.OldPath,
+			Status:    status,
+			Commit:    entry.Hash,
+			Additions: change.Additions,
+			Deletions: change.Deletions,
+			Timestamp: entry.Timestamp,
+		})
+	}
+	return files
+}
+
+// Timeline returns the commits and file changes of the story, oldest first.
+// They are read from git whenever git knows a commit of the story, so commits
+// made with plain git commit are included. Otherwise, for instance outside of
+// the repository, the log recorded in the story is used.
+func Timeline(s *Story, baseBranch string) ([]Commit, []File) {
+	commits, files, err := History(s, baseBranch)
+	if err != nil || len(commits) == 0 {
+		return s.Commits, s.Files
+	}
+	return commits, files
+}
+
+// Rebuild replaces the commits and files recorded in the story with its
+// history in git. It returns the recorded commits that git does not know as
+// part of the story, for instance because they were rebased away.
+func Rebuild(s *Story, baseBranch string) ([]Commit, error) {
+	commits, files, err := History(s, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []Commit
+	err = Update(s, func(s *Story) error {
+		dropped = nil
+		for _, recorded := range s.Commits {
+			if !containsCommit(commits, recorded.Hash) {
+				dropped = append(dropped, recorded)
+			}
+		}
+		s.Commits = commits
+		s.Files = files
+		s.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dropped, nil
+}
+
+// containsCommit reports whether one of the commits has the given hash, which
+// may be abbreviated
+func containsCommit(commits []Commit, hash string) bool {
+	if hash == "" {
+		return false
+	}
+	for _, c := range commits {
+		if strings.HasPrefix(c.Hash, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitFromLog returns the story commit and file changes of a commit read from git
+func CommitFromLog(entry utils.LogEntry) (Commit, []File) {
+	commit := Commit{
+		Hash:      entry.Hash,
+		Message:   entry.Message,
+		Author:    entry.Author,
+		CoAuthors: coAuthorsOf(entry.Message),
+		Timestamp: entry.Timestamp,
+	}
+	return commit, filesOf(entry)
+}
+
+// coAuthorTrailer matches a Co-authored-by trailer
+var coAuthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:[ \t]*(.+?)[ \t]*$`)
+
+// coAuthorsOf returns the co-authors credited by the trailers of a commit message
+func coAuthorsOf(message string) []string {
+	var coAuthors []string
+	for _, match := range coAuthorTrailer.FindAllStringSubmatch(message, -1) {
+		coAuthors = append(coAuthors, match[1])
+	}
+	return coAuthors
+}
+
+// Authors returns the names of the author and co-authors of the commit
+func (c Commit) Authors() []string {
+	authors := []string{c.Author}
+	for _, coAuthor := range c.CoAuthors {
+		name, _, _ := strings.Cut(coAuthor, " <")
+		authors = append(authors, strings.TrimSpace(name))
+	}
+	return authors
+}
+
+// CommitIndex finds the story a commit belongs to
+type CommitIndex struct {
+	stories  []*Story
+	patterns []*regexp.Regexp // References of each story, nil when it has none
+}
+
+// NewCommitIndex indexes the commits recorded on the stories and their references
+func NewCommitIndex(stories []*Story) *CommitIndex {
+	index := &CommitIndex{stories: stories, patterns: make([]*regexp.Regexp, len(stories))}
+	for i, s := range stories {
+		if refs := s.references(); len(refs) > 0 {
+			index.patterns[i] = referencePattern(refs)
+		}
+	}
+	return index
+}
+
+// StoryOf returns the story that recorded the commit or else the first one
+// the message references, or nil when the commit belongs to none
+func (x *CommitIndex) StoryOf(hash, message string) *Story {
+	for _, s := range x.stories {
+		for _, c := range s.Commits {
+			recorded := strings.TrimSpace(c.Hash)
+			if recorded != "" && strings.HasPrefix(hash, recorded) {
+				return s
+			}
+		}
+	}
+	for i, s := range x.stories {
+		if x.patterns[i] != nil && x.patterns[i].MatchString(message) {
+			return s
+		}
+	}
+	return nil
+}
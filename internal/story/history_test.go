@@ -0,0 +1,183 @@
+package story
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	branch := "features/my-project-7-first-story"
+
+	mockGit := utils.GitClient.(*utils.MockGit)
+	mockGit.GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+	mockGit.BranchExistsFunc = func(name string) (bool, error) {
+		return name == branch || name == "main", nil
+	}
+
+	branchCommits := []utils.LogEntry{
+		{
+			Hash: "ccc333", Author: "Jane", Timestamp: base.Add(2 * time.Hour), Message: "fix: edge case",
+			Files: []utils.FileChange{
+				{Path: "new.go", OldPath: "old.go", Status: "R", Additions: 1, Deletions: 1},
+				{Path: "gone.go", Status: "D", Deletions: 12},
+			},
+		},
+		{
+			Hash: "aaa111", Author: "John", Timestamp: base, Message: "feat: first",
+			Files: []utils.FileChange{{Path: "old.go", Status: "A", Additions: 20}},
+		},
+	}
+	var grepArgs []string
+	logErr := error(nil)
+	mockGit.LogFunc = func(args ...string) ([]utils.LogEntry, error) {
+		if logErr != nil {
+			return nil, logErr
+		}
+		switch args[0] {
+		case "main.." + branch:
+			return branchCommits, nil
+		case "--all":
+			grepArgs = args
+			return []utils.LogEntry{
+				{Hash: "ddd444", Author: "Jane", Timestamp: base.Add(3 * time.Hour), Message: "docs: mention PROJ-123"},
+				{
+					Hash: "bbb222", Author: "Jane", Timestamp: base.Add(time.Hour), Message: "chore: tidy\n\nRefs: PROJ-12",
+					Files: []utils.FileChange{{Path: "README.md", Status: "M", Additions: 2, Deletions: 1}},
+				},
+				branchCommits[0],
+			}, nil
+		}
+		return nil, fmt.Errorf("unexpected log arguments: %v", args)
+	}
+
+	s := &Story{ID: "abc111", Title: "First Story", Number: 7, JiraKey: "PROJ-12", CreatedAt: base}
+
+	t.Run("history", func(t *testing.T) {
+		commits, files, err := History(s, "main")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"--all", "--fixed-strings", "--grep=abc111", "--grep=PROJ-12", "--grep=my-project-7", "--grep=" + branch}, grepArgs)
+
+		// Oldest first, without duplicates or partial references
+		require.Len(t, commits, 3)
+		assert.Equal(t, "aaa111", commits[0].Hash)
+		assert.Equal(t, "bbb222", commits[1].Hash)
+		assert.Equal(t, "ccc333", commits[2].Hash)
+		assert.Equal(t, "Jane", commits[1].Author)
+		assert.Equal(t, base.Add(time.Hour), commits[1].Timestamp)
+
+		assert.Equal(t, []File{
+			{Path: "old.go", Status: FileAdded, Commit: "aaa111", Additions: 20, Timestamp: base},
+			{Path: "README.md", Status: FileModified, Commit: "bbb222", Additions: 2, Deletions: 1, Timestamp: base.Add(time.Hour)},
+			{Path: "new.go", OldPath: "old.go", Status: FileRenamed, Commit: "ccc333", Additions: 1, Deletions: 1, Timestamp: base.Add(2 * time.Hour)},
+			{Path: "gone.go", Status: FileDeleted, Commit: "ccc333", Deletions: 12, Timestamp: base.Add(2 * time.Hour)},
+		}, files)
+	})
+
+	t.Run("rebuild", func(t *testing.T) {
+		s.Commits = []Commit{{Hash: "aaa", Message: "feat: first"}, {Hash: "fff999", Message: "rebased away"}}
+		s.Files = []File{{Path: "old.go", Status: "M"}}
+		require.NoError(t, s.Save())
+
+		dropped, err := Rebuild(s, "main")
+		require.NoError(t, err)
+		require.Len(t, dropped, 1)
+		assert.Equal(t, "fff999", dropped[0].Hash)
+
+		loaded, err := LoadStory(s.Filename)
+		require.NoError(t, err)
+		require.Len(t, loaded.Commits, 3)
+		assert.Equal(t, "aaa111", loaded.Commits[0].Hash)
+		require.Len(t, loaded.Files, 4)
+		assert.Equal(t, FileRenamed, loaded.Files[2].Status)
+		assert.Equal(t, "old.go", loaded.Files[2].OldPath)
+	})
+
+	t.Run("timeline falls back to the recorded log", func(t *testing.T) {
+		recorded := &Story{
+			ID:      "abc222",
+			Title:   "Recorded",
+			Commits: []Commit{{Hash: "eee555"}},
+			Files:   []File{{Path: "main.go", Status: FileModified}},
+		}
+
+		logErr = fmt.Errorf("not a git repository")
+		commits, files := Timeline(recorded, "main")
+		assert.Equal(t, recorded.Commits, commits)
+		assert.Equal(t, recorded.Files, files)
+
+		logErr = nil
+		commits, _ = Timeline(recorded, "main")
+		assert.Equal(t, recorded.Commits, commits)
+
+		commits, _ = Timeline(s, "main")
+		assert.Len(t, commits, 3)
+	})
+}
+
+func TestCommitCoAuthors(t *testing.T) {
+	message := "feat: add export\n\nStory: PROJ-7\nCo-authored-by: Jane Doe <jane@example.com>\nco-authored-by: Sam Smith <sam@example.com>\n"
+
+	commit, _ := CommitFromLog(utils.LogEntry{Hash: "abc123", Author: "John Doe", Message: message})
+	assert.Equal(t, []string{"Jane Doe <jane@example.com>", "Sam Smith <sam@example.com>"}, commit.CoAuthors)
+	assert.Equal(t, []string{"John Doe", "Jane Doe", "Sam Smith"}, commit.Authors())
+
+	s := &Story{}
+	s.AddCommit("abc123", message, "John Doe", time.Now())
+	assert.Equal(t, commit.CoAuthors, s.Commits[0].CoAuthors)
+
+	s.AddCommit("def456", "fix: handle timeouts", "John Doe", time.Now())
+	assert.Empty(t, s.Commits[1].CoAuthors)
+	assert.Equal(t, []string{"John Doe"}, s.Commits[1].Authors())
+}
+
+func TestCommitIndex(t *testing.T) {
+	setupTestRepo(t)
+	mockGit := utils.GitClient.(*utils.MockGit)
+	mockGit.GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	recorded := &Story{ID: "aaaa1111", Title: "Recorded", Number: 7}
+	recorded.AddCommit("abc1234", "feat: first", "John", time.Now())
+	jira := &Story{ID: "bbbb2222", Title: "Linked", Number: 8, JiraKey: "PROJ-12"}
+	index := NewCommitIndex([]*Story{recorded, jira})
+
+	assert.Equal(t, recorded, index.StoryOf("abc1234567890", "feat: first"), "abbreviated recorded hash")
+	assert.Equal(t, recorded, index.StoryOf("fff000", "fix: edge case\n\nStory: my-project-7"))
+	assert.Equal(t, jira, index.StoryOf("fff000", "fix: PROJ-12 edge case"))
+	assert.Nil(t, index.StoryOf("fff000", "fix: my-project-70 edge case"))
+	assert.Nil(t, index.StoryOf("fff000", "chore: tidy"))
+}
@@ -0,0 +1,97 @@
+package story
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Story statuses
+const (
+	StatusOpen       = "open"
+	StatusInProgress = "in-progress"
+	StatusBlocked    = "blocked"
+	StatusInReview   = "in-review"
+	StatusDone       = "done"
+)
+
+// Transition records a single status change of a story
+type Transition struct {
+	From   string    `json:"from" yaml:"from"`
+	To     string    `json:"to" yaml:"to"`
+	By     string    `json:"by" yaml:"by"`
+	At     time.Time `json:"at" yaml:"at"`
+	Reason string    `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// allowedTransitions maps each status to the statuses it may move to
+var allowedTransitions = map[string][]string{
+	StatusOpen:       {StatusInProgress},
+	StatusInProgress: {StatusBlocked, StatusInReview},
+	StatusBlocked:    {StatusInProgress},
+	StatusInReview:   {StatusInProgress, StatusDone},
+	StatusDone:       {StatusOpen},
+}
+
+// IsValidStatus checks if the given status is part of the story lifecycle
+func IsValidStatus(status string) bool {
+	_, ok := allowedTransitions[status]
+	return ok
+}
+
+// AllowedTransitions returns the statuses a story in the given status may move to
+func AllowedTransitions(status string) []string {
+	return allowedTransitions[status]
+}
+
+// CanTransition checks if a story may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo moves the story to the given status, recording who made the change and why
+func (s *Story) TransitionTo(status, by, reason string) error {
+	if !IsValidStatus(status) {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+
+	from := s.Status
+	if from == "" {
+		from = StatusOpen
+	}
+
+	if from == status {
+		return fmt.Errorf("story is already %s", status)
+	}
+
+	if !CanTransition(from, status) {
+		allowed := AllowedTransitions(from)
+		if len(allowed) == 0 {
+			return fmt.Errorf("cannot move story from %s to %s", from, status)
+		}
+		return fmt.Errorf("cannot move story from %s to %s. Allowed: %s", from, status, strings.Join(allowed, ", "))
+	}
+
+	now := time.Now()
+	s.Transitions = append(s.Transitions, Transition{
+		From:   from,
+		To:     status,
+		By:     by,
+		At:     now,
+		Reason: reason,
+	})
+	s.Status = status
+	s.UpdatedAt = now
+
+	return nil
+}
+
+// GetTransitions returns all status transitions of the story
+func (s *Story) GetTransitions() []Transition {
+	return s.Transitions
+}
@@ -0,0 +1,148 @@
+package story
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitionTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		to          string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "open to in-progress",
+			from: StatusOpen,
+			to:   StatusInProgress,
+		},
+		{
+			name: "in-progress to blocked",
+			from: StatusInProgress,
+			to:   StatusBlocked,
+		},
+		{
+			name: "blocked to in-progress",
+			from: StatusBlocked,
+			to:   StatusInProgress,
+		},
+		{
+			name: "in-progress to in-review",
+			from: StatusInProgress,
+			to:   StatusInReview,
+		},
+		{
+			name: "in-review back to in-progress",
+			from: StatusInReview,
+			to:   StatusInProgress,
+		},
+		{
+			name: "in-review to done",
+			from: StatusInReview,
+			to:   StatusDone,
+		},
+		{
+			name: "done to open",
+			from: StatusDone,
+			to:   StatusOpen,
+		},
+		{
+			name: "empty status treated as open",
+			from: "",
+			to:   StatusInProgress,
+		},
+		{
+			name:        "open to done",
+			from:        StatusOpen,
+			to:          StatusDone,
+			expectError: true,
+			errorMsg:    "cannot move story from open to done. Allowed: in-progress",
+		},
+		{
+			name:        "blocked to in-review",
+			from:        StatusBlocked,
+			to:          StatusInReview,
+			expectError: true,
+			errorMsg:    "cannot move story from blocked to in-review. Allowed: in-progress",
+		},
+		{
+			name:        "same status",
+			from:        StatusInProgress,
+			to:          StatusInProgress,
+			expectError: true,
+			errorMsg:    "story is already in-progress",
+		},
+		{
+			name:        "unknown status",
+			from:        StatusOpen,
+			to:          "closed",
+			expectError: true,
+			errorMsg:    "invalid status: closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Story{ID: "story1", Status: tt.from}
+
+			err := s.TransitionTo(tt.to, "john.doe", "some reason")
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Equal(t, tt.errorMsg, err.Error())
+				assert.Equal(t, tt.from, s.Status)
+				assert.Empty(t, s.GetTransitions())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, s.Status)
+			require.Len(t, s.GetTransitions(), 1)
+			transition := s.GetTransitions()[0]
+			assert.Equal(t, tt.to, transition.To)
+			assert.Equal(t, "john.doe", transition.By)
+			assert.Equal(t, "some reason", transition.Reason)
+			assert.NotZero(t, transition.At)
+			assert.Equal(t, transition.At, s.UpdatedAt)
+		})
+	}
+}
+
+func TestTransitionsPersist(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	s, err := NewStory("Test Story", "Test Description", "john.doe")
+	require.NoError(t, err)
+
+	require.NoError(t, s.TransitionTo(StatusInProgress, "john.doe", ""))
+	require.NoError(t, s.TransitionTo(StatusBlocked, "jane.doe", "waiting on API"))
+	require.NoError(t, s.Save())
+
+	loaded, err := LoadStory(s.Filename)
+	require.NoError(t, err)
+	assert.Equal(t, StatusBlocked, loaded.Status)
+	require.Len(t, loaded.Transitions, 2)
+	assert.Equal(t, StatusOpen, loaded.Transitions[0].From)
+	assert.Equal(t, StatusInProgress, loaded.Transitions[0].To)
+	assert.Equal(t, "jane.doe", loaded.Transitions[1].By)
+	assert.Equal(t, "waiting on API", loaded.Transitions[1].Reason)
+}
@@ -0,0 +1,183 @@
+package story
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// NumberCollision groups stories of one project that share a number
+type NumberCollision struct {
+	Project string
+	Number  int
+	Stories []*Story // Oldest first
+}
+
+// Renumbering describes a number change proposed to repair a collision
+type Renumbering struct {
+	Story *Story
+	From  int
+	To    int
+}
+
+// projectOf returns the project a story belongs to. Stories created before
+// projects were recorded belong to the current project.
+func projectOf(s *Story, currentProject string) string {
+	if s.Project != "" {
+		return s.Project
+	}
+	return currentProject
+}
+
+// NumberFromJiraKey extracts the issue number from a Jira key such as PROJ-123
+func NumberFromJiraKey(key string) (int, error) {
+	if !jiraKeyPattern.MatchString(key) {
+		return 0, fmt.Errorf("invalid Jira key: %s", key)
+	}
+
+	number, err := strconv.Atoi(key[strings.LastIndex(key, "-")+1:])
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid Jira key: %s", key)
+	}
+
+	return number, nil
+}
+
+// NextNumber returns the number following the highest one used in the project
+func NextNumber(stories []*Story, project, currentProject string) int {
+	highest := 0
+	for _, s := range stories {
+		if projectOf(s, currentProject) == project && s.Number > highest {
+			highest = s.Number
+		}
+	}
+	return highest + 1
+}
+
+// SaveNew saves a new story and creates its branch. The number is taken and
+// checked while the stories are locked, so concurrent runs cannot get the
+// same one: a story without a number gets the next free one of its project,
+// and a given number must not be used by another story of the project.
+func SaveNew(s *Story) error {
+	store, err := OpenStore()
+	if err != nil {
+		return err
+	}
+
+	current, _ := utils.GetProjectName()
+	check := uniqueNumber(s, current)
+	if err := store.SaveChecked(s, func(stored []*Story) error {
+		if s.Number <= 0 {
+			s.Number = NextNumber(stored, projectOf(s, current), current)
+			return nil
+		}
+		return check(stored)
+	}); err != nil {
+		return err
+	}
+
+	createBranch(s)
+	return nil
+}
+
+// saveNumbered saves a story like Save. When the story takes a new number,
+// the number is checked against the other stories of its project while the
+// stories are locked.
+func saveNumbered(s *Story) error {
+	store, err := OpenStore()
+	if err != nil {
+		return err
+	}
+
+	current, _ := utils.GetProjectName()
+	return store.SaveChecked(s, uniqueNumber(s, current))
+}
+
+// uniqueNumber returns the check of a save that fails when the story takes a
+// number another story of its project uses. A story keeping its stored number
+// passes, so stories already sharing one can still be edited.
+func uniqueNumber(s *Story, currentProject string) func(stored []*Story) error {
+	return func(stored []*Story) error {
+		if s.Number <= 0 {
+			return nil
+		}
+		for _, old := range stored {
+			if old.ID == s.ID && old.Number == s.Number {
+				return nil
+			}
+		}
+		return numberAvailable(stored, s.Number, s.ID, projectOf(s, currentProject), currentProject)
+	}
+}
+
+// numberAvailable returns an error if a story of the project other than the
+// one with exceptID uses the number
+func numberAvailable(stories []*Story, number int, exceptID, project, currentProject string) error {
+	for _, s := range stories {
+		if s.ID != exceptID && s.Number == number && projectOf(s, currentProject) == project {
+			return fmt.Errorf("story number %d is already used by story %s (%s). Next free number is %d",
+				number, s.ID, s.Title, NextNumber(stories, project, currentProject))
+		}
+	}
+	return nil
+}
+
+// FindNumberCollisions returns the groups of stories that share a number within a project
+func FindNumberCollisions(stories []*Story, currentProject string) []NumberCollision {
+	type key struct {
+		project string
+		number  int
+	}
+
+	groups := make(map[key][]*Story)
+	for _, s := range stories {
+		if s.Number <= 0 {
+			continue
+		}
+		k := key{projectOf(s, currentProject), s.Number}
+		groups[k] = append(groups[k], s)
+	}
+
+	var collisions []NumberCollision
+	for k, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].CreatedAt.Before(group[j].CreatedAt)
+		})
+		collisions = append(collisions, NumberCollision{Project: k.project, Number: k.number, Stories: group})
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		if collisions[i].Project != collisions[j].Project {
+			return collisions[i].Project < collisions[j].Project
+		}
+		return collisions[i].Number < collisions[j].Number
+	})
+
+	return collisions
+}
+
+// PlanRenumbering proposes new numbers that resolve all collisions. In each
+// collision the oldest story keeps its number and the others get the next free
+// numbers of their project.
+func PlanRenumbering(stories []*Story, currentProject string) []Renumbering {
+	next := make(map[string]int)
+
+	var plan []Renumbering
+	for _, collision := range FindNumberCollisions(stories, currentProject) {
+		if _, ok := next[collision.Project]; !ok {
+			next[collision.Project] = NextNumber(stories, collision.Project, currentProject)
+		}
+		for _, s := range collision.Stories[1:] {
+			plan = append(plan, Renumbering{Story: s, From: s.Number, To: next[collision.Project]})
+			next[collision.Project]++
+		}
+	}
+
+	return plan
+}
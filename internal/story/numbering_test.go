@@ -0,0 +1,281 @@
+package story
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberFromJiraKey(t *testing.T) {
+	tests := []struct {
+		key         string
+		expected    int
+		expectError bool
+	}{
+		{key: "PROJ-123", expected: 123},
+		{key: "proj_2-7", expected: 7},
+		{key: "PROJ-0", expectError: true},
+		{key: "PROJ", expectError: true},
+		{key: "123", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			number, err := NumberFromJiraKey(tt.key)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid Jira key")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, number)
+		})
+	}
+}
+
+func TestNextNumber(t *testing.T) {
+	stories := []*Story{
+		{ID: "a", Number: 3, Project: "alpha"},
+		{ID: "b", Number: 9, Project: "beta"},
+		{ID: "c", Number: 5},
+	}
+
+	assert.Equal(t, 6, NextNumber(stories, "alpha", "alpha"))
+	assert.Equal(t, 10, NextNumber(stories, "beta", "alpha"))
+	assert.Equal(t, 1, NextNumber(stories, "gamma", "alpha"))
+	assert.Equal(t, 1, NextNumber(nil, "alpha", "alpha"))
+}
+
+func TestFindNumberCollisionsAndPlanRenumbering(t *testing.T) {
+	now := time.Now()
+	stories := []*Story{
+		{ID: "newer", Number: 2, Project: "alpha", CreatedAt: now.Add(time.Hour)},
+		{ID: "older", Number: 2, CreatedAt: now},
+		{ID: "other-project", Number: 2, Project: "beta", CreatedAt: now},
+		{ID: "single", Number: 4, Project: "alpha", CreatedAt: now},
+		{ID: "newest", Number: 2, Project: "alpha", CreatedAt: now.Add(2 * time.Hour)},
+		{ID: "unnumbered-1", CreatedAt: now},
+		{ID: "unnumbered-2", CreatedAt: now},
+	}
+
+	collisions := FindNumberCollisions(stories, "alpha")
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "alpha", collisions[0].Project)
+	assert.Equal(t, 2, collisions[0].Number)
+	require.Len(t, collisions[0].Stories, 3)
+	assert.Equal(t, "older", collisions[0].Stories[0].ID)
+
+	plan := PlanRenumbering(stories, "alpha")
+	require.Len(t, plan, 2)
+	assert.Equal(t, "newer", plan[0].Story.ID)
+	assert.Equal(t, 2, plan[0].From)
+	assert.Equal(t, 5, plan[0].To)
+	assert.Equal(t, "newest", plan[1].Story.ID)
+	assert.Equal(t, 6, plan[1].To)
+}
+
+func TestSaveNewAndCheckNumber(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	utils.GitClient.(*utils.MockGit).GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	s, err := NewDraft("First Story", "", "john.doe")
+	require.NoError(t, err)
+	assert.Equal(t, "my-project", s.Project)
+	require.NoError(t, SaveNew(s))
+	assert.Equal(t, 1, s.Number)
+
+	// Stories of other projects do not take numbers
+	other := &Story{ID: "other", Title: "Other", Number: 7, Project: "other-project", CreatedAt: time.Now()}
+	require.NoError(t, other.Save())
+
+	second, err := NewDraft("Second Story", "", "john.doe")
+	require.NoError(t, err)
+	require.NoError(t, SaveNew(second))
+	assert.Equal(t, 2, second.Number)
+
+	taken, err := NewDraft("Taken", "", "john.doe")
+	require.NoError(t, err)
+	taken.Number = 1
+	err = SaveNew(taken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "story number 1 is already used by story "+s.ID)
+	_, err = LoadStory(taken.ID + ".yaml")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+}
+
+func TestUpdateKeepsNumbersUnique(t *testing.T) {
+	dir := setupTestRepo(t)
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() {
+		require.NoError(t, os.Chdir(currentDir))
+	}()
+
+	utils.GitClient.(*utils.MockGit).GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	now := time.Now()
+	stories := []*Story{
+		{ID: "mine4", Title: "Mine", Number: 4, Project: "my-project", CreatedAt: now},
+		{ID: "legacy", Title: "Legacy", Number: 6, CreatedAt: now}, // Of the current project
+		{ID: "other3", Title: "Other", Number: 3, Project: "other-project", CreatedAt: now},
+		{ID: "other5", Title: "Other five", Number: 5, Project: "other-project", CreatedAt: now},
+		{ID: "dup5", Title: "Duplicate five", Number: 5, Project: "other-project", CreatedAt: now},
+	}
+	for _, s := range stories {
+		require.NoError(t, s.Save())
+	}
+	renumber := func(id string, number int) error {
+		s, err := LoadStory(id + ".yaml")
+		require.NoError(t, err)
+		return Update(s, func(s *Story) error {
+			s.Number = number
+			return nil
+		})
+	}
+
+	// Numbers are checked against the project of the edited story, not the
+	// current one
+	err = renumber("other3", 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "story number 5 is already used by story")
+	assert.Contains(t, err.Error(), "Next free number is 6")
+	assert.NoError(t, renumber("other3", 4))
+	err = renumber("mine4", 6)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "story number 6 is already used by story legacy")
+
+	// Stories already sharing a number can still be edited
+	s, err := LoadStory("dup5.yaml")
+	require.NoError(t, err)
+	assert.NoError(t, Update(s, func(s *Story) error {
+		s.Title = "Renamed"
+		return nil
+	}))
+
+	// Merged edits are checked too
+	base, err := LoadStory("mine4.yaml")
+	require.NoError(t, err)
+	mine := *base
+	mine.Number = 6
+	err = SaveMerged(base, &mine)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "story number 6 is already used by story legacy")
+}
+
+func TestUpdateRenumbersConcurrently(t *testing.T) {
+	dir := setupTestRepo(t)
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() {
+		require.NoError(t, os.Chdir(currentDir))
+	}()
+
+	const runs = 6
+	for i := range runs {
+		s := &Story{ID: fmt.Sprintf("story%d", i), Title: "Story", Number: i + 1, CreatedAt: time.Now()}
+		require.NoError(t, s.Save())
+	}
+
+	// Every story tries to take number 10, only one gets it
+	var wg sync.WaitGroup
+	errs := make([]error, runs)
+	for i := range runs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := LoadStory(fmt.Sprintf("story%d.yaml", i))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = Update(s, func(s *Story) error {
+				s.Number = 10
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Contains(t, err.Error(), "story number 10 is already used by story")
+	}
+	assert.Equal(t, 1, succeeded)
+
+	stories, err := ListStories()
+	require.NoError(t, err)
+	assert.Empty(t, FindNumberCollisions(stories, ""))
+}
+
+func TestSaveNewConcurrently(t *testing.T) {
+	dir := setupTestRepo(t)
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() {
+		require.NoError(t, os.Chdir(currentDir))
+	}()
+
+	const runs = 8
+	var wg sync.WaitGroup
+	stories := make([]*Story, runs)
+	errs := make([]error, runs)
+	for i := range stories {
+		s, err := NewDraft(fmt.Sprintf("Story %d", i), "", "john.doe")
+		require.NoError(t, err)
+		stories[i] = s
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = SaveNew(stories[i])
+		}(i)
+	}
+	wg.Wait()
+
+	numbers := make(map[int]bool)
+	for i, s := range stories {
+		require.NoError(t, errs[i])
+		assert.False(t, numbers[s.Number], "number %d is taken twice", s.Number)
+		numbers[s.Number] = true
+	}
+	assert.Len(t, numbers, runs)
+}
@@ -0,0 +1,164 @@
+package story
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sort keys accepted by Query
+const (
+	SortByCreated = "created"
+	SortByUpdated = "updated"
+	SortByNumber  = "number"
+	SortByTitle   = "title"
+	SortByStatus  = "status"
+)
+
+// SortKeys lists the supported sort keys
+var SortKeys = []string{SortByCreated, SortByUpdated, SortByNumber, SortByTitle, SortByStatus}
+
+// Query describes which stories to select and in which order.
+// Zero values mean no filtering.
+type Query struct {
+	Statuses      []string  // Any of these statuses
+	Tags          []string  // All of these tags
+	Author        string    // Exact author
+	JiraKey       string    // Linked Jira key, case insensitive
+	CreatedAfter  time.Time // Created at or after
+	CreatedBefore time.Time // Created at or before
+	UpdatedAfter  time.Time // Updated at or after
+	UpdatedBefore time.Time // Updated at or before
+	SortBy        string    // One of SortKeys, defaults to SortByCreated
+	Reverse       bool      // Reverse the natural order of SortBy
+	Offset        int       // Number of matching stories to skip
+	Limit         int       // Maximum number of stories to return, 0 for all
+}
+
+// Validate checks the query for unsupported values
+func (q Query) Validate() error {
+	for _, status := range q.Statuses {
+		if !IsValidStatus(status) {
+			return fmt.Errorf("invalid status: %s", status)
+		}
+	}
+
+	if q.SortBy != "" && !isSortKey(q.SortBy) {
+		return fmt.Errorf("invalid sort key: %s. Must be one of: %s", q.SortBy, strings.Join(SortKeys, ", "))
+	}
+
+	if q.Offset < 0 {
+		return fmt.Errorf("offset cannot be negative")
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+
+	return nil
+}
+
+// Matches checks if a story passes the query filters
+func (q Query) Matches(s *Story) bool {
+	if len(q.Statuses) > 0 && !containsString(q.Statuses, s.Status) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !containsString(s.Tags, tag) {
+			return false
+		}
+	}
+	if q.Author != "" && s.Author != q.Author {
+		return false
+	}
+	if q.JiraKey != "" && !strings.EqualFold(s.JiraKey, q.JiraKey) {
+		return false
+	}
+	return inRange(s.CreatedAt, q.CreatedAfter, q.CreatedBefore) &&
+		inRange(s.UpdatedAt, q.UpdatedAfter, q.UpdatedBefore)
+}
+
+// Apply filters, sorts and pages the given stories. It also returns the number of
+// stories that matched before paging.
+func (q Query) Apply(stories []*Story) ([]*Story, int) {
+	var matches []*Story
+	for _, s := range stories {
+		if q.Matches(s) {
+			matches = append(matches, s)
+		}
+	}
+
+	sortStories(matches, q.SortBy, q.Reverse)
+	total := len(matches)
+
+	if q.Offset >= len(matches) {
+		return nil, total
+	}
+	matches = matches[q.Offset:]
+
+	if q.Limit > 0 && q.Limit < len(matches) {
+		matches = matches[:q.Limit]
+	}
+
+	return matches, total
+}
+
+// QueryStories returns the stories selected by the query and the number of
+// stories that matched before paging
+func QueryStories(q Query) ([]*Story, int, error) {
+	store, err := OpenStore()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return store.Query(q)
+}
+
+// sortStories sorts stories by the given key. Dates sort newest first, everything
+// else ascending; reverse flips the order.
+func sortStories(stories []*Story, key string, reverse bool) {
+	less := func(a, b *Story) bool {
+		switch key {
+		case SortByUpdated:
+			return a.UpdatedAt.After(b.UpdatedAt)
+		case SortByNumber:
+			return a.Number < b.Number
+		case SortByTitle:
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		case SortByStatus:
+			return a.Status < b.Status
+		default:
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+	}
+
+	sort.SliceStable(stories, func(i, j int) bool {
+		if reverse {
+			return less(stories[j], stories[i])
+		}
+		return less(stories[i], stories[j])
+	})
+}
+
+func isSortKey(key string) bool {
+	return containsString(SortKeys, key)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && t.After(before) {
+		return false
+	}
+	return true
+}
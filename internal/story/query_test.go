@@ -0,0 +1,142 @@
+package story
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryApply(t *testing.T) {
+	now := time.Now()
+	stories := []*Story{
+		{ID: "s1", Title: "Bravo", Number: 3, Status: StatusOpen, Author: "john.doe", Tags: []string{"auth"}, CreatedAt: now.Add(-3 * time.Hour), UpdatedAt: now.Add(-1 * time.Hour)},
+		{ID: "s2", Title: "alpha", Number: 1, Status: StatusInProgress, Author: "jane.doe", Tags: []string{"auth", "api"}, JiraKey: "PROJ-1", CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-3 * time.Hour)},
+		{ID: "s3", Title: "Charlie", Number: 2, Status: StatusDone, Author: "john.doe", Tags: []string{"api"}, CreatedAt: now.Add(-1 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour)},
+	}
+
+	tests := []struct {
+		name          string
+		query         Query
+		expectedIDs   []string
+		expectedTotal int
+	}{
+		{
+			name:          "no filters sorts newest first",
+			query:         Query{},
+			expectedIDs:   []string{"s3", "s2", "s1"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "any of statuses",
+			query:         Query{Statuses: []string{StatusOpen, StatusDone}},
+			expectedIDs:   []string{"s3", "s1"},
+			expectedTotal: 2,
+		},
+		{
+			name:          "all of tags",
+			query:         Query{Tags: []string{"auth", "api"}},
+			expectedIDs:   []string{"s2"},
+			expectedTotal: 1,
+		},
+		{
+			name:          "author",
+			query:         Query{Author: "john.doe"},
+			expectedIDs:   []string{"s3", "s1"},
+			expectedTotal: 2,
+		},
+		{
+			name:          "jira key ignores case",
+			query:         Query{JiraKey: "proj-1"},
+			expectedIDs:   []string{"s2"},
+			expectedTotal: 1,
+		},
+		{
+			name:          "created range",
+			query:         Query{CreatedAfter: now.Add(-150 * time.Minute), CreatedBefore: now.Add(-30 * time.Minute)},
+			expectedIDs:   []string{"s3", "s2"},
+			expectedTotal: 2,
+		},
+		{
+			name:          "updated after",
+			query:         Query{UpdatedAfter: now.Add(-90 * time.Minute)},
+			expectedIDs:   []string{"s1"},
+			expectedTotal: 1,
+		},
+		{
+			name:          "sort by updated",
+			query:         Query{SortBy: SortByUpdated},
+			expectedIDs:   []string{"s1", "s3", "s2"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "sort by number",
+			query:         Query{SortBy: SortByNumber},
+			expectedIDs:   []string{"s2", "s3", "s1"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "sort by title ignores case",
+			query:         Query{SortBy: SortByTitle},
+			expectedIDs:   []string{"s2", "s1", "s3"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "reverse",
+			query:         Query{SortBy: SortByNumber, Reverse: true},
+			expectedIDs:   []string{"s1", "s3", "s2"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "limit and offset",
+			query:         Query{SortBy: SortByNumber, Offset: 1, Limit: 1},
+			expectedIDs:   []string{"s3"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "offset past the end",
+			query:         Query{Offset: 5},
+			expectedIDs:   []string{},
+			expectedTotal: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, total := tt.query.Apply(stories)
+			ids := []string{}
+			for _, s := range result {
+				ids = append(ids, s.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedTotal, total)
+		})
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    Query
+		errorMsg string
+	}{
+		{name: "valid", query: Query{Statuses: []string{StatusBlocked}, SortBy: SortByTitle, Limit: 5}},
+		{name: "invalid status", query: Query{Statuses: []string{"closed"}}, errorMsg: "invalid status: closed"},
+		{name: "invalid sort key", query: Query{SortBy: "size"}, errorMsg: "invalid sort key: size"},
+		{name: "negative offset", query: Query{Offset: -1}, errorMsg: "offset cannot be negative"},
+		{name: "negative limit", query: Query{Limit: -1}, errorMsg: "limit cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.errorMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package story
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// jiraKeyPattern matches Jira issue keys such as PROJ-123
+var jiraKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*-[0-9]+$`)
+
+// Resolve finds a story from a user supplied reference. The reference may be the
+// story filename, its ID or a unique ID prefix, its number, a branch name created
+// for it, or the key of the Jira issue it is linked to.
+func Resolve(ref string) (*Story, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("story reference cannot be empty")
+	}
+
+	// Exact filename or ID
+	if s, err := loadExact(ref); err == nil {
+		return s, nil
+	}
+
+	stories, err := ListStories()
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are unique within a project only, so stories of the current
+	// project are preferred over those of other projects
+	matchers := []struct {
+		kind      string
+		match     func(*Story) bool
+		byProject bool
+	}{
+		{"number", matchNumber(ref), true},
+		{"Jira key", matchJiraKey(ref), false},
+		{"branch", matchBranch(ref), false},
+		{"ID prefix", matchIDPrefix(ref), false},
+	}
+
+	for _, m := range matchers {
+		if m.match == nil {
+			continue
+		}
+
+		matches := filterStories(stories, m.match)
+		if m.byProject && len(matches) > 1 {
+			if current := filterStories(matches, inCurrentProject()); len(current) > 0 {
+				matches = current
+			}
+		}
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		default:
+			return nil, ambiguousError(ref, m.kind, matches)
+		}
+	}
+
+	return nil, fmt.Errorf("no story found matching %q", ref)
+}
+
+// ResolveOrCurrent resolves the given reference, or, when it is empty, returns the
+// current story or the story whose branch is checked out
+func ResolveOrCurrent(ref string) (*Story, error) {
+	if strings.TrimSpace(ref) != "" {
+		return Resolve(ref)
+	}
+
+	s, err := GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		return s, nil
+	}
+
+	branch, err := utils.GitClient.GetCurrentBranch()
+	if err == nil && branch != "" {
+		if s, err := Resolve(branch); err == nil {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no story given and no current story. Pass a story or run 'tracer story switch <story>' first")
+}
+
+// loadExact loads a story by its exact filename or ID
+func loadExact(ref string) (*Story, error) {
+	// Refuse anything that would escape the stories directory
+	if filepath.Base(ref) != ref {
+		return nil, fmt.Errorf("invalid story filename: %s", ref)
+	}
+
+	return LoadStory(ref)
+}
+
+func matchNumber(ref string) func(*Story) bool {
+	number, err := strconv.Atoi(strings.TrimPrefix(ref, "#"))
+	if err != nil || number <= 0 {
+		return nil
+	}
+	return func(s *Story) bool {
+		return s.Number == number
+	}
+}
+
+// inCurrentProject matches the stories of the current project
+func inCurrentProject() func(*Story) bool {
+	project, _ := utils.GetProjectName()
+	return func(s *Story) bool {
+		return projectOf(s, project) == project
+	}
+}
+
+func matchJiraKey(ref string) func(*Story) bool {
+	if !jiraKeyPattern.MatchString(ref) {
+		return nil
+	}
+	return func(s *Story) bool {
+		return strings.EqualFold(s.JiraKey, ref)
+	}
+}
+
+func matchBranch(ref string) func(*Story) bool {
+	if !strings.Contains(ref, "/") {
+		return nil
+	}
+
+	projectName, _ := utils.GetProjectName()
+	if _, err := utils.ParseBranchName(ref, projectName); err != nil {
+		return nil
+	}
+
+	return func(s *Story) bool {
+		if s.BranchName() == ref {
+			return true
+		}
+
+		// Numbers are unique within a project only, so the branch must name
+		// the story's project, or none for a story of the current project
+		project := projectOf(s, projectName)
+		parsed, err := utils.ParseBranchName(ref, project)
+		if err != nil || parsed.Number <= 0 || parsed.Number != s.Number {
+			return false
+		}
+		return parsed.Project == project || (parsed.Project == "" && project == projectName)
+	}
+}
+
+func matchIDPrefix(ref string) func(*Story) bool {
+	prefix := strings.ToLower(ref)
+	return func(s *Story) bool {
+		return strings.HasPrefix(strings.ToLower(s.ID), prefix)
+	}
+}
+
+func filterStories(stories []*Story, match func(*Story) bool) []*Story {
+	var matches []*Story
+	for _, s := range stories {
+		if match(s) {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+func ambiguousError(ref, kind string, matches []*Story) error {
+	candidates := make([]string, 0, len(matches))
+	for _, s := range matches {
+		candidates = append(candidates, fmt.Sprintf("  %s (#%d) %s", s.ID, s.Number, s.Title))
+	}
+	return fmt.Errorf("story reference %q is ambiguous: %d stories match by %s:\n%s\nUse a longer ID prefix or the full ID",
+		ref, len(matches), kind, strings.Join(candidates, "\n"))
+}
@@ -0,0 +1,187 @@
+package story
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	utils.GitClient.(*utils.MockGit).GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	now := time.Now()
+	stories := []*Story{
+		{ID: "abc111", Title: "First Story", Number: 7, JiraKey: "PROJ-12", CreatedAt: now},
+		{ID: "abc222", Title: "Second Story", Number: 8, CreatedAt: now},
+		{ID: "def333", Title: "Third Story", Number: 9, CreatedAt: now},
+		{ID: "fff444", Title: "Duplicate A", Number: 10, CreatedAt: now},
+		{ID: "fff555", Title: "Duplicate B", Number: 10, CreatedAt: now},
+		{ID: "aaa666", Title: "Other Project", Number: 11, Project: "other-app", CreatedAt: now},
+		{ID: "aaa777", Title: "Other Project Nine", Number: 9, Project: "other-app", CreatedAt: now},
+		{ID: "bbb888", Title: "Other Project Twelve", Number: 12, Project: "other-app", CreatedAt: now},
+		{ID: "ccc999", Title: "Third Project Twelve", Number: 12, Project: "third-app", CreatedAt: now},
+	}
+	for _, s := range stories {
+		require.NoError(t, s.Save())
+	}
+
+	tests := []struct {
+		name        string
+		ref         string
+		expectedID  string
+		expectError bool
+		errorMsg    string
+	}{
+		{name: "by filename", ref: "abc111.yaml", expectedID: "abc111"},
+		{name: "by full ID", ref: "abc222", expectedID: "abc222"},
+		{name: "by number of the current project first", ref: "9", expectedID: "def333"},
+		{name: "by number of another project", ref: "11", expectedID: "aaa666"},
+		{name: "by number with hash", ref: "#8", expectedID: "abc222"},
+		{name: "by unique ID prefix", ref: "def", expectedID: "def333"},
+		{name: "by ID prefix ignoring case", ref: "DEF3", expectedID: "def333"},
+		{name: "by Jira key", ref: "PROJ-12", expectedID: "abc111"},
+		{name: "by Jira key ignoring case", ref: "proj-12", expectedID: "abc111"},
+		{name: "by branch name", ref: "features/my-project-8-second-story", expectedID: "abc222"},
+		{name: "by branch number with renamed title", ref: "features/my-project-9-old-title", expectedID: "def333"},
+		{name: "by branch of another project", ref: "features/other-app-11-old-title", expectedID: "aaa666"},
+		{
+			name:        "branch number of another project",
+			ref:         "features/my-project-11-old-title",
+			expectError: true,
+			errorMsg:    `no story found matching "features/my-project-11-old-title"`,
+		},
+		{
+			name:        "ambiguous ID prefix",
+			ref:         "abc",
+			expectError: true,
+			errorMsg:    `story reference "abc" is ambiguous: 2 stories match by ID prefix`,
+		},
+		{
+			name:        "ambiguous number",
+			ref:         "10",
+			expectError: true,
+			errorMsg:    `story reference "10" is ambiguous: 2 stories match by number`,
+		},
+		{
+			name:        "ambiguous number across other projects",
+			ref:         "12",
+			expectError: true,
+			errorMsg:    `story reference "12" is ambiguous: 2 stories match by number`,
+		},
+		{
+			name:        "unknown reference",
+			ref:         "zzz",
+			expectError: true,
+			errorMsg:    `no story found matching "zzz"`,
+		},
+		{
+			name:        "unknown Jira key",
+			ref:         "PROJ-99",
+			expectError: true,
+			errorMsg:    `no story found matching "PROJ-99"`,
+		},
+		{
+			name:        "path outside stories directory",
+			ref:         "../config",
+			expectError: true,
+			errorMsg:    `no story found matching "../config"`,
+		},
+		{
+			name:        "empty",
+			ref:         "",
+			expectError: true,
+			errorMsg:    "story reference cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Resolve(tt.ref)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, s.ID)
+		})
+	}
+}
+
+func TestResolveOrCurrent(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	mockGit := utils.GitClient.(*utils.MockGit)
+	branch := ""
+	mockGit.GetCurrentBranchFunc = func() (string, error) {
+		return branch, nil
+	}
+
+	s1 := &Story{ID: "abc111", Title: "First Story", Number: 7, CreatedAt: time.Now()}
+	s2 := &Story{ID: "def222", Title: "Second Story", Number: 8, CreatedAt: time.Now()}
+	require.NoError(t, s1.Save())
+	require.NoError(t, s2.Save())
+
+	// Nothing to fall back to
+	_, err = ResolveOrCurrent("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no story given and no current story")
+
+	// Falls back to the checked out branch
+	branch = "features/8-second-story"
+	s, err := ResolveOrCurrent("")
+	require.NoError(t, err)
+	assert.Equal(t, "def222", s.ID)
+
+	// The current story wins over the branch
+	require.NoError(t, SetCurrent(s1))
+	s, err = ResolveOrCurrent("")
+	require.NoError(t, err)
+	assert.Equal(t, "abc111", s.ID)
+
+	// An explicit reference wins over both
+	s, err = ResolveOrCurrent("8")
+	require.NoError(t, err)
+	assert.Equal(t, "def222", s.ID)
+}
@@ -0,0 +1,217 @@
+package story
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Story file schema versions
+const (
+	// SchemaV1 is the layout written before stories had a schema version. Its
+	// keys are the lower-cased Go field names (createdat, jirakey) and it
+	// includes the filename.
+	SchemaV1 = 1
+	// SchemaV2 uses explicit snake_case keys (created_at, jira_key) and records
+	// the schema_version
+	SchemaV2 = 2
+
+	// CurrentSchemaVersion is the version written by this version of tracer
+	CurrentSchemaVersion = SchemaV2
+)
+
+// schemaVersionKey is the key holding the schema version of a story file
+const schemaVersionKey = "schema_version"
+
+// v1Renames maps the SchemaV1 keys that changed to their SchemaV2 names
+var v1Renames = map[string]string{
+	"createdat": "created_at",
+	"updatedat": "updated_at",
+	"jirakey":   "jira_key",
+}
+
+// v1Removed lists the SchemaV1 keys that are no longer stored
+var v1Removed = map[string]bool{
+	"filename": true,
+}
+
+// upgradeNode rewrites a decoded story document of an older schema to the
+// current one in place and returns the version it was written in
+func upgradeNode(doc *yaml.Node) (int, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return 0, fmt.Errorf("story must be a YAML mapping")
+	}
+
+	version := SchemaV1
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != schemaVersionKey {
+			continue
+		}
+		v, err := strconv.Atoi(root.Content[i+1].Value)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid %s: %s", schemaVersionKey, root.Content[i+1].Value)
+		}
+		// Zero is written for stories that were never saved
+		if v > 0 {
+			version = v
+		}
+	}
+
+	if version > CurrentSchemaVersion {
+		return 0, fmt.Errorf("story has schema version %d, this version of tracer supports up to %d. Please upgrade tracer",
+			version, CurrentSchemaVersion)
+	}
+
+	if version == SchemaV1 {
+		content := make([]*yaml.Node, 0, len(root.Content)+2)
+		content = append(content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: schemaVersionKey},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CurrentSchemaVersion)},
+		)
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key := root.Content[i]
+			if v1Removed[key.Value] || key.Value == schemaVersionKey {
+				continue
+			}
+			if renamed, ok := v1Renames[key.Value]; ok {
+				key.Value = renamed
+			}
+			content = append(content, key, root.Content[i+1])
+		}
+		root.Content = content
+	}
+
+	return version, nil
+}
+
+// decodeStoryYAML decodes a story written in any supported schema version and
+// returns it along with that version. In strict mode unknown fields are rejected.
+func decodeStoryYAML(data []byte, strict bool) (*Story, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if doc.Kind == 0 {
+		return nil, 0, fmt.Errorf("story is empty")
+	}
+
+	version, err := upgradeNode(&doc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var s Story
+	if !strict {
+		if err := doc.Decode(&s); err != nil {
+			return nil, 0, err
+		}
+		return &s, version, nil
+	}
+
+	upgraded, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(upgraded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&s); err != nil {
+		return nil, 0, err
+	}
+
+	return &s, version, nil
+}
+
+// encodeStoryYAML encodes the story in the current schema version
+func encodeStoryYAML(s *Story) ([]byte, error) {
+	s.SchemaVersion = CurrentSchemaVersion
+	return yaml.Marshal(s)
+}
+
+// MigrateYAML rewrites a story file to the current schema version. It returns
+// the rewritten file and the version the file was in; files that are already
+// current are returned unchanged.
+func MigrateYAML(data []byte) ([]byte, int, error) {
+	s, version, err := decodeStoryYAML(data, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == CurrentSchemaVersion {
+		return data, version, nil
+	}
+
+	migrated, err := encodeStoryYAML(s)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal story: %w", err)
+	}
+	return migrated, version, nil
+}
+
+// FileMigration describes a story file rewritten to the current schema version
+type FileMigration struct {
+	Path string
+	From int
+	Old  []byte
+	New  []byte
+}
+
+// MigrateStoryFiles rewrites every story file in the stories directory that is
+// not in the current schema version. With dryRun the files are left untouched
+// and only the migrations that would be made are returned.
+func MigrateStoryFiles(dryRun bool) ([]FileMigration, error) {
+	storiesDir, err := GetStoriesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockStories(storiesDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	files, err := os.ReadDir(storiesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stories directory: %w", err)
+	}
+
+	var migrations []FileMigration
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), config.DefaultStoryExt) {
+			continue
+		}
+
+		path := filepath.Join(storiesDir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read story file: %w", err)
+		}
+
+		migrated, version, err := MigrateYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate story %s: %w", file.Name(), err)
+		}
+		if version == CurrentSchemaVersion {
+			continue
+		}
+
+		if !dryRun {
+			if err := utils.WriteFileAtomic(path, migrated, utils.DefaultFilePerm); err != nil {
+				return nil, fmt.Errorf("failed to write story file: %w", err)
+			}
+		}
+		migrations = append(migrations, FileMigration{Path: path, From: version, Old: data, New: migrated})
+	}
+
+	return migrations, nil
+}
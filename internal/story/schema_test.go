@@ -0,0 +1,114 @@
+package story
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v1Story is a story as written before stories had a schema version
+const v1Story = `id: abc123
+title: Legacy story
+description: Written by an old tracer
+status: open
+createdat: 2024-01-02T03:04:05Z
+updatedat: 2024-01-03T03:04:05Z
+author: john.doe
+tags:
+    - legacy
+jirakey: PROJ-7
+number: 7
+commits: []
+files: []
+filename: abc123.yaml
+`
+
+func TestDecodeStoryYAML(t *testing.T) {
+	s, version, err := decodeStoryYAML([]byte(v1Story), true)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaV1, version)
+	assert.Equal(t, CurrentSchemaVersion, s.SchemaVersion)
+	assert.Equal(t, "Legacy story", s.Title)
+	assert.Equal(t, "PROJ-7", s.JiraKey)
+	assert.Equal(t, 2024, s.CreatedAt.Year())
+	assert.Equal(t, 3, s.UpdatedAt.Day())
+
+	_, _, err = decodeStoryYAML([]byte("schema_version: 99\nid: abc123\n"), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schema version 99")
+
+	_, _, err = decodeStoryYAML([]byte("- not a story\n"), false)
+	require.Error(t, err)
+}
+
+func TestMigrateYAML(t *testing.T) {
+	migrated, version, err := MigrateYAML([]byte(v1Story))
+	require.NoError(t, err)
+	assert.Equal(t, SchemaV1, version)
+
+	content := string(migrated)
+	assert.Contains(t, content, "schema_version: 2\n")
+	assert.Contains(t, content, "created_at: 2024-01-02T03:04:05Z\n")
+	assert.Contains(t, content, "jira_key: PROJ-7\n")
+	assert.NotContains(t, content, "createdat")
+	assert.NotContains(t, content, "filename")
+
+	// Current files are left untouched
+	again, version, err := MigrateYAML(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, version)
+	assert.Equal(t, migrated, again)
+}
+
+func TestMigrateStoryFiles(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	storiesDir, err := GetStoriesDir()
+	require.NoError(t, err)
+	legacyPath := filepath.Join(storiesDir, "abc123.yaml")
+	require.NoError(t, os.WriteFile(legacyPath, []byte(v1Story), 0600))
+	require.NoError(t, (&Story{ID: "def456", Title: "Current story"}).Save())
+
+	// Legacy stories are readable before migrating
+	s, err := LoadStory("abc123.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "PROJ-7", s.JiraKey)
+
+	migrations, err := MigrateStoryFiles(true)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	assert.Equal(t, legacyPath, migrations[0].Path)
+	assert.Equal(t, SchemaV1, migrations[0].From)
+	data, err := os.ReadFile(legacyPath)
+	require.NoError(t, err)
+	assert.Equal(t, v1Story, string(data))
+
+	migrations, err = MigrateStoryFiles(false)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+	data, err = os.ReadFile(legacyPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "jira_key: PROJ-7")
+
+	migrations, err = MigrateStoryFiles(false)
+	require.NoError(t, err)
+	assert.Empty(t, migrations)
+}
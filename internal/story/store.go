@@ -0,0 +1,228 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// ErrNotFound is returned by a Store when no story has the requested ID
+var ErrNotFound = errors.New("story not found")
+
+// ErrConflict is returned by Store.Save when the story was saved by someone
+// else after the copy being saved was loaded
+var ErrConflict = errors.New("story was changed by another process")
+
+// storiesLockFile is the advisory lock file, inside the stories directory,
+// held while stories are written
+const storiesLockFile = ".lock"
+
+// Event types reported by Store.Watch
+const (
+	EventSaved   = "saved"
+	EventDeleted = "deleted"
+)
+
+// Event reports a change made to a stored story, by this or another process
+type Event struct {
+	Type  string // EventSaved or EventDeleted
+	ID    string
+	Story *Story // The saved story, nil for deletions
+}
+
+// Store persists stories. Commands go through the package level functions
+// (LoadStory, ListStories, SaveStory, QueryStories), which use the store
+// selected by the story_store setting of the configuration.
+type Store interface {
+	// Get returns the story with the given ID, or ErrNotFound
+	Get(id string) (*Story, error)
+	// List returns all stories, newest first
+	List() ([]*Story, error)
+	// Query returns the stories selected by the query and the number of
+	// stories that matched before paging
+	Query(q Query) ([]*Story, int, error)
+	// Save creates or replaces a story. It fails with ErrConflict when the
+	// stored story has a different revision than s, and increments the
+	// revision of s on success.
+	Save(s *Story) error
+	// SaveChecked saves a story like Save once check accepts it. check is
+	// given the stored stories, which it must not change, while the store is
+	// locked, and may change s, for instance to give it a number.
+	SaveChecked(s *Story, check func(stored []*Story) error) error
+	// Delete removes a story, or returns ErrNotFound
+	Delete(id string) error
+	// Watch reports changes to the stored stories until the context is done
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// watchInterval is how often stores check for changes while watched
+var watchInterval = time.Second
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]Store)
+)
+
+// OpenStore returns the story store selected in the configuration. Stores are
+// opened once per location, so caches survive between calls.
+func OpenStore() (Store, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	storiesDir, err := GetStoriesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	key := cfg.StoryStore + ":" + storiesDir
+	if s, ok := stores[key]; ok {
+		return s, nil
+	}
+
+	var s Store
+	switch cfg.StoryStore {
+	case config.StoryStoreYAML:
+		s = NewYAMLStore(storiesDir)
+	case config.StoryStoreDB:
+		s, err = openDBStore(filepath.Join(storiesDir, config.DefaultStoryDBFile), storiesDir)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown story store: %s. Must be one of: %s, %s",
+			cfg.StoryStore, config.StoryStoreYAML, config.StoryStoreDB)
+	}
+
+	stores[key] = s
+	return s, nil
+}
+
+// openDBStore opens the database store at path. A new database is seeded with
+// the YAML stories found in storiesDir, so switching backends keeps history.
+func openDBStore(path, storiesDir string) (*DBStore, error) {
+	_, statErr := os.Stat(path)
+	db := NewDBStore(path)
+	if !os.IsNotExist(statErr) {
+		return db, nil
+	}
+
+	if _, err := ImportStories(db, NewYAMLStore(storiesDir)); err != nil {
+		return nil, fmt.Errorf("failed to import stories into %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// ImportStories copies every story of src into dst and returns how many were copied
+func ImportStories(dst, src Store) (int, error) {
+	stories, err := src.List()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, s := range stories {
+		if err := dst.Save(s); err != nil {
+			return 0, fmt.Errorf("failed to save story %s: %w", s.ID, err)
+		}
+	}
+
+	return len(stories), nil
+}
+
+// lockStories takes the advisory lock on the stories directory dir. Writers
+// hold it from reading the stored revision until the new file is in place.
+func lockStories(dir string) (func(), error) {
+	return utils.LockFile(filepath.Join(dir, storiesLockFile))
+}
+
+// checkRevision returns ErrConflict when the stored story, if any, has moved on
+// since s was loaded
+func checkRevision(s, stored *Story) error {
+	if stored == nil || stored.Revision == s.Revision {
+		return nil
+	}
+	return fmt.Errorf("%w: story %s is at revision %d, the copy being saved is at revision %d",
+		ErrConflict, s.ID, stored.Revision, s.Revision)
+}
+
+// idFromFilename returns the story ID stored in the given filename
+func idFromFilename(filename string) string {
+	return strings.TrimSuffix(filename, config.DefaultStoryExt)
+}
+
+// pollChanges reports changes by comparing the story versions returned by
+// versions every watchInterval. A version is any string that changes when the
+// story is saved.
+func pollChanges(ctx context.Context, versions func() (map[string]string, error), get func(id string) (*Story, error)) (<-chan Event, error) {
+	seen, err := versions()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		send := func(e Event) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := versions()
+			if err != nil {
+				continue
+			}
+
+			for id, version := range current {
+				if seen[id] == version {
+					continue
+				}
+				s, err := get(id)
+				if err != nil {
+					continue
+				}
+				if !send(Event{Type: EventSaved, ID: id, Story: s}) {
+					return
+				}
+			}
+			for id := range seen {
+				if _, ok := current[id]; ok {
+					continue
+				}
+				if !send(Event{Type: EventDeleted, ID: id}) {
+					return
+				}
+			}
+
+			seen = current
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,205 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestStores(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(dir string) Store
+	}{
+		{name: "yaml", open: func(dir string) Store { return NewYAMLStore(dir) }},
+		{name: "db", open: func(dir string) Store { return NewDBStore(filepath.Join(dir, "stories.db")) }},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			store := backend.open(t.TempDir())
+			now := time.Now().Truncate(time.Second)
+
+			stories, err := store.List()
+			require.NoError(t, err)
+			assert.Empty(t, stories)
+
+			older := &Story{ID: "aaa111", Title: "Older", Status: StatusOpen, Number: 1, Tags: []string{"auth"}, CreatedAt: now.Add(-time.Hour)}
+			newer := &Story{ID: "bbb222", Title: "Newer", Status: StatusDone, Number: 2, CreatedAt: now}
+			require.NoError(t, store.Save(older))
+			require.NoError(t, store.Save(newer))
+			assert.Equal(t, "aaa111.yaml", older.Filename)
+
+			s, err := store.Get("aaa111")
+			require.NoError(t, err)
+			assert.Equal(t, "Older", s.Title)
+			assert.Equal(t, []string{"auth"}, s.Tags)
+			assert.Equal(t, "aaa111.yaml", s.Filename)
+
+			_, err = store.Get("missing")
+			assert.True(t, errors.Is(err, ErrNotFound))
+
+			stories, err = store.List()
+			require.NoError(t, err)
+			require.Len(t, stories, 2)
+			assert.Equal(t, "bbb222", stories[0].ID)
+
+			result, total, err := store.Query(Query{Statuses: []string{StatusOpen}})
+			require.NoError(t, err)
+			assert.Equal(t, 1, total)
+			require.Len(t, result, 1)
+			assert.Equal(t, "aaa111", result[0].ID)
+
+			_, _, err = store.Query(Query{SortBy: "size"})
+			assert.Error(t, err)
+
+			// Changes to returned stories are not visible until saved
+			s.Title = "Changed"
+			s2, err := store.Get("aaa111")
+			require.NoError(t, err)
+			assert.Equal(t, "Older", s2.Title)
+			require.NoError(t, store.Save(s))
+			s2, err = store.Get("aaa111")
+			require.NoError(t, err)
+			assert.Equal(t, "Changed", s2.Title)
+
+			require.NoError(t, store.Delete("aaa111"))
+			_, err = store.Get("aaa111")
+			assert.True(t, errors.Is(err, ErrNotFound))
+			assert.True(t, errors.Is(store.Delete("aaa111"), ErrNotFound))
+		})
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	originalInterval := watchInterval
+	watchInterval = 10 * time.Millisecond
+	defer func() {
+		watchInterval = originalInterval
+	}()
+
+	for _, store := range []Store{NewYAMLStore(t.TempDir()), NewDBStore(filepath.Join(t.TempDir(), "stories.db"))} {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		events, err := store.Watch(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Save(&Story{ID: "abc123", Title: "Watched", CreatedAt: time.Now()}))
+		select {
+		case e := <-events:
+			assert.Equal(t, EventSaved, e.Type)
+			assert.Equal(t, "abc123", e.ID)
+			require.NotNil(t, e.Story)
+			assert.Equal(t, "Watched", e.Story.Title)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event for saved story")
+		}
+
+		require.NoError(t, store.Delete("abc123"))
+		select {
+		case e := <-events:
+			assert.Equal(t, EventDeleted, e.Type)
+			assert.Equal(t, "abc123", e.ID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event for deleted story")
+		}
+
+		cancel()
+		for range events {
+		}
+	}
+}
+
+func TestDBStoreSeesOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stories.db")
+	first := NewDBStore(path)
+	second := NewDBStore(path)
+
+	require.NoError(t, first.Save(&Story{ID: "abc123", Title: "From first", CreatedAt: time.Now()}))
+	s, err := second.Get("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "From first", s.Title)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+	_, err = first.List()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has version 99")
+}
+
+func TestDBStoreDeleteKeepsStoriesOfOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stories.db")
+	first := NewDBStore(path)
+	second := NewDBStore(path)
+
+	require.NoError(t, first.Save(&Story{ID: "abc123", Title: "From first", CreatedAt: time.Now()}))
+	require.NoError(t, second.Save(&Story{ID: "def456", Title: "From second", CreatedAt: time.Now()}))
+	require.NoError(t, first.Delete("abc123"))
+
+	stories, err := NewDBStore(path).List()
+	require.NoError(t, err)
+	require.Len(t, stories, 1)
+	assert.Equal(t, "def456", stories[0].ID)
+}
+
+func TestOpenStore(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	writeConfig := func(backend string) {
+		repoConfigDir, err := utils.GetRepoConfigDir()
+		require.NoError(t, err)
+		data, err := yaml.Marshal(&config.Config{StoryStore: backend})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(repoConfigDir, config.DefaultConfigFile), data, 0600))
+	}
+
+	// Stories are kept as YAML files by default
+	s := &Story{ID: "abc123", Title: "Stored as YAML", CreatedAt: time.Now()}
+	require.NoError(t, s.Save())
+	storiesDir, err := GetStoriesDir()
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(storiesDir, "abc123.yaml"))
+
+	// A new database is seeded with the existing YAML stories
+	writeConfig(config.StoryStoreDB)
+	store, err := OpenStore()
+	require.NoError(t, err)
+	assert.IsType(t, &DBStore{}, store)
+	assert.FileExists(t, filepath.Join(storiesDir, config.DefaultStoryDBFile))
+
+	loaded, err := LoadStory("abc123.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "Stored as YAML", loaded.Title)
+
+	resolved, err := Resolve("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", resolved.ID)
+
+	writeConfig("postgres")
+	_, err = OpenStore()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown story store: postgres")
+}
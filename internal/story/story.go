@@ -2,74 +2,57 @@ package story
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"time"
 
 	"github.com/helmedeiros/tracer-bullet/internal/utils"
-	"gopkg.in/yaml.v3"
 )
 
 // Commit represents a Git commit associated with a story
 type Commit struct {
-	Hash      string    `json:"hash"`
-	Message   string    `json:"message"`
-	Author    string    `json:"author"`
-	Timestamp time.Time `json:"timestamp"`
+	Hash      string    `json:"hash" yaml:"hash"`
+	Message   string    `json:"message" yaml:"message"`
+	Author    string    `json:"author" yaml:"author"`
+	CoAuthors []string  `json:"co_authors,omitempty" yaml:"co_authors,omitempty"` // "Name <email>" of each Co-authored-by trailer
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
 }
 
 // File represents a file modified as part of a story
 type File struct {
-	Path      string    `json:"path"`
-	Status    string    `json:"status"` // added, modified, deleted
-	Timestamp time.Time `json:"timestamp"`
+	Path      string    `json:"path" yaml:"path"`
+	OldPath   string    `json:"old_path,omitempty" yaml:"old_path,omitempty"` // Path before a rename or copy
+	Status    string    `json:"status" yaml:"status"`                         // added, modified, deleted, renamed
+	Commit    string    `json:"commit,omitempty" yaml:"commit,omitempty"`     // Hash of the commit that changed the file
+	Additions int       `json:"additions,omitempty" yaml:"additions,omitempty"`
+	Deletions int       `json:"deletions,omitempty" yaml:"deletions,omitempty"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
 }
 
 // Story represents a development story
 type Story struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Author      string    `json:"author"`
-	Tags        []string  `json:"tags"`
-	JiraKey     string    `json:"jira_key,omitempty"`
-	Number      int       `json:"number,omitempty"`
-	Commits     []Commit  `json:"commits,omitempty"`
-	Files       []File    `json:"files,omitempty"`
-	Filename    string    `json:"-"`
+	SchemaVersion int          `json:"schema_version" yaml:"schema_version"` // See CurrentSchemaVersion
+	ID            string       `json:"id" yaml:"id"`
+	Title         string       `json:"title" yaml:"title"`
+	Description   string       `json:"description" yaml:"description"`
+	Status        string       `json:"status" yaml:"status"`
+	CreatedAt     time.Time    `json:"created_at" yaml:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at" yaml:"updated_at"`
+	Author        string       `json:"author" yaml:"author"`
+	Tags          []string     `json:"tags" yaml:"tags"`
+	JiraKey       string       `json:"jira_key,omitempty" yaml:"jira_key,omitempty"`
+	Number        int          `json:"number,omitempty" yaml:"number,omitempty"`
+	Project       string       `json:"project,omitempty" yaml:"project,omitempty"`
+	Commits       []Commit     `json:"commits,omitempty" yaml:"commits,omitempty"`
+	Files         []File       `json:"files,omitempty" yaml:"files,omitempty"`
+	Transitions   []Transition `json:"transitions,omitempty" yaml:"transitions,omitempty"`
+	Revision      int          `json:"revision" yaml:"revision"` // Incremented by every save, see Store.Save
+	Filename      string       `json:"-" yaml:"-"`
 }
 
 // NewStory creates a new story with the given title and description
 func NewStory(title, description, author string) (*Story, error) {
-	now := time.Now()
-	story := &Story{
-		ID:          utils.GenerateID(),
-		Title:       title,
-		Description: description,
-		Status:      "open",
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		Author:      author,
-		Tags:        []string{},
-		Number:      0,
-	}
-
-	// Get project name from git config
-	projectName, _ := utils.GetProjectName()
-
-	// Try to create a git branch for the story
-	branchName := utils.GenerateBranchName(title, story.ID, story.Number, projectName)
-	if err := utils.CreateBranch(branchName); err != nil {
-		// If we can't create a branch, it's not a critical error
-		// The story will still be created, but we'll log the error
-		fmt.Printf("Warning: Failed to create git branch: %v\n", err)
-	}
-
+	story := newStory(title, description, author, 0)
+	createBranch(story)
 	return story, nil
 }
 
@@ -83,31 +66,73 @@ func NewStoryWithNumber(title, description, author string, number int) (*Story,
 		return nil, fmt.Errorf("title is required when creating a story with a number")
 	}
 
+	story := newStory(title, description, author, number)
+	createBranch(story)
+	return story, nil
+}
+
+// NewDraft creates a story that is neither numbered nor saved, and has no
+// branch yet. SaveNew numbers it, saves it and creates its branch.
+func NewDraft(title, description, author string) (*Story, error) {
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	return newStory(title, description, author, 0), nil
+}
+
+// newStory returns a story of the current project
+func newStory(title, description, author string, number int) *Story {
 	now := time.Now()
 	story := &Story{
-		ID:          utils.GenerateID(),
-		Title:       title,
-		Description: description,
-		Status:      "open",
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		Author:      author,
-		Tags:        []string{},
-		Number:      number,
+		SchemaVersion: CurrentSchemaVersion,
+		ID:            utils.GenerateID(),
+		Title:         title,
+		Description:   description,
+		Status:        StatusOpen,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Author:        author,
+		Tags:          []string{},
+		Number:        number,
 	}
 
 	// Get project name from git config
 	projectName, _ := utils.GetProjectName()
+	story.Project = projectName
+
+	return story
+}
 
-	// Try to create a git branch for the story
-	branchName := utils.GenerateBranchName(title, story.ID, story.Number, projectName)
+// createBranch tries to create the git branch of the story
+func createBranch(story *Story) {
+	branchName := utils.GenerateBranchName(story.Title, story.ID, story.Number, story.Project)
 	if err := utils.CreateBranch(branchName); err != nil {
 		// If we can't create a branch, it's not a critical error
 		// The story will still be created, but we'll log the error
 		fmt.Printf("Warning: Failed to create git branch: %v\n", err)
 	}
+}
 
-	return story, nil
+// BranchName returns the git branch name for the story, named after its
+// project
+func (s *Story) BranchName() string {
+	projectName, _ := utils.GetProjectName()
+
+	return utils.GenerateBranchName(s.Title, s.ID, s.Number, projectOf(s, projectName))
+}
+
+// Key returns the reference to the story added to commit messages: its Jira
+// key, its project and number (e.g. tracer-42), or else its ID
+func (s *Story) Key() string {
+	if s.JiraKey != "" {
+		return s.JiraKey
+	}
+	project, _ := utils.GetProjectName()
+	project = projectOf(s, project)
+	if s.Number > 0 && project != "" {
+		return fmt.Sprintf("%s-%d", project, s.Number)
+	}
+	return s.ID
 }
 
 // Save saves the story to disk
@@ -119,76 +144,34 @@ func (s *Story) Save() error {
 	return SaveStory(s)
 }
 
-// LoadStory loads a story from a file
+// LoadStory loads a story by its filename from the configured store
 func LoadStory(filename string) (*Story, error) {
-	storiesDir, err := GetStoriesDir()
+	store, err := OpenStore()
 	if err != nil {
 		return nil, err
 	}
 
-	filePath := filepath.Join(storiesDir, filename)
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read story file: %w", err)
-	}
-
-	var story Story
-	if err := yaml.Unmarshal(data, &story); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal story: %w", err)
-	}
-
-	return &story, nil
+	return store.Get(idFromFilename(filename))
 }
 
-// ListStories returns a list of all stories
+// ListStories returns a list of all stories, newest first
 func ListStories() ([]*Story, error) {
-	storiesDir, err := GetStoriesDir()
+	store, err := OpenStore()
 	if err != nil {
 		return nil, err
 	}
 
-	files, err := os.ReadDir(storiesDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read stories directory: %w", err)
-	}
-
-	var stories []*Story
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".yaml") {
-			story, err := LoadStory(file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to load story %s: %w", file.Name(), err)
-			}
-			stories = append(stories, story)
-		}
-	}
-
-	// Sort stories by creation date in descending order (newest first)
-	sort.Slice(stories, func(i, j int) bool {
-		return stories[i].CreatedAt.After(stories[j].CreatedAt)
-	})
-
-	return stories, nil
+	return store.List()
 }
 
-// SaveStory saves a story to a file
+// SaveStory saves a story to the configured store
 func SaveStory(story *Story) error {
-	storiesDir, err := GetStoriesDir()
+	store, err := OpenStore()
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(story)
-	if err != nil {
-		return fmt.Errorf("failed to marshal story: %w", err)
-	}
-
-	filePath := filepath.Join(storiesDir, story.Filename)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write story file: %w", err)
-	}
-
-	return nil
+	return store.Save(story)
 }
 
 // AddCommit adds a commit to the story
@@ -197,6 +180,7 @@ func (s *Story) AddCommit(hash, message, author string, timestamp time.Time) {
 		Hash:      hash,
 		Message:   message,
 		Author:    author,
+		CoAuthors: coAuthorsOf(message),
 		Timestamp: timestamp,
 	})
 	s.UpdatedAt = time.Now()
@@ -179,6 +179,56 @@ func TestNewStory(t *testing.T) {
 	}
 }
 
+func TestNewDraft(t *testing.T) {
+	originalGitClient := utils.GitClient
+	defer func() {
+		utils.GitClient = originalGitClient
+	}()
+
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	utils.GitClient = mockGit
+	mockGit.CreateBranchFunc = func(branchName string) error {
+		t.Errorf("a draft has no branch, got %s", branchName)
+		return nil
+	}
+	mockGit.GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	_, err := NewDraft("", "Test Description", "john.doe")
+	assert.EqualError(t, err, "title is required")
+
+	story, err := NewDraft("Test Story", "Test Description", "john.doe")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Story", story.Title)
+	assert.Equal(t, "my-project", story.Project)
+	assert.Zero(t, story.Number)
+	assert.Equal(t, StatusOpen, story.Status)
+}
+
+func TestBranchName(t *testing.T) {
+	originalGitClient := utils.GitClient
+	defer func() {
+		utils.GitClient = originalGitClient
+	}()
+
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	utils.GitClient = mockGit
+	mockGit.GetConfigFunc = func(key string) (string, error) {
+		if key == "current.project" {
+			return "my-project", nil
+		}
+		return "", nil
+	}
+
+	assert.Equal(t, "features/my-project-7-add-login", (&Story{Title: "Add login", Number: 7}).BranchName())
+	assert.Equal(t, "features/my-project-7-add-login", (&Story{Title: "Add login", Number: 7, Project: "my-project"}).BranchName())
+	assert.Equal(t, "features/other-app-7-add-login", (&Story{Title: "Add login", Number: 7, Project: "other-app"}).BranchName())
+}
+
 func TestNewStory_BranchExists(t *testing.T) {
 	// Set up mock git client
 	originalGitClient := utils.GitClient
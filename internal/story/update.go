@@ -0,0 +1,104 @@
+package story
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxSaveAttempts is how many times Update tries to save before giving up on
+// a story that keeps being changed by other processes
+const maxSaveAttempts = 5
+
+// Update applies change to the story and saves it. When another process saved
+// the story since it was loaded, the latest version is loaded, change is
+// applied to it again and the save is retried. A new number must not be used by
+// another story of the project. On success s holds the saved story.
+func Update(s *Story, change func(s *Story) error) error {
+	current := s
+	for attempt := 1; ; attempt++ {
+		if err := change(current); err != nil {
+			return err
+		}
+
+		err := saveNumbered(current)
+		if err == nil {
+			*s = *current
+			return nil
+		}
+		if !errors.Is(err, ErrConflict) || attempt == maxSaveAttempts {
+			return fmt.Errorf("failed to save story: %w", err)
+		}
+
+		latest, err := LoadStory(current.Filename)
+		if err != nil {
+			return fmt.Errorf("failed to reload story %s: %w", current.ID, err)
+		}
+		current = latest
+	}
+}
+
+// SaveMerged saves mine, an edited copy of base. When another process saved the
+// story since base was loaded, the fields changed in mine are merged into the
+// latest version instead. It fails if both sides changed the same field to
+// different values. On success mine holds the saved story.
+func SaveMerged(base, mine *Story) error {
+	err := saveNumbered(mine)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrConflict) {
+		return fmt.Errorf("failed to save story: %w", err)
+	}
+
+	latest, err := LoadStory(mine.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to reload story %s: %w", mine.ID, err)
+	}
+	if err := Update(latest, func(theirs *Story) error {
+		return mergeInto(base, mine, theirs)
+	}); err != nil {
+		return err
+	}
+
+	*mine = *latest
+	return nil
+}
+
+// mergeFields lists the user editable fields that SaveMerged merges
+var mergeFields = []struct {
+	name  string
+	equal func(a, b *Story) bool
+	copy  func(from, to *Story)
+}{
+	{"title", func(a, b *Story) bool { return a.Title == b.Title }, func(from, to *Story) { to.Title = from.Title }},
+	{"description", func(a, b *Story) bool { return a.Description == b.Description }, func(from, to *Story) { to.Description = from.Description }},
+	{"number", func(a, b *Story) bool { return a.Number == b.Number }, func(from, to *Story) { to.Number = from.Number }},
+	{"jira_key", func(a, b *Story) bool { return a.JiraKey == b.JiraKey }, func(from, to *Story) { to.JiraKey = from.JiraKey }},
+	{"tags", func(a, b *Story) bool { return yamlEqual(a.Tags, b.Tags) }, func(from, to *Story) { to.Tags = from.Tags }},
+}
+
+// mergeInto applies the fields changed from base to mine onto theirs
+func mergeInto(base, mine, theirs *Story) error {
+	var conflicts []string
+	for _, f := range mergeFields {
+		if f.equal(mine, base) || f.equal(mine, theirs) {
+			continue
+		}
+		if !f.equal(theirs, base) {
+			conflicts = append(conflicts, f.name)
+			continue
+		}
+		f.copy(mine, theirs)
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: both sides changed %s. Reload the story and edit it again",
+			ErrConflict, strings.Join(conflicts, ", "))
+	}
+
+	if mine.UpdatedAt.After(theirs.UpdatedAt) {
+		theirs.UpdatedAt = mine.UpdatedAt
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package story
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRejectsStaleSaves(t *testing.T) {
+	store := NewYAMLStore(t.TempDir())
+
+	s := &Story{ID: "abc123", Title: "Original", CreatedAt: time.Now()}
+	require.NoError(t, store.Save(s))
+	assert.Equal(t, 1, s.Revision)
+
+	first, err := store.Get("abc123")
+	require.NoError(t, err)
+	second, err := store.Get("abc123")
+	require.NoError(t, err)
+
+	first.Title = "First"
+	require.NoError(t, store.Save(first))
+	assert.Equal(t, 2, first.Revision)
+
+	second.Title = "Second"
+	err = store.Save(second)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, 1, second.Revision)
+
+	stored, err := store.Get("abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "First", stored.Title)
+}
+
+func TestUpdateAndSaveMerged(t *testing.T) {
+	// Set up test repository
+	dir := setupTestRepo(t)
+
+	// Save current directory
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Change to test directory
+	err = os.Chdir(dir)
+	require.NoError(t, err)
+
+	// Defer changing back to original directory
+	defer func() {
+		err := os.Chdir(currentDir)
+		require.NoError(t, err)
+	}()
+
+	s := &Story{ID: "abc123", Title: "Original", Description: "Description", CreatedAt: time.Now()}
+	require.NoError(t, s.Save())
+
+	load := func() *Story {
+		loaded, err := LoadStory("abc123.yaml")
+		require.NoError(t, err)
+		return loaded
+	}
+
+	t.Run("update retries on the latest version", func(t *testing.T) {
+		stale := load()
+		other := load()
+		other.Description = "Changed elsewhere"
+		require.NoError(t, other.Save())
+
+		calls := 0
+		err := Update(stale, func(s *Story) error {
+			calls++
+			s.AddCommit("abc", "feat: change", "john.doe", time.Now())
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, "Changed elsewhere", stale.Description)
+		assert.Len(t, stale.Commits, 1)
+		assert.Len(t, load().Commits, 1)
+	})
+
+	t.Run("concurrent updates are not lost", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 4)
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- Update(load(), func(s *Story) error {
+					s.AddFile(fmt.Sprintf("file%d.go", i), "M")
+					return nil
+				})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Len(t, load().Files, 4)
+	})
+
+	t.Run("save merged keeps both changes", func(t *testing.T) {
+		base := load()
+		mine := load()
+		mine.Title = "Edited title"
+
+		other := load()
+		other.Description = "Edited elsewhere"
+		require.NoError(t, other.Save())
+
+		require.NoError(t, SaveMerged(base, mine))
+		assert.Equal(t, "Edited title", mine.Title)
+		assert.Equal(t, "Edited elsewhere", mine.Description)
+
+		stored := load()
+		assert.Equal(t, "Edited title", stored.Title)
+		assert.Equal(t, "Edited elsewhere", stored.Description)
+	})
+
+	t.Run("save merged reports conflicting fields", func(t *testing.T) {
+		base := load()
+		mine := load()
+		mine.Title = "Mine"
+
+		other := load()
+		other.Title = "Theirs"
+		require.NoError(t, other.Save())
+
+		err := SaveMerged(base, mine)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrConflict))
+		assert.Contains(t, err.Error(), "both sides changed title")
+		assert.Equal(t, "Theirs", load().Title)
+	})
+}
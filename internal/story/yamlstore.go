@@ -0,0 +1,187 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/config"
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+)
+
+// YAMLStore keeps one YAML file per story in a directory
+type YAMLStore struct {
+	dir string
+}
+
+// NewYAMLStore returns a store that keeps its stories in dir
+func NewYAMLStore(dir string) *YAMLStore {
+	return &YAMLStore{dir: dir}
+}
+
+// path returns the file that holds the story with the given ID
+func (y *YAMLStore) path(id string) string {
+	return filepath.Join(y.dir, id+config.DefaultStoryExt)
+}
+
+// Get loads the story with the given ID
+func (y *YAMLStore) Get(id string) (*Story, error) {
+	data, err := os.ReadFile(y.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to read story file: %w", err)
+	}
+
+	// Older schema versions are upgraded while reading, see MigrateYAML
+	story, _, err := decodeStoryYAML(data, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal story: %w", err)
+	}
+	story.Filename = id + config.DefaultStoryExt
+
+	return story, nil
+}
+
+// List loads every story file in the directory
+func (y *YAMLStore) List() ([]*Story, error) {
+	files, err := os.ReadDir(y.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stories directory: %w", err)
+	}
+
+	var stories []*Story
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), config.DefaultStoryExt) {
+			story, err := y.Get(idFromFilename(file.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load story %s: %w", file.Name(), err)
+			}
+			stories = append(stories, story)
+		}
+	}
+
+	// Sort stories by creation date in descending order (newest first)
+	sort.Slice(stories, func(i, j int) bool {
+		return stories[i].CreatedAt.After(stories[j].CreatedAt)
+	})
+
+	return stories, nil
+}
+
+// Query loads every story and applies the query to them
+func (y *YAMLStore) Query(q Query) ([]*Story, int, error) {
+	if err := q.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	stories, err := y.List()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, total := q.Apply(stories)
+	return result, total, nil
+}
+
+// Save writes the story to its file. The file is replaced atomically while
+// holding the lock on the directory.
+func (y *YAMLStore) Save(story *Story) error {
+	return y.SaveChecked(story, nil)
+}
+
+// SaveChecked writes the story to its file once check accepts it, holding the
+// lock on the directory from the check until the file is replaced
+func (y *YAMLStore) SaveChecked(story *Story, check func(stored []*Story) error) error {
+	if story.Filename == "" {
+		story.Filename = story.ID + config.DefaultStoryExt
+	}
+
+	unlock, err := lockStories(y.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if check != nil {
+		stories, err := y.List()
+		if err != nil {
+			return err
+		}
+		if err := check(stories); err != nil {
+			return err
+		}
+	}
+
+	stored, err := y.Get(idFromFilename(story.Filename))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := checkRevision(story, stored); err != nil {
+		return err
+	}
+
+	story.Revision++
+	data, err := encodeStoryYAML(story)
+	if err != nil {
+		story.Revision--
+		return fmt.Errorf("failed to marshal story: %w", err)
+	}
+
+	filePath := filepath.Join(y.dir, story.Filename)
+	if err := utils.WriteFileAtomic(filePath, data, utils.DefaultFilePerm); err != nil {
+		story.Revision--
+		return fmt.Errorf("failed to write story file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the story file
+func (y *YAMLStore) Delete(id string) error {
+	unlock, err := lockStories(y.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(y.path(id)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return fmt.Errorf("failed to delete story file: %w", err)
+	}
+	return nil
+}
+
+// Watch polls the directory for story files that are written or removed
+func (y *YAMLStore) Watch(ctx context.Context) (<-chan Event, error) {
+	return pollChanges(ctx, y.versions, y.Get)
+}
+
+// versions returns the modification time and size of every story file
+func (y *YAMLStore) versions() (map[string]string, error) {
+	files, err := os.ReadDir(y.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stories directory: %w", err)
+	}
+
+	versions := make(map[string]string)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), config.DefaultStoryExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		versions[idFromFilename(file.Name())] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+	}
+
+	return versions, nil
+}
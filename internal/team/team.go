@@ -0,0 +1,88 @@
+// Package team reads the team roster, which maps the names teammates are
+// known by in tracer to the names and emails git credits them with.
+package team
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// CoAuthorTrailer is the git trailer that credits a co-author of a commit
+const CoAuthorTrailer = "Co-authored-by"
+
+// Member is a teammate
+type Member struct {
+	Handle string `yaml:"handle"` // Name used with tracer pair start, such as john.doe
+	Name   string `yaml:"name"`
+	Email  string `yaml:"email"`
+}
+
+// Roster is the list of teammates
+type Roster struct {
+	Members []Member `yaml:"members"`
+	Path    string   `yaml:"-"` // File the roster was read from, empty when there is none
+}
+
+// String returns the member as git writes authors: Name <email>
+func (m Member) String() string {
+	return fmt.Sprintf("%s <%s>", m.Name, m.Email)
+}
+
+// Trailer returns the trailer crediting the member as a co-author
+func (m Member) Trailer() string {
+	return fmt.Sprintf("%s: %s", CoAuthorTrailer, m)
+}
+
+// LoadRoster reads the roster from the file in the repository configuration
+// directory, so it can be shared with the team, or else in the global one. A
+// missing roster is empty.
+func LoadRoster(file string) (*Roster, error) {
+	var dirs []string
+	if dir, err := utils.GetRepoConfigDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	if dir, err := utils.GetConfigDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read team roster: %w", err)
+		}
+
+		roster := &Roster{Path: path}
+		if err := yaml.Unmarshal(data, roster); err != nil {
+			return nil, fmt.Errorf("failed to parse team roster %s: %w", path, err)
+		}
+		return roster, nil
+	}
+
+	return &Roster{}, nil
+}
+
+// Find returns the member known by the handle, name or email, compared
+// case-insensitively. A name written as "Name <email>" is taken as is.
+func (r *Roster) Find(name string) (Member, bool) {
+	name = strings.TrimSpace(name)
+	for _, m := range r.Members {
+		if strings.EqualFold(m.Handle, name) || strings.EqualFold(m.Name, name) || strings.EqualFold(m.Email, name) {
+			return m, m.Name != "" && m.Email != ""
+		}
+	}
+
+	if addr, err := mail.ParseAddress(name); err == nil && addr.Name != "" {
+		return Member{Name: addr.Name, Email: addr.Address}, true
+	}
+	return Member{}, false
+}
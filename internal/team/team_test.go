@@ -0,0 +1,95 @@
+package team
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/helmedeiros/tracer-bullet/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRoster(t *testing.T) {
+	configDir := t.TempDir()
+	origConfigDir := utils.TestConfigDir
+	origGitClient := utils.GitClient
+	defer func() {
+		utils.TestConfigDir = origConfigDir
+		utils.GitClient = origGitClient
+	}()
+	utils.TestConfigDir = configDir
+
+	// Outside a repository only the global roster is read
+	mockGit := utils.NewMockGit().(*utils.MockGit)
+	mockGit.GetGitRootFunc = func() (string, error) {
+		return "", os.ErrNotExist
+	}
+	utils.GitClient = mockGit
+
+	roster, err := LoadRoster("team.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, roster.Members)
+	assert.Empty(t, roster.Path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "team.yaml"), []byte(`
+members:
+  - handle: jane.doe
+    name: Jane Doe
+    email: jane@example.com
+  - handle: sam
+    name: Sam Smith
+`), 0644))
+
+	roster, err = LoadRoster("team.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "team.yaml"), roster.Path)
+	require.Len(t, roster.Members, 2)
+
+	// A roster in the repository takes precedence
+	repoDir := t.TempDir()
+	mockGit.GetGitRootFunc = func() (string, error) {
+		return repoDir, nil
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, ".tracer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", "team.yaml"), []byte("members:\n  - handle: ann\n    name: Ann Lee\n    email: ann@example.com\n"), 0644))
+
+	roster, err = LoadRoster("team.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []Member{{Handle: "ann", Name: "Ann Lee", Email: "ann@example.com"}}, roster.Members)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ".tracer", "team.yaml"), []byte("members: [\n"), 0644))
+	_, err = LoadRoster("team.yaml")
+	assert.Error(t, err)
+}
+
+func TestRosterFind(t *testing.T) {
+	roster := &Roster{Members: []Member{
+		{Handle: "jane.doe", Name: "Jane Doe", Email: "jane@example.com"},
+		{Handle: "sam", Name: "Sam Smith"},
+	}}
+
+	tests := []struct {
+		name     string
+		lookup   string
+		expected Member
+		found    bool
+	}{
+		{name: "handle", lookup: "jane.doe", expected: roster.Members[0], found: true},
+		{name: "name", lookup: "jane doe", expected: roster.Members[0], found: true},
+		{name: "email", lookup: "JANE@example.com", expected: roster.Members[0], found: true},
+		{name: "member without email", lookup: "sam", expected: roster.Members[1], found: false},
+		{name: "name and email", lookup: "Bo Chen <bo@example.com>", expected: Member{Name: "Bo Chen", Email: "bo@example.com"}, found: true},
+		{name: "unknown", lookup: "bo", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member, found := roster.Find(tt.lookup)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, member)
+		})
+	}
+
+	assert.Equal(t, "Co-authored-by: Jane Doe <jane@example.com>", roster.Members[0].Trailer())
+}
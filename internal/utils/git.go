@@ -4,9 +4,31 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// LogEntry is a commit read from the git log
+type LogEntry struct {
+	Hash      string
+	Author    string
+	Email     string
+	Timestamp time.Time
+	Message   string
+	Files     []FileChange
+}
+
+// FileChange is a file changed by a commit
+type FileChange struct {
+	Path      string
+	OldPath   string // Previous path of renamed and copied files
+	Status    string // Git status letter: A, M, D, R, C or T
+	Additions int    // Lines added, 0 for binary files
+	Deletions int    // Lines deleted, 0 for binary files
+}
+
 // GitOperations defines the interface for git operations
 type GitOperations interface {
 	Init() error
@@ -24,8 +46,20 @@ type GitOperations interface {
 	GetUnstagedFiles() ([]string, error)
 	GetUntrackedFiles() ([]string, error)
 	GetDiff(file string) (string, error)
+	GetStagedFiles() ([]string, error)
+	GetStagedDiff(file string) (string, error)
 	StageAll() error
 	CommitWithFile(file string) error
+	GetCurrentBranch() (string, error)
+	RenameBranch(oldName, newName string) error
+	Log(args ...string) ([]LogEntry, error)
+	MergeBase(a, b string) (string, error)
+	Diff(args ...string) (string, error)
+	RevList(args ...string) ([]string, error)
+	GetHooksDir() (string, error)
+	GetGitPath(name string) (string, error)
+	Tags(args ...string) ([]string, error)
+	CreateTag(name, message string) error
 }
 
 // RealGit implements GitOperations using actual git commands
@@ -53,8 +87,20 @@ type BaseMockGit struct {
 	GetUnstagedFilesFunc  func() ([]string, error)
 	GetUntrackedFilesFunc func() ([]string, error)
 	GetDiffFunc           func(file string) (string, error)
+	GetStagedFilesFunc    func() ([]string, error)
+	GetStagedDiffFunc     func(file string) (string, error)
 	StageAllFunc          func() error
 	CommitWithFileFunc    func(file string) error
+	GetCurrentBranchFunc  func() (string, error)
+	RenameBranchFunc      func(oldName, newName string) error
+	LogFunc               func(args ...string) ([]LogEntry, error)
+	MergeBaseFunc         func(a, b string) (string, error)
+	DiffFunc              func(args ...string) (string, error)
+	RevListFunc           func(args ...string) ([]string, error)
+	GetHooksDirFunc       func() (string, error)
+	GetGitPathFunc        func(name string) (string, error)
+	TagsFunc              func(args ...string) ([]string, error)
+	CreateTagFunc         func(name, message string) error
 }
 
 // NewBaseMockGit creates a new BaseMockGit with default implementations
@@ -105,12 +151,48 @@ func NewBaseMockGit() *BaseMockGit {
 		GetDiffFunc: func(file string) (string, error) {
 			return "", nil
 		},
+		GetStagedFilesFunc: func() ([]string, error) {
+			return nil, nil
+		},
+		GetStagedDiffFunc: func(file string) (string, error) {
+			return "", nil
+		},
 		StageAllFunc: func() error {
 			return nil
 		},
 		CommitWithFileFunc: func(file string) error {
 			return nil
 		},
+		GetCurrentBranchFunc: func() (string, error) {
+			return "", nil
+		},
+		RenameBranchFunc: func(oldName, newName string) error {
+			return nil
+		},
+		LogFunc: func(args ...string) ([]LogEntry, error) {
+			return nil, nil
+		},
+		MergeBaseFunc: func(a, b string) (string, error) {
+			return "", nil
+		},
+		DiffFunc: func(args ...string) (string, error) {
+			return "", nil
+		},
+		RevListFunc: func(args ...string) ([]string, error) {
+			return nil, nil
+		},
+		GetHooksDirFunc: func() (string, error) {
+			return "", nil
+		},
+		GetGitPathFunc: func(name string) (string, error) {
+			return "", nil
+		},
+		TagsFunc: func(args ...string) ([]string, error) {
+			return nil, nil
+		},
+		CreateTagFunc: func(name, message string) error {
+			return nil
+		},
 	}
 }
 
@@ -278,6 +360,23 @@ func (g *RealGit) GetDiff(file string) (string, error) {
 	return RunCommand("git", "diff", file)
 }
 
+// GetStagedFiles gets a list of files with changes staged for the next commit
+func (g *RealGit) GetStagedFiles() ([]string, error) {
+	output, err := RunCommand("git", "diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSpace(output), "\n"), nil
+}
+
+// GetStagedDiff gets the staged diff for a specific file
+func (g *RealGit) GetStagedDiff(file string) (string, error) {
+	return RunCommand("git", "diff", "--cached", "--", file)
+}
+
 // StageAll stages all changes
 func (g *RealGit) StageAll() error {
 	_, err := RunCommand("git", "add", ".")
@@ -290,6 +389,168 @@ func (g *RealGit) CommitWithFile(file string) error {
 	return err
 }
 
+// GetCurrentBranch gets the name of the checked out branch
+func (g *RealGit) GetCurrentBranch() (string, error) {
+	output, err := RunCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// RenameBranch renames a git branch
+func (g *RealGit) RenameBranch(oldName, newName string) error {
+	_, err := RunCommand("git", "branch", "-m", oldName, newName)
+	return err
+}
+
+// logFormat separates the commits of the git log with a record separator and
+// their fields with a unit separator. The changed files follow the last field.
+const logFormat = "%x1e%H%x1f%an%x1f%ae%x1f%aI%x1f%B%x1f"
+
+// Log returns the commits selected by the git log arguments (revisions,
+// --grep patterns and the like), newest first, with the files each changed
+func (g *RealGit) Log(args ...string) ([]LogEntry, error) {
+	logArgs := append([]string{"log", "--format=" + logFormat, "--raw", "--numstat", "-M", "--no-color"}, args...)
+	output, err := RunCommand("git", logArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+	return parseLog(output)
+}
+
+// parseLog parses the output of git log written in logFormat with --raw and --numstat
+func parseLog(output string) ([]LogEntry, error) {
+	var entries []LogEntry
+	for _, record := range strings.Split(output, "\x1e") {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x1f", 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected git log output: %q", record)
+		}
+		timestamp, err := time.Parse(time.RFC3339, fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit date %q: %w", fields[3], err)
+		}
+
+		entry := LogEntry{
+			Hash:      fields[0],
+			Author:    fields[1],
+			Email:     fields[2],
+			Timestamp: timestamp,
+			Message:   strings.TrimSpace(fields[4]),
+		}
+
+		// --raw lists every file first, --numstat then repeats them in the
+		// same order with their line counts
+		numstat := 0
+		for _, line := range strings.Split(fields[5], "\n") {
+			if line == "" {
+				continue
+			}
+			parts := strings.Split(line, "\t")
+			if strings.HasPrefix(line, ":") {
+				meta := strings.Fields(parts[0])
+				if len(meta) < 5 || len(parts) < 2 {
+					return nil, fmt.Errorf("unexpected git log file line: %q", line)
+				}
+				change := FileChange{Status: meta[4][:1], Path: parts[len(parts)-1]}
+				if len(parts) == 3 {
+					change.OldPath = parts[1]
+				}
+				entry.Files = append(entry.Files, change)
+				continue
+			}
+			if len(parts) < 3 || numstat >= len(entry.Files) {
+				continue
+			}
+			// Binary files report "-" for both counts
+			entry.Files[numstat].Additions, _ = strconv.Atoi(parts[0])
+			entry.Files[numstat].Deletions, _ = strconv.Atoi(parts[1])
+			numstat++
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// MergeBase returns the best common ancestor of two revisions
+func (g *RealGit) MergeBase(a, b string) (string, error) {
+	output, err := RunCommand("git", "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Diff runs git diff with the given arguments and returns its output
+func (g *RealGit) Diff(args ...string) (string, error) {
+	output, err := RunCommand("git", append([]string{"diff", "--no-color"}, args...)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff: %w", err)
+	}
+	return output, nil
+}
+
+// RevList returns the hashes of the commits selected by the git rev-list arguments
+func (g *RealGit) RevList(args ...string) ([]string, error) {
+	output, err := RunCommand("git", append([]string{"rev-list"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return splitLines(output), nil
+}
+
+// Tags returns the tags listed by git tag with the arguments, such as
+// --merged <rev> or --sort=-creatordate
+func (g *RealGit) Tags(args ...string) ([]string, error) {
+	output, err := RunCommand("git", append([]string{"tag", "--list"}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return splitLines(output), nil
+}
+
+// CreateTag creates an annotated tag of HEAD with the message, kept verbatim
+func (g *RealGit) CreateTag(name, message string) error {
+	if _, err := RunCommand("git", "tag", "--annotate", "--cleanup=verbatim", "--message", message, name); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetHooksDir returns the absolute path of the directory git runs hooks from,
+// which honours core.hooksPath
+func (g *RealGit) GetHooksDir() (string, error) {
+	output, err := RunCommand("git", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	dir, err := filepath.Abs(strings.TrimSpace(output))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve hooks directory: %w", err)
+	}
+	return dir, nil
+}
+
+// GetGitPath returns the absolute path of a file in the git directory, such
+// as COMMIT_EDITMSG, where files that must not be committed are kept
+func (g *RealGit) GetGitPath(name string) (string, error) {
+	output, err := RunCommand("git", "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	path, err := filepath.Abs(strings.TrimSpace(output))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git path %s: %w", name, err)
+	}
+	return path, nil
+}
+
 // Init initializes a git repository (mock implementation)
 func (g *MockGit) Init() error {
 	return g.InitFunc()
@@ -365,6 +626,16 @@ func (g *MockGit) GetDiff(file string) (string, error) {
 	return g.GetDiffFunc(file)
 }
 
+// GetStagedFiles gets a list of files with staged changes (mock implementation)
+func (g *MockGit) GetStagedFiles() ([]string, error) {
+	return g.GetStagedFilesFunc()
+}
+
+// GetStagedDiff gets the staged diff for a specific file (mock implementation)
+func (g *MockGit) GetStagedDiff(file string) (string, error) {
+	return g.GetStagedDiffFunc(file)
+}
+
 // StageAll stages all changes (mock implementation)
 func (g *MockGit) StageAll() error {
 	return g.StageAllFunc()
@@ -375,6 +646,56 @@ func (g *MockGit) CommitWithFile(file string) error {
 	return g.CommitWithFileFunc(file)
 }
 
+// GetCurrentBranch gets the name of the checked out branch (mock implementation)
+func (g *MockGit) GetCurrentBranch() (string, error) {
+	return g.GetCurrentBranchFunc()
+}
+
+// RenameBranch renames a git branch (mock implementation)
+func (g *MockGit) RenameBranch(oldName, newName string) error {
+	return g.RenameBranchFunc(oldName, newName)
+}
+
+// Log returns the commits selected by the git log arguments (mock implementation)
+func (g *MockGit) Log(args ...string) ([]LogEntry, error) {
+	return g.LogFunc(args...)
+}
+
+// MergeBase returns the best common ancestor of two revisions (mock implementation)
+func (g *MockGit) MergeBase(a, b string) (string, error) {
+	return g.MergeBaseFunc(a, b)
+}
+
+// Diff runs git diff with the given arguments (mock implementation)
+func (g *MockGit) Diff(args ...string) (string, error) {
+	return g.DiffFunc(args...)
+}
+
+// RevList returns the hashes of the commits selected by the git rev-list arguments (mock implementation)
+func (g *MockGit) RevList(args ...string) ([]string, error) {
+	return g.RevListFunc(args...)
+}
+
+// GetHooksDir returns the directory git runs hooks from (mock implementation)
+func (g *MockGit) GetHooksDir() (string, error) {
+	return g.GetHooksDirFunc()
+}
+
+// GetGitPath returns the path of a file in the git directory (mock implementation)
+func (g *MockGit) GetGitPath(name string) (string, error) {
+	return g.GetGitPathFunc(name)
+}
+
+// Tags returns the tags listed by git tag with the arguments (mock implementation)
+func (g *MockGit) Tags(args ...string) ([]string, error) {
+	return g.TagsFunc(args...)
+}
+
+// CreateTag creates an annotated tag of HEAD (mock implementation)
+func (g *MockGit) CreateTag(name, message string) error {
+	return g.CreateTagFunc(name, message)
+}
+
 // splitLines splits a string into lines and trims whitespace
 func splitLines(s string) []string {
 	lines := strings.Split(s, "\n")
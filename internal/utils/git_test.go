@@ -3,7 +3,9 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -214,6 +216,49 @@ func TestSplitLines(t *testing.T) {
 	}
 }
 
+func TestParseLog(t *testing.T) {
+	output := "\x1e2347004b\x1fJane Doe\x1fjane@example.com\x1f2024-03-01T10:00:00+01:00\x1ffeat: second\n\nStory: #7\n\x1f\n\n" +
+		":100644 000000 587be6b 0000000 D\tg.txt\n" +
+		":100644 100644 422c2b7 422c2b7 R100\tf.txt\th.txt\n" +
+		":000000 100644 0000000 3e75765 A\tn.txt\n" +
+		":100644 100644 1111111 2222222 M\timage.png\n" +
+		"0\t1\tg.txt\n" +
+		"0\t0\tf.txt => h.txt\n" +
+		"1\t0\tn.txt\n" +
+		"-\t-\timage.png\n" +
+		"\x1ea1c1428e\x1fJohn Doe\x1fjohn@example.com\x1f2024-02-29T09:00:00Z\x1finit\n\x1f\n\n" +
+		":000000 100644 0000000 422c2b7 A\tf.txt\n" +
+		"2\t0\tf.txt\n"
+
+	entries, err := parseLog(output)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "2347004b", entries[0].Hash)
+	assert.Equal(t, "Jane Doe", entries[0].Author)
+	assert.Equal(t, "jane@example.com", entries[0].Email)
+	assert.Equal(t, "feat: second\n\nStory: #7", entries[0].Message)
+	assert.True(t, entries[0].Timestamp.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []FileChange{
+		{Path: "g.txt", Status: "D", Deletions: 1},
+		{Path: "h.txt", OldPath: "f.txt", Status: "R"},
+		{Path: "n.txt", Status: "A", Additions: 1},
+		{Path: "image.png", Status: "M"},
+	}, entries[0].Files)
+
+	assert.Equal(t, "a1c1428e", entries[1].Hash)
+	assert.Equal(t, []FileChange{{Path: "f.txt", Status: "A", Additions: 2}}, entries[1].Files)
+
+	// Empty output has no commits
+	entries, err = parseLog("")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Truncated records are rejected
+	_, err = parseLog("\x1eabc\x1fJane")
+	assert.Error(t, err)
+}
+
 func TestRealGit_GetConfig_Error(t *testing.T) {
 	// Save current GitClient
 	originalGitClient := GitClient
@@ -353,3 +398,37 @@ func TestRealGit_Commit_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "nothing to commit")
 }
+
+func TestRealGit_GetStagedChanges(t *testing.T) {
+	dir := t.TempDir()
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(currentDir)) }()
+
+	git := NewRealGit()
+	_, err = RunCommand("git", "init", "--quiet")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("staged.go", []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile("unstaged.go", []byte("package main\n"), 0644))
+	_, err = RunCommand("git", "add", "staged.go")
+	require.NoError(t, err)
+
+	files, err := git.GetStagedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staged.go"}, files)
+
+	diff, err := git.GetStagedDiff("staged.go")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+package main")
+
+	diff, err = git.GetStagedDiff("unstaged.go")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	root, err := os.Getwd()
+	require.NoError(t, err)
+	path, err := git.GetGitPath("COMMIT_EDITMSG")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".git", "COMMIT_EDITMSG"), path)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -61,6 +62,33 @@ func GetConfigDir() (string, error) {
 	return configDir, nil
 }
 
+// WriteFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers see either the old or the new content, never a mix
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
 // FileExists checks if a file exists
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -289,6 +317,38 @@ func GenerateBranchName(title string, id string, number int, project string) str
 	return branch.String()
 }
 
+// ParseBranchName parses a branch name in the format produced by BranchName.String.
+// The project is needed to tell it apart from the rest of the name, since both may
+// contain dashes; pass an empty project for branches created without one.
+func ParseBranchName(branch, project string) (*BranchName, error) {
+	typ, rest, found := strings.Cut(branch, "/")
+	if !found || typ == "" || rest == "" {
+		return nil, fmt.Errorf("invalid branch name: %s", branch)
+	}
+
+	parsed := &BranchName{Type: BranchType(typ)}
+
+	if project != "" {
+		if trimmed, ok := strings.CutPrefix(rest, project+"-"); ok {
+			parsed.Project = project
+			rest = trimmed
+		}
+	}
+
+	if number, name, ok := strings.Cut(rest, "-"); ok {
+		if n, err := strconv.Atoi(number); err == nil && n > 0 {
+			parsed.Number = n
+			rest = name
+		}
+	} else if n, err := strconv.Atoi(rest); err == nil && n > 0 {
+		parsed.Number = n
+		rest = ""
+	}
+
+	parsed.Name = rest
+	return parsed, nil
+}
+
 // GetProjectName returns the current project name from git config
 func GetProjectName() (string, error) {
 	return GitClient.GetConfig("current.project")
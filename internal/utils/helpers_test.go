@@ -796,6 +796,69 @@ func TestGenerateBranchName(t *testing.T) {
 	}
 }
 
+func TestParseBranchName(t *testing.T) {
+	tests := []struct {
+		name        string
+		branch      string
+		project     string
+		expectError bool
+		expected    *BranchName
+	}{
+		{
+			name:     "project and number",
+			branch:   "features/my-project-123-test-story",
+			project:  "my-project",
+			expected: &BranchName{Type: FeatureBranch, Project: "my-project", Number: 123, Name: "test-story"},
+		},
+		{
+			name:     "number without project",
+			branch:   "features/129-test-story",
+			expected: &BranchName{Type: FeatureBranch, Number: 129, Name: "test-story"},
+		},
+		{
+			name:     "project without number",
+			branch:   "features/my-project-test-story",
+			project:  "my-project",
+			expected: &BranchName{Type: FeatureBranch, Project: "my-project", Name: "test-story"},
+		},
+		{
+			name:     "different project is kept in the name",
+			branch:   "features/other-123-test-story",
+			project:  "my-project",
+			expected: &BranchName{Type: FeatureBranch, Name: "other-123-test-story"},
+		},
+		{
+			name:     "bugfix branch",
+			branch:   "bugfix/my-project-7-crash",
+			project:  "my-project",
+			expected: &BranchName{Type: BugfixBranch, Project: "my-project", Number: 7, Name: "crash"},
+		},
+		{
+			name:     "round trip with GenerateBranchName",
+			branch:   GenerateBranchName("Fix Bug #123", "id", 124, "my-project"),
+			project:  "my-project",
+			expected: &BranchName{Type: FeatureBranch, Project: "my-project", Number: 124, Name: "fix-bug-123"},
+		},
+		{
+			name:        "no type",
+			branch:      "main",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseBranchName(tt.branch, tt.project)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestCreateBranch(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1,54 +1,71 @@
 package utils
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"net/url"
 	"strings"
+
+	"github.com/helmedeiros/tracer-bullet/internal/diffplan"
+	"github.com/helmedeiros/tracer-bullet/internal/llm"
 )
 
-// llamaAPIURL is the URL of the llama API server
-var llamaAPIURL = "http://localhost:11434/api/generate"
+// PromptType is a commit type the model may choose, with what it is used for
+type PromptType struct {
+	Name        string
+	Description string
+}
+
+// CommitPolicy describes the commit messages the model is asked to write
+type CommitPolicy struct {
+	Types           []PromptType // Types to choose from, the configured ones in practice
+	Scopes          []string     // Scopes to choose from, any component name when empty
+	MaxHeaderLength int          // Longest header, no limit when 0
+	Footers         []string     // Footers every message must have
+}
+
+// CommitMessageGenerator asks a language model for commit messages
+type CommitMessageGenerator struct {
+	Provider llm.Provider     // The model that writes the messages
+	Policy   CommitPolicy     // The policy described in the prompt
+	Context  diffplan.Options // How much of the changes the prompt shows
+}
 
-// SetLlamaAPIURL sets the URL of the llama API server
-func SetLlamaAPIURL(urlStr string) error {
-	// Validate the URL
-	_, err := url.ParseRequestURI(urlStr)
+// Generate asks the language model for a commit message for the provided
+// diffs, following the author's hint, such as "mention the retry logic", when
+// one is given
+func (g *CommitMessageGenerator) Generate(ctx context.Context, diffs []string, hint string) (string, error) {
+	prompt := g.buildPrompt(diffs) + formatHint(hint)
+	message, err := g.Provider.Generate(ctx, prompt)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return "", err
 	}
-	llamaAPIURL = urlStr
-	return nil
+	return cleanAndFormatMessage(strings.TrimSpace(message))
 }
 
-// GenerateCommitMessage uses llama to generate a commit message from the provided diffs
-func GenerateCommitMessage(diffs []string) (string, error) {
-	prompt := buildPrompt(diffs)
-	return callLlamaAPI(prompt)
+// PlanContext plans which of the changes the prompt shows. Diffs are given as
+// "File: <path>" or, for untracked files, "New file: <path>" followed by the
+// diff or the content of the file.
+func (g *CommitMessageGenerator) PlanContext(diffs []string) *diffplan.Plan {
+	return diffplan.New(parseChanges(diffs), g.Context)
 }
 
-func buildPrompt(diffs []string) string {
-	prompt := getBasePrompt()
+func (g *CommitMessageGenerator) buildPrompt(diffs []string) string {
+	prompt := getBasePrompt(g.Policy)
 	prompt += "\nCHANGES TO ANALYZE:\n\n"
 
-	fileChanges := groupChangesByFile(diffs)
-	prompt += formatFileChanges(fileChanges)
-	prompt += formatChangeSummary(fileChanges)
+	plan := g.PlanContext(diffs)
+	prompt += formatFileChanges(plan)
+	prompt += formatChangeSummary(plan)
 
 	return prompt
 }
 
-func getBasePrompt() string {
+func getBasePrompt(policy CommitPolicy) string {
 	return `You are an expert at writing clear and descriptive commit messages.
 The commit message MUST follow this exact format:
 <type>(<scope>): <description>
 
-- type: feat, fix, docs, style, refactor, test, or chore
-- scope: optional component name in parentheses
-- description: start with verb, use imperative mood, no period
-
+` + policyRules(policy) + `
 A blank line must separate the header from the body.
 The body should list the key changes with bullet points.
 
@@ -82,142 +99,100 @@ Standardize error handling across core services
 The changes will be provided in git diff format. Generate a commit message for these changes:`
 }
 
-func groupChangesByFile(diffs []string) map[string][]string {
-	fileChanges := make(map[string][]string)
-	for _, diff := range diffs {
-		lines := strings.Split(diff, "\n")
-		if len(lines) == 0 {
-			continue
-		}
-
-		fileLine := lines[0]
-		if !strings.HasPrefix(fileLine, "File: ") {
-			continue
-		}
-		fileName := strings.TrimPrefix(fileLine, "File: ")
-		fileChanges[fileName] = append(fileChanges[fileName], strings.Join(lines[1:], "\n"))
-	}
-	return fileChanges
-}
-
-func formatFileChanges(fileChanges map[string][]string) string {
-	var prompt string
-	for fileName, changes := range fileChanges {
-		prompt += fmt.Sprintf("File: %s\n", fileName)
-		for _, change := range changes {
-			lines := strings.Split(change, "\n")
-			if len(lines) > 0 && strings.HasPrefix(lines[0], "@@") {
-				prompt += lines[0] + "\n"
-				for _, line := range lines[1:] {
-					prompt += line + "\n"
-				}
+// policyRules describes the types, scopes and limits of the policy in the prompt
+func policyRules(policy CommitPolicy) string {
+	var rules strings.Builder
+	if len(policy.Types) == 0 {
+		rules.WriteString("- type: a conventional commit type\n")
+	} else {
+		rules.WriteString("- type: one of\n")
+		for _, t := range policy.Types {
+			if t.Description == "" {
+				fmt.Fprintf(&rules, "    %s\n", t.Name)
+			} else {
+				fmt.Fprintf(&rules, "    %s: %s\n", t.Name, t.Description)
 			}
 		}
-		prompt += "\n"
 	}
-	return prompt
-}
 
-func formatChangeSummary(fileChanges map[string][]string) string {
-	summary := "\nSUMMARY OF CHANGES:\n"
-	for fileName, changes := range fileChanges {
-		summary += fmt.Sprintf("- In %s:\n", fileName)
-		for _, change := range changes {
-			summary += formatChangeStats(change)
-		}
+	if len(policy.Scopes) == 0 {
+		rules.WriteString("- scope: optional component name in parentheses\n")
+	} else {
+		fmt.Fprintf(&rules, "- scope: optional, one of %s\n", strings.Join(policy.Scopes, ", "))
 	}
-	return summary
-}
+	rules.WriteString("- description: start with verb, use imperative mood, no period\n")
 
-func formatChangeStats(change string) string {
-	lines := strings.Split(change, "\n")
-	if len(lines) == 0 || !strings.HasPrefix(lines[0], "@@") {
-		return ""
+	if policy.MaxHeaderLength > 0 {
+		fmt.Fprintf(&rules, "- the header line must be at most %d characters long\n", policy.MaxHeaderLength)
 	}
-
-	additions := 0
-	removals := 0
-	for _, line := range lines[1:] {
-		switch {
-		case strings.HasPrefix(line, "+"):
-			additions++
-		case strings.HasPrefix(line, "-"):
-			removals++
-		}
+	if len(policy.Footers) > 0 {
+		fmt.Fprintf(&rules, "- end the message with these footers, after a blank line: %s\n", strings.Join(policy.Footers, ", "))
 	}
-
-	return fmt.Sprintf("  - %d lines added, %d lines removed\n", additions, removals)
+	return rules.String()
 }
 
-func callLlamaAPI(prompt string) (string, error) {
-	response, err := makeAPIRequest(prompt)
-	if err != nil {
-		return "", err
-	}
-
-	message, err := extractMessageFromResponse(response)
-	if err != nil {
-		return "", err
+// parseChanges reads the changed files from the diffs. The planner merges
+// several diffs of the same file.
+func parseChanges(diffs []string) []diffplan.Change {
+	var changes []diffplan.Change
+	for _, diff := range diffs {
+		header, content, _ := strings.Cut(diff, "\n")
+		change := diffplan.Change{Diff: content}
+		if name, ok := strings.CutPrefix(header, "New file: "); ok {
+			change.Path, change.New = name, true
+		} else if name, ok := strings.CutPrefix(header, "File: "); ok {
+			change.Path = name
+		} else {
+			continue
+		}
+		changes = append(changes, change)
 	}
-
-	return cleanAndFormatMessage(message)
+	return changes
 }
 
-func makeAPIRequest(prompt string) (map[string]interface{}, error) {
-	reqBody := map[string]interface{}{
-		"model":       "llama3",
-		"prompt":      prompt,
-		"max_tokens":  500,
-		"temperature": 0.7,
-		"stream":      false,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	if err := validateAPIURL(); err != nil {
-		return nil, err
-	}
-
-	//nolint:gosec // URL is validated before making the request
-	resp, err := http.Post(llamaAPIURL, "application/json", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to call llama API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("llama API returned status code %d", resp.StatusCode)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode llama API response: %w", err)
+// formatFileChanges shows the planned hunks of each file, most significant first
+func formatFileChanges(plan *diffplan.Plan) string {
+	var prompt strings.Builder
+	for _, f := range plan.Files {
+		if f.Text == "" {
+			continue
+		}
+		label := "File"
+		if f.New {
+			label = "New file"
+		}
+		fmt.Fprintf(&prompt, "%s: %s\n%s\n", label, f.Path, f.Text)
 	}
-
-	return result, nil
+	return prompt.String()
 }
 
-func validateAPIURL() error {
-	_, err := url.ParseRequestURI(llamaAPIURL)
-	if err != nil {
-		return fmt.Errorf("invalid llama API URL: %w", err)
+// formatChangeSummary lists the stats of every file, including those the
+// prompt does not show
+func formatChangeSummary(plan *diffplan.Plan) string {
+	var summary strings.Builder
+	summary.WriteString("\nSUMMARY OF CHANGES:\n")
+	for _, f := range plan.Files {
+		fmt.Fprintf(&summary, "- In %s: %d lines added, %d lines removed", f.Path, f.Stats.Added, f.Stats.Removed)
+		switch f.Status {
+		case diffplan.StatusTruncated:
+			fmt.Fprintf(&summary, " (%d lines not shown)", f.Omitted)
+		case diffplan.StatusSummary:
+			summary.WriteString(" (not shown)")
+		case diffplan.StatusSkipped:
+			fmt.Fprintf(&summary, " (not shown: %s)", f.Reason)
+		}
+		summary.WriteString("\n")
 	}
-	return nil
+	return summary.String()
 }
 
-func extractMessageFromResponse(result map[string]interface{}) (string, error) {
-	responseFields := []string{"response", "text", "content"}
-
-	for _, field := range responseFields {
-		if response, ok := result[field].(string); ok {
-			return strings.TrimSpace(response), nil
-		}
+// formatHint adds the author's hint to the prompt
+func formatHint(hint string) string {
+	hint = strings.TrimSpace(hint)
+	if hint == "" {
+		return ""
 	}
-
-	return "", fmt.Errorf("missing response field in llama API response")
+	return "\nINSTRUCTIONS FROM THE AUTHOR (follow them when writing the message):\n" + hint + "\n"
 }
 
 func cleanAndFormatMessage(message string) (string, error) {
@@ -1,13 +1,17 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/helmedeiros/tracer-bullet/internal/diffplan"
+	"github.com/helmedeiros/tracer-bullet/internal/llm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateCommitMessage(t *testing.T) {
@@ -23,8 +27,7 @@ func TestGenerateCommitMessage(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Verify request body
-		assert.Equal(t, float64(500), reqBody["max_tokens"])
-		assert.Equal(t, float64(0.7), reqBody["temperature"])
+		assert.Equal(t, map[string]interface{}{"num_predict": float64(500), "temperature": float64(0.7)}, reqBody["options"])
 		assert.Contains(t, reqBody["prompt"], "You are an expert at writing clear and descriptive commit messages")
 
 		// Send response
@@ -37,14 +40,8 @@ func TestGenerateCommitMessage(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override llama API URL for testing
-	originalURL := llamaAPIURL
-	err := SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
-	defer func() {
-		err := SetLlamaAPIURL(originalURL)
-		assert.NoError(t, err)
-	}()
+	// Point the generator at the test server
+	generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
 
 	// Test cases
 	tests := []struct {
@@ -71,7 +68,7 @@ func TestGenerateCommitMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			message, err := GenerateCommitMessage(tt.diffs)
+			message, err := generator.Generate(context.Background(), tt.diffs, "")
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, message)
 		})
@@ -79,21 +76,6 @@ func TestGenerateCommitMessage(t *testing.T) {
 }
 
 func TestGenerateCommitMessageError(t *testing.T) {
-	// Create a test server that returns an error
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
-	}))
-	defer server.Close()
-
-	// Override llama API URL for testing
-	originalURL := llamaAPIURL
-	err := SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
-	defer func() {
-		err := SetLlamaAPIURL(originalURL)
-		assert.NoError(t, err)
-	}()
-
 	// Test cases
 	tests := []struct {
 		name          string
@@ -105,7 +87,7 @@ func TestGenerateCommitMessageError(t *testing.T) {
 			serverHandler: func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusInternalServerError)
 			},
-			expectedError: "llama API returned status code 500",
+			expectedError: "ollama API returned status code 500",
 		},
 		{
 			name: "invalid JSON response",
@@ -114,7 +96,7 @@ func TestGenerateCommitMessageError(t *testing.T) {
 				_, err := w.Write([]byte("invalid json"))
 				assert.NoError(t, err)
 			},
-			expectedError: "failed to decode llama API response",
+			expectedError: "failed to decode ollama API response",
 		},
 		{
 			name: "missing response field",
@@ -126,7 +108,7 @@ func TestGenerateCommitMessageError(t *testing.T) {
 				err := json.NewEncoder(w).Encode(response)
 				assert.NoError(t, err)
 			},
-			expectedError: "missing response field in llama API response",
+			expectedError: "missing response field in ollama API response",
 		},
 	}
 
@@ -136,20 +118,20 @@ func TestGenerateCommitMessageError(t *testing.T) {
 			server := httptest.NewServer(tt.serverHandler)
 			defer server.Close()
 
-			// Override llama API URL for testing
-			originalURL := llamaAPIURL
-			err := SetLlamaAPIURL(server.URL)
-			assert.NoError(t, err)
-			defer func() {
-				err := SetLlamaAPIURL(originalURL)
-				assert.NoError(t, err)
-			}()
+			// Point the generator at the test server
+			generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
 
-			_, err = GenerateCommitMessage([]string{"diff"})
+			_, err := generator.Generate(context.Background(), []string{"diff"}, "")
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), tt.expectedError)
 		})
 	}
+	// The request gives up with the context of the command
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	generator := &CommitMessageGenerator{Provider: llm.NewStub("feat: add login")}
+	_, err := generator.Generate(ctx, []string{"diff"}, "")
+	assert.ErrorIs(t, err, context.Canceled)
 }
 
 func TestGenerateCommitMessageDefaultType(t *testing.T) {
@@ -164,16 +146,10 @@ func TestGenerateCommitMessageDefaultType(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override llama API URL for testing
-	originalURL := llamaAPIURL
-	err := SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
-	defer func() {
-		err := SetLlamaAPIURL(originalURL)
-		assert.NoError(t, err)
-	}()
+	// Point the generator at the test server
+	generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
 
-	message, err := GenerateCommitMessage([]string{"diff"})
+	message, err := generator.Generate(context.Background(), []string{"diff"}, "")
 	assert.NoError(t, err)
 	assert.Equal(t, "feat: This is a commit message without a type", message)
 }
@@ -313,8 +289,7 @@ func TestPromptFormat(t *testing.T) {
 
 				// Verify request body
 				assert.Equal(t, "llama3", reqBody["model"])
-				assert.Equal(t, float64(500), reqBody["max_tokens"])
-				assert.Equal(t, float64(0.7), reqBody["temperature"])
+				assert.Equal(t, map[string]interface{}{"num_predict": float64(500), "temperature": float64(0.7)}, reqBody["options"])
 
 				// Verify prompt contains our examples and format
 				prompt := reqBody["prompt"].(string)
@@ -333,17 +308,11 @@ func TestPromptFormat(t *testing.T) {
 			}))
 			defer server.Close()
 
-			// Override llama API URL for testing
-			originalURL := llamaAPIURL
-			err := SetLlamaAPIURL(server.URL)
-			assert.NoError(t, err)
-			defer func() {
-				err := SetLlamaAPIURL(originalURL)
-				assert.NoError(t, err)
-			}()
+			// Point the generator at the test server
+			generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
 
 			// Generate commit message
-			message, err := GenerateCommitMessage(tt.diffs)
+			message, err := generator.Generate(context.Background(), tt.diffs, "")
 			assert.NoError(t, err)
 
 			// Verify message format
@@ -390,16 +359,92 @@ func TestPromptExamples(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override llama API URL for testing
-	originalURL := llamaAPIURL
-	err := SetLlamaAPIURL(server.URL)
-	assert.NoError(t, err)
-	defer func() {
-		err := SetLlamaAPIURL(originalURL)
-		assert.NoError(t, err)
-	}()
+	// Point the generator at the test server
+	generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
 
 	// Generate commit message
-	_, err = GenerateCommitMessage([]string{"test diff"})
+	_, err := generator.Generate(context.Background(), []string{"test diff"}, "")
 	assert.NoError(t, err)
 }
+
+func TestPromptCommitPolicy(t *testing.T) {
+	// Without a policy any conventional type and scope is allowed
+	prompt := getBasePrompt(CommitPolicy{})
+	assert.Contains(t, prompt, "- type: a conventional commit type\n")
+	assert.Contains(t, prompt, "- scope: optional component name in parentheses")
+
+	prompt = getBasePrompt(CommitPolicy{
+		Types:           []PromptType{{Name: "perf", Description: "A performance improvement"}, {Name: "ci"}},
+		Scopes:          []string{"api", "core"},
+		MaxHeaderLength: 72,
+		Footers:         []string{"Signed-off-by"},
+	})
+	assert.Contains(t, prompt, "    perf: A performance improvement\n    ci\n")
+	assert.NotContains(t, prompt, "a conventional commit type")
+	assert.Contains(t, prompt, "- scope: optional, one of api, core")
+	assert.Contains(t, prompt, "at most 72 characters")
+	assert.Contains(t, prompt, "these footers, after a blank line: Signed-off-by")
+}
+
+func TestPromptContext(t *testing.T) {
+	generator := &CommitMessageGenerator{Context: diffplan.Options{Budget: 60, Exclude: []string{"*.snap"}}}
+
+	var big strings.Builder
+	big.WriteString("@@ -1,0 +1,50 @@\n")
+	for i := 0; i < 50; i++ {
+		big.WriteString("+\tcall(i)\n")
+	}
+	prompt := generator.buildPrompt([]string{
+		"File: internal/api/handler.go\ndiff --git a/internal/api/handler.go b/internal/api/handler.go\n--- a/internal/api/handler.go\n+++ b/internal/api/handler.go\n" + big.String(),
+		"File: go.sum\n@@ -1 +1 @@\n-a v1\n+a v2",
+		"New file: ui/__snapshots__/app.snap\nsnapshot",
+		"New file: logo.png\n\x89PNG\x00",
+	})
+
+	assert.Contains(t, prompt, "File: internal/api/handler.go\n@@ -1,0 +1,50 @@\n+\tcall(i)\n")
+	assert.NotContains(t, prompt, "diff --git")
+	assert.NotContains(t, prompt, "File: go.sum")
+	assert.Contains(t, prompt, "- In internal/api/handler.go: 50 lines added, 0 lines removed (")
+	assert.Contains(t, prompt, "- In go.sum: 1 lines added, 1 lines removed (not shown: excluded)\n")
+	assert.Contains(t, prompt, "- In ui/__snapshots__/app.snap: 1 lines added, 0 lines removed (not shown: excluded)\n")
+	assert.Contains(t, prompt, "- In logo.png: 0 lines added, 0 lines removed (not shown: binary)\n")
+
+	plan := generator.PlanContext([]string{"New file: main.go\npackage main\n"})
+	assert.Equal(t, "@@ -0,0 +1,1 @@\n+package main\n", plan.Files[0].Text)
+}
+
+func TestPlanContextMergesDiffsOfAFile(t *testing.T) {
+	generator := &CommitMessageGenerator{}
+	header := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n"
+	plan := generator.PlanContext([]string{
+		"File: main.go\n" + header + "@@ -1 +1 @@\n-var a = 1\n+var a = 2",
+		"File: main.go\n" + header + "@@ -9 +9 @@\n+var b = 1",
+	})
+
+	require.Len(t, plan.Files, 1)
+	assert.Equal(t, diffplan.Stats{Added: 2, Removed: 1, Hunks: 2}, plan.Files[0].Stats)
+	assert.NotContains(t, plan.Files[0].Text, "diff --git")
+}
+
+func TestPromptHint(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		prompts = append(prompts, reqBody["prompt"].(string))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"response": "fix: retry failed requests"})
+	}))
+	defer server.Close()
+
+	generator := &CommitMessageGenerator{Provider: llm.NewOllama(llm.Config{Endpoint: server.URL})}
+
+	message, err := generator.Generate(context.Background(), []string{"File: client.go\n@@ -1 +1 @@\n+retry()"}, "  mention the retry logic\n")
+	require.NoError(t, err)
+	assert.Equal(t, "fix: retry failed requests", message)
+	_, err = generator.Generate(context.Background(), []string{"File: client.go\n@@ -1 +1 @@\n+retry()"}, "")
+	require.NoError(t, err)
+
+	require.Len(t, prompts, 2)
+	assert.True(t, strings.HasSuffix(prompts[0], "\nINSTRUCTIONS FROM THE AUTHOR (follow them when writing the message):\nmention the retry logic\n"))
+	assert.NotContains(t, prompts[1], "INSTRUCTIONS FROM THE AUTHOR")
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// LockTimeout is how long LockFile waits for another process to release a lock
+var LockTimeout = 10 * time.Second
+
+// lockRetryInterval is how often LockFile retries a held lock
+const lockRetryInterval = 20 * time.Millisecond
+
+// LockFile takes an exclusive advisory lock on the file at path, creating it if
+// needed. It waits up to LockTimeout for other processes to release the lock.
+// The returned function releases the lock.
+func LockFile(path string) (func(), error) {
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		unlock, err := tryLockFile(path)
+		if err == nil {
+			return unlock, nil
+		}
+		if err != errLocked {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for lock %s held by another process", LockTimeout, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// openLockFile opens the lock file, creating it if needed
+func openLockFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, DefaultFilePerm)
+}
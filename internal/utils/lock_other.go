@@ -0,0 +1,30 @@
+//go:build !unix
+
+package utils
+
+import (
+	"errors"
+	"os"
+)
+
+// errLocked reports that another process holds the lock
+var errLocked = errors.New("lock is held by another process")
+
+// tryLockFile takes the lock by creating a marker file next to path. The marker
+// is removed on unlock; a marker left behind by a crashed process has to be
+// removed by hand.
+func tryLockFile(path string) (func(), error) {
+	marker := path + ".held"
+	f, err := os.OpenFile(marker, os.O_RDWR|os.O_CREATE|os.O_EXCL, DefaultFilePerm)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	f.Close()
+
+	return func() {
+		os.Remove(marker)
+	}, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	originalTimeout := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	defer func() {
+		LockTimeout = originalTimeout
+	}()
+
+	path := filepath.Join(t.TempDir(), ".lock")
+
+	unlock, err := LockFile(path)
+	require.NoError(t, err)
+
+	// Another process cannot take the lock while it is held
+	_, err = tryLockFile(path)
+	assert.Equal(t, errLocked, err)
+	_, err = LockFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	unlock()
+	unlock, err = LockFile(path)
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "story.yaml")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first"), DefaultFilePerm))
+	require.NoError(t, WriteFileAtomic(path, []byte("second"), DefaultFilePerm))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(DefaultFilePerm), info.Mode().Perm())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
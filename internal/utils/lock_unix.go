@@ -0,0 +1,32 @@
+//go:build unix
+
+package utils
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errLocked reports that another process holds the lock
+var errLocked = errors.New("lock is held by another process")
+
+// tryLockFile takes a flock on the file without waiting
+func tryLockFile(path string) (func(), error) {
+	f, err := openLockFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}